@@ -14,14 +14,28 @@ import (
 	"github.com/arjnep/gyanpass/config"
 	"github.com/arjnep/gyanpass/internal/db"
 	httpBook "github.com/arjnep/gyanpass/internal/delivery/http/book"
+	httpCycle "github.com/arjnep/gyanpass/internal/delivery/http/cycle"
 	httpExchange "github.com/arjnep/gyanpass/internal/delivery/http/exchange"
+	httpFavourite "github.com/arjnep/gyanpass/internal/delivery/http/favourite"
+	httpGenre "github.com/arjnep/gyanpass/internal/delivery/http/genre"
+	httpImage "github.com/arjnep/gyanpass/internal/delivery/http/image"
+	httpLoan "github.com/arjnep/gyanpass/internal/delivery/http/loan"
 	httpNotification "github.com/arjnep/gyanpass/internal/delivery/http/notification"
+	httpSearch "github.com/arjnep/gyanpass/internal/delivery/http/search"
+	httpTag "github.com/arjnep/gyanpass/internal/delivery/http/tag"
 	httpUser "github.com/arjnep/gyanpass/internal/delivery/http/user"
+	httpWanted "github.com/arjnep/gyanpass/internal/delivery/http/wanted"
+	httpWishlist "github.com/arjnep/gyanpass/internal/delivery/http/wishlist"
+	httpWork "github.com/arjnep/gyanpass/internal/delivery/http/work"
 	"github.com/arjnep/gyanpass/internal/delivery/middleware"
 	"github.com/arjnep/gyanpass/internal/repository"
 	"github.com/arjnep/gyanpass/internal/usecase"
 	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/mailer"
+	"github.com/arjnep/gyanpass/pkg/metadata"
 	"github.com/arjnep/gyanpass/pkg/notification"
+	"github.com/arjnep/gyanpass/pkg/scheduler"
+	"github.com/arjnep/gyanpass/pkg/storage"
 	"github.com/gin-gonic/gin"
 )
 
@@ -71,13 +85,87 @@ func main() {
 	bookRepo := repository.NewBookRepository(database)
 	exchangeRepo := repository.NewExchangeRepository(database)
 	notificationRepo := repository.NewNotificationRepository(database)
+	imageRepo := repository.NewImageRepository(database)
+	workRepo := repository.NewWorkRepository(database)
+	importJobRepo := repository.NewImportJobRepository(database)
+	bookVersionRepo := repository.NewBookVersionRepository(database)
+	wishlistRepo := repository.NewWishlistRepository(database)
+	savedSearchRepo := repository.NewSavedSearchRepository(database)
+	loanRepo := repository.NewLoanRepository(database)
+	wantedRepo := repository.NewWantedRepository(database)
+	genreRepo := repository.NewGenreRepository(database)
+	tagRepo := repository.NewTagRepository(database)
+	exchangeEventRepo := repository.NewExchangeEventRepository(database)
+	exchangeOfferRepo := repository.NewExchangeOfferRepository(database)
+	exchangeMeetupRepo := repository.NewExchangeMeetupRepository(database)
+	exchangeHandoffRepo := repository.NewExchangeHandoffRepository(database)
+	exchangeMessageRepo := repository.NewExchangeMessageRepository(database)
+	tradeCycleRepo := repository.NewTradeCycleRepository(database)
+	transactor := repository.NewTransactor(database)
+	favouriteRepo := repository.NewFavouriteRepository(database)
+	recommendationRepo := repository.NewRecommendationRepository(database)
 
 	jwtService := jwt.NewJWTService(cfg)
-	notificationService := notification.NewNotificationService(notificationRepo)
+	notificationService := notification.NewNotificationService(notificationRepo, userRepo, mailer.NewMailer(cfg))
+	imageStorage, err := storage.NewStorage(cfg)
+	if err != nil {
+		log.Fatalf("Error Setting Up Image Storage: %v", err)
+	}
 
 	userUsecase := usecase.NewUserUsecase(userRepo, jwtService)
-	bookUsecase := usecase.NewBookUsecase(bookRepo)
-	exchangeUsecase := usecase.NewExchangeUsecase(exchangeRepo, bookRepo, notificationService)
+	metadataProvider, err := metadata.NewProvider(cfg)
+	if err != nil {
+		log.Fatalf("Error Setting Up Metadata Provider: %v", err)
+	}
+
+	wishlistUsecase := usecase.NewWishlistUsecase(wishlistRepo, bookRepo, notificationService)
+	bookUsecase := usecase.NewBookUsecase(&usecase.BookUsecaseConfig{
+		BookRepo:            bookRepo,
+		ImageRepo:           imageRepo,
+		WorkRepo:            workRepo,
+		ExchangeRepo:        exchangeRepo,
+		ExchangeEventRepo:   exchangeEventRepo,
+		LoanRepo:            loanRepo,
+		BookVersionRepo:     bookVersionRepo,
+		GenreRepo:           genreRepo,
+		TagRepo:             tagRepo,
+		MetadataProvider:    metadataProvider,
+		WishlistUsecase:     wishlistUsecase,
+		Transactor:          transactor,
+		NotificationService: notificationService,
+	})
+	if err := bookUsecase.BackfillPublicLocations(); err != nil {
+		log.Printf("Failed Backfilling Public Book Locations: %v", err)
+	}
+	workUsecase := usecase.NewWorkUsecase(workRepo)
+	genreUsecase := usecase.NewGenreUsecase(genreRepo)
+	tagUsecase := usecase.NewTagUsecase(tagRepo)
+	importUsecase := usecase.NewImportUsecase(importJobRepo, bookUsecase)
+	if err := importUsecase.FailInterruptedJobs(); err != nil {
+		log.Printf("Failed Cleaning Up Interrupted Import Jobs: %v", err)
+	}
+	imageUsecase := usecase.NewImageUsecase(imageRepo, imageStorage, cfg.Storage.ThumbnailSize)
+	savedSearchUsecase := usecase.NewSavedSearchUsecase(savedSearchRepo, notificationService)
+	loanUsecase := usecase.NewLoanUsecase(loanRepo, bookRepo, exchangeRepo, exchangeEventRepo, transactor, notificationService)
+	exchangeTimeouts := usecase.ExchangeTimeouts{
+		Pending:  time.Duration(cfg.Scheduler.PendingRequestTTL) * time.Second,
+		Accepted: time.Duration(cfg.Scheduler.AcceptedRequestTTL) * time.Second,
+	}
+	if exchangeTimeouts.Pending <= 0 {
+		exchangeTimeouts.Pending = 14 * 24 * time.Hour
+	}
+	if exchangeTimeouts.Accepted <= 0 {
+		exchangeTimeouts.Accepted = 7 * 24 * time.Hour
+	}
+	exchangeUsecase := usecase.NewExchangeUsecase(exchangeRepo, exchangeEventRepo, exchangeOfferRepo, exchangeMeetupRepo, exchangeHandoffRepo, userRepo, bookRepo, bookVersionRepo, transactor, notificationService, exchangeTimeouts, []byte(cfg.Server.JWTSecret))
+	if err := exchangeUsecase.BackfillItems(); err != nil {
+		log.Printf("Failed Backfilling Exchange Items: %v", err)
+	}
+	tradeCycleUsecase := usecase.NewTradeCycleUsecase(tradeCycleRepo, wishlistRepo, exchangeUsecase)
+	exchangeMessageUsecase := usecase.NewExchangeMessageUsecase(exchangeMessageRepo, exchangeRepo, imageRepo, transactor, notificationService)
+	wantedUsecase := usecase.NewWantedUsecase(wantedRepo, exchangeUsecase)
+	favouriteUsecase := usecase.NewFavouriteUsecase(favouriteRepo, bookRepo, notificationService)
+	recommendationUsecase := usecase.NewRecommendationUsecase(recommendationRepo, bookRepo, exchangeRepo, wishlistRepo, favouriteRepo)
 
 	httpUser.NewUserHandler(&httpUser.Config{
 		R:           router,
@@ -85,22 +173,116 @@ func main() {
 		JwtService:  jwtService,
 	})
 	httpBook.NewBookHandler(&httpBook.Config{
+		R:                     router,
+		BookUsecase:           bookUsecase,
+		ImportUsecase:         importUsecase,
+		RecommendationUsecase: recommendationUsecase,
+		JwtService:            jwtService,
+	})
+	httpWork.NewWorkHandler(&httpWork.Config{
 		R:           router,
-		BookUsecase: bookUsecase,
+		WorkUsecase: workUsecase,
 		JwtService:  jwtService,
 	})
+	httpGenre.NewGenreHandler(&httpGenre.Config{
+		R:            router,
+		GenreUsecase: genreUsecase,
+		JwtService:   jwtService,
+	})
+	httpTag.NewTagHandler(&httpTag.Config{
+		R:          router,
+		TagUsecase: tagUsecase,
+		JwtService: jwtService,
+	})
 	httpExchange.NewExchangeHandler(&httpExchange.Config{
+		R:                      router,
+		BookUsecase:            bookUsecase,
+		ExchangeUsecase:        exchangeUsecase,
+		ExchangeMessageUsecase: exchangeMessageUsecase,
+		JwtService:             jwtService,
+	})
+	httpWishlist.NewWishlistHandler(&httpWishlist.Config{
 		R:               router,
-		BookUsecase:     bookUsecase,
-		ExchangeUsecase: exchangeUsecase,
+		WishlistUsecase: wishlistUsecase,
 		JwtService:      jwtService,
 	})
+	httpCycle.NewCycleHandler(&httpCycle.Config{
+		R:                 router,
+		TradeCycleUsecase: tradeCycleUsecase,
+		JwtService:        jwtService,
+	})
+	httpFavourite.NewFavouriteHandler(&httpFavourite.Config{
+		R:                router,
+		FavouriteUsecase: favouriteUsecase,
+		JwtService:       jwtService,
+	})
+	httpLoan.NewLoanHandler(&httpLoan.Config{
+		R:           router,
+		BookUsecase: bookUsecase,
+		LoanUsecase: loanUsecase,
+		JwtService:  jwtService,
+	})
+	httpSearch.NewSearchHandler(&httpSearch.Config{
+		R:                  router,
+		SavedSearchUsecase: savedSearchUsecase,
+		JwtService:         jwtService,
+	})
+	httpWanted.NewWantedHandler(&httpWanted.Config{
+		R:             router,
+		BookUsecase:   bookUsecase,
+		WantedUsecase: wantedUsecase,
+		JwtService:    jwtService,
+	})
+	httpImage.NewImageHandler(&httpImage.Config{
+		R:             router,
+		ImageUsecase:  imageUsecase,
+		JwtService:    jwtService,
+		MaxUploadSize: cfg.Storage.MaxUploadSize,
+	})
 	httpNotification.NewNotificationHandler(&httpNotification.Config{
 		R:                   router,
 		NotificationService: notificationService,
 		JWTService:          jwtService,
 	})
 
+	digestInterval := time.Duration(cfg.Scheduler.DigestInterval) * time.Second
+	if digestInterval <= 0 {
+		digestInterval = 15 * time.Minute
+	}
+	reminderInterval := time.Duration(cfg.Scheduler.ReminderInterval) * time.Second
+	if reminderInterval <= 0 {
+		reminderInterval = time.Hour
+	}
+	recommendationInterval := time.Duration(cfg.Scheduler.RecommendationInterval) * time.Second
+	if recommendationInterval <= 0 {
+		recommendationInterval = 6 * time.Hour
+	}
+	expiryInterval := time.Duration(cfg.Scheduler.ExpiryInterval) * time.Second
+	if expiryInterval <= 0 {
+		expiryInterval = time.Hour
+	}
+	cycleInterval := time.Duration(cfg.Scheduler.CycleInterval) * time.Second
+	if cycleInterval <= 0 {
+		cycleInterval = 6 * time.Hour
+	}
+	meetupReminderInterval := time.Duration(cfg.Scheduler.MeetupReminderInterval) * time.Second
+	if meetupReminderInterval <= 0 {
+		meetupReminderInterval = 15 * time.Minute
+	}
+	wishlistMatchInterval := time.Duration(cfg.Scheduler.WishlistMatchInterval) * time.Second
+	if wishlistMatchInterval <= 0 {
+		wishlistMatchInterval = 5 * time.Minute
+	}
+	jobs := scheduler.New()
+	jobs.Every("saved-search-digests", digestInterval, savedSearchUsecase.RunDueDigests)
+	jobs.Every("loan-reminders", reminderInterval, loanUsecase.RunReminders)
+	jobs.Every("recommendations", recommendationInterval, recommendationUsecase.RunRecommendations)
+	jobs.Every("exchange-expiry", expiryInterval, exchangeUsecase.RunExpiry)
+	jobs.Every("trade-cycles", cycleInterval, tradeCycleUsecase.RunCycles)
+	jobs.Every("meetup-reminders", meetupReminderInterval, exchangeUsecase.RunMeetupReminders)
+	jobs.Every("wishlist-matches", wishlistMatchInterval, wishlistUsecase.RunPendingMatches)
+	jobs.Start()
+
 	srv := &http.Server{
 		Addr:           ":" + cfg.Server.Port,
 		Handler:        router,
@@ -121,6 +303,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Server Shutted Down...")
+	jobs.Stop()
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.Timeout)*time.Second)
 	defer cancel()
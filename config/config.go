@@ -29,9 +29,53 @@ type DatabaseConfiguration struct {
 	MaxIdleConns int
 }
 
+type StorageConfiguration struct {
+	Driver        string
+	LocalPath     string
+	S3Endpoint    string
+	S3Region      string
+	S3Bucket      string
+	S3AccessKey   string
+	S3SecretKey   string
+	S3PathStyle   bool
+	MaxUploadSize int64
+	ThumbnailSize int
+}
+
+type MetadataConfiguration struct {
+	Provider       string
+	OpenLibraryURL string
+	CatalogPath    string
+	CacheTTL       int
+}
+
+type MailConfiguration struct {
+	SMTPHost string
+	SMTPPort string
+	Username string
+	Password string
+	From     string
+}
+
+type SchedulerConfiguration struct {
+	DigestInterval         int
+	ReminderInterval       int
+	RecommendationInterval int
+	ExpiryInterval         int
+	PendingRequestTTL      int
+	AcceptedRequestTTL     int
+	CycleInterval          int
+	MeetupReminderInterval int
+	WishlistMatchInterval  int
+}
+
 type Configuration struct {
-	Server   ServerConfiguration
-	Database DatabaseConfiguration
+	Server    ServerConfiguration
+	Database  DatabaseConfiguration
+	Storage   StorageConfiguration
+	Metadata  MetadataConfiguration
+	Mail      MailConfiguration
+	Scheduler SchedulerConfiguration
 }
 
 var config *Configuration
@@ -50,6 +94,19 @@ func LoadConfig() {
 	dbMaxOpenConns, _ := strconv.Atoi(os.Getenv("DATABASE_MAX_OPEN_CONNS"))
 	dbMaxIdleConns, _ := strconv.Atoi(os.Getenv("DATABASE_MAX_IDLE_CONNS"))
 	jWTExpiry, _ := strconv.Atoi(os.Getenv("SERVER_JWTExpiry"))
+	s3PathStyle, _ := strconv.ParseBool(os.Getenv("STORAGE_S3_PATH_STYLE"))
+	maxUploadSize, _ := strconv.ParseInt(os.Getenv("STORAGE_MAX_UPLOAD_SIZE"), 10, 64)
+	thumbnailSize, _ := strconv.Atoi(os.Getenv("STORAGE_THUMBNAIL_SIZE"))
+	metadataCacheTTL, _ := strconv.Atoi(os.Getenv("METADATA_CACHE_TTL"))
+	digestInterval, _ := strconv.Atoi(os.Getenv("SCHEDULER_DIGEST_INTERVAL"))
+	reminderInterval, _ := strconv.Atoi(os.Getenv("SCHEDULER_REMINDER_INTERVAL"))
+	recommendationInterval, _ := strconv.Atoi(os.Getenv("SCHEDULER_RECOMMENDATION_INTERVAL"))
+	expiryInterval, _ := strconv.Atoi(os.Getenv("SCHEDULER_EXPIRY_INTERVAL"))
+	pendingRequestTTL, _ := strconv.Atoi(os.Getenv("SCHEDULER_PENDING_REQUEST_TTL"))
+	acceptedRequestTTL, _ := strconv.Atoi(os.Getenv("SCHEDULER_ACCEPTED_REQUEST_TTL"))
+	cycleInterval, _ := strconv.Atoi(os.Getenv("SCHEDULER_CYCLE_INTERVAL"))
+	meetupReminderInterval, _ := strconv.Atoi(os.Getenv("SCHEDULER_MEETUP_REMINDER_INTERVAL"))
+	wishlistMatchInterval, _ := strconv.Atoi(os.Getenv("SCHEDULER_WISHLIST_MATCH_INTERVAL"))
 
 	cfg := &Configuration{
 		Server: ServerConfiguration{
@@ -71,6 +128,42 @@ func LoadConfig() {
 			MaxOpenConns: dbMaxOpenConns,
 			MaxIdleConns: dbMaxIdleConns,
 		},
+		Storage: StorageConfiguration{
+			Driver:        os.Getenv("STORAGE_DRIVER"),
+			LocalPath:     os.Getenv("STORAGE_LOCAL_PATH"),
+			S3Endpoint:    os.Getenv("STORAGE_S3_ENDPOINT"),
+			S3Region:      os.Getenv("STORAGE_S3_REGION"),
+			S3Bucket:      os.Getenv("STORAGE_S3_BUCKET"),
+			S3AccessKey:   os.Getenv("STORAGE_S3_ACCESS_KEY"),
+			S3SecretKey:   os.Getenv("STORAGE_S3_SECRET_KEY"),
+			S3PathStyle:   s3PathStyle,
+			MaxUploadSize: maxUploadSize,
+			ThumbnailSize: thumbnailSize,
+		},
+		Metadata: MetadataConfiguration{
+			Provider:       os.Getenv("METADATA_PROVIDER"),
+			OpenLibraryURL: os.Getenv("METADATA_OPENLIBRARY_URL"),
+			CatalogPath:    os.Getenv("METADATA_CATALOG_PATH"),
+			CacheTTL:       metadataCacheTTL,
+		},
+		Mail: MailConfiguration{
+			SMTPHost: os.Getenv("MAIL_SMTP_HOST"),
+			SMTPPort: os.Getenv("MAIL_SMTP_PORT"),
+			Username: os.Getenv("MAIL_USERNAME"),
+			Password: os.Getenv("MAIL_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		},
+		Scheduler: SchedulerConfiguration{
+			DigestInterval:         digestInterval,
+			ReminderInterval:       reminderInterval,
+			RecommendationInterval: recommendationInterval,
+			ExpiryInterval:         expiryInterval,
+			PendingRequestTTL:      pendingRequestTTL,
+			AcceptedRequestTTL:     acceptedRequestTTL,
+			CycleInterval:          cycleInterval,
+			MeetupReminderInterval: meetupReminderInterval,
+			WishlistMatchInterval:  wishlistMatchInterval,
+		},
 	}
 
 	config = cfg
@@ -51,7 +51,7 @@ func SetupPostgres() {
 }
 
 func migrate() error {
-	return db.AutoMigrate(&entity.User{}, &entity.Book{}, &entity.ExchangeRequest{}, &entity.Notification{})
+	return db.AutoMigrate(&entity.User{}, &entity.Work{}, &entity.Genre{}, &entity.GenreAlias{}, &entity.Tag{}, &entity.Book{}, &entity.Image{}, &entity.BookVersion{}, &entity.TradeCycle{}, &entity.TradeCycleParticipant{}, &entity.ExchangeRequest{}, &entity.ExchangeItem{}, &entity.ExchangeEvent{}, &entity.ExchangeOffer{}, &entity.ExchangeMeetup{}, &entity.ExchangeHandoff{}, &entity.ExchangeMessage{}, &entity.Notification{}, &entity.ImportJob{}, &entity.WishlistItem{}, &entity.WishlistMatch{}, &entity.PendingBookMatch{}, &entity.SavedSearch{}, &entity.Loan{}, &entity.WantedPost{}, &entity.Favourite{}, &entity.Recommendation{})
 }
 
 func GetDB() *gorm.DB {
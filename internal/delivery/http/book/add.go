@@ -5,21 +5,30 @@ import (
 	"net/http"
 
 	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/geo"
+	"github.com/arjnep/gyanpass/pkg/isbn"
 	"github.com/arjnep/gyanpass/pkg/jwt"
 	"github.com/arjnep/gyanpass/pkg/response"
 	"github.com/arjnep/gyanpass/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type addBookReq struct {
-	Title       string             `gorm:"not null" json:"title" binding:"required"`
-	Author      string             `gorm:"not null" json:"author" binding:"required"`
-	Genre       string             `json:"genre" binding:"omitempty"`
-	ImageUrl    string             `gorm:"not null" json:"image_url" binding:"required"`
-	Address     string             `json:"address" binding:"omitempty"`
-	Description entity.Description `gorm:"embedded" json:"description" binding:"required"`
-	Latitude    float64            `gorm:"not null" json:"latitude" binding:"required,latitude"`
-	Longitude   float64            `gorm:"not null" json:"longitude" binding:"required,longitude"`
+	Title             string             `gorm:"not null" json:"title" binding:"required_without=ISBN"`
+	Author            string             `gorm:"not null" json:"author" binding:"required_without=ISBN"`
+	Genre             string             `json:"genre" binding:"omitempty"`
+	ISBN              string             `json:"isbn" binding:"omitempty"`
+	ImageUrl          string             `gorm:"not null" json:"image_url" binding:"omitempty"`
+	ImageIDs          []uuid.UUID        `json:"image_ids" binding:"omitempty,max=8"`
+	Tags              []string           `json:"tags" binding:"omitempty,max=10"`
+	Address           string             `json:"address" binding:"omitempty"`
+	Description       entity.Description `gorm:"embedded" json:"description" binding:"required"`
+	ListingType       string             `json:"listing_type" binding:"omitempty,oneof=swap giveaway either"`
+	ClaimMode         string             `json:"claim_mode" binding:"omitempty,oneof=choose first"`
+	LocationPrecision string             `json:"location_precision" binding:"omitempty,oneof=neighbourhood district city"`
+	Latitude          float64            `gorm:"not null" json:"latitude" binding:"required,latitude"`
+	Longitude         float64            `gorm:"not null" json:"longitude" binding:"required,longitude"`
 }
 
 func (h *BookHandler) AddBook(c *gin.Context) {
@@ -39,23 +48,15 @@ func (h *BookHandler) AddBook(c *gin.Context) {
 		return
 	}
 
-	newBook := entity.Book{
-		Title:       req.Title,
-		Author:      req.Author,
-		Genre:       req.Genre,
-		Description: req.Description,
-		ImageUrl:    req.ImageUrl,
-		Owner:       *authUser.(*jwt.TokenClaims).User,
-		UserID:      authUser.(*jwt.TokenClaims).User.UID,
-		PickupLocation: entity.Location{
-			Address:   req.Address,
-			Latitude:  req.Latitude,
-			Longitude: req.Longitude,
-		},
-		IsActive: true,
+	newBook, err := req.toBook(authUser.(*jwt.TokenClaims).User)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
 	}
 
-	err := h.bookUsecase.AddBook(&newBook)
+	err = h.bookUsecase.AddBook(newBook, req.ImageIDs)
 	if err != nil {
 		log.Printf("Failed to add new Book: %v", err)
 		c.JSON(response.Status(err), gin.H{
@@ -69,3 +70,53 @@ func (h *BookHandler) AddBook(c *gin.Context) {
 	})
 
 }
+
+func (req *addBookReq) toBook(owner *entity.User) (*entity.Book, error) {
+	normalizedISBN := ""
+	if req.ISBN != "" {
+		var err error
+		normalizedISBN, err = isbn.Normalize(req.ISBN)
+		if err != nil {
+			return nil, response.NewBadRequestError("isbn is not a valid ISBN-10 or ISBN-13")
+		}
+	}
+
+	listingType := req.ListingType
+	if listingType == "" {
+		listingType = "swap"
+	}
+	claimMode := req.ClaimMode
+	if claimMode == "" {
+		claimMode = "choose"
+	}
+	locationPrecision := req.LocationPrecision
+	if locationPrecision == "" {
+		locationPrecision = geo.PrecisionNeighbourhood
+	}
+
+	var tags []entity.Tag
+	for _, name := range req.Tags {
+		tags = append(tags, entity.Tag{Name: name})
+	}
+
+	return &entity.Book{
+		Title:       req.Title,
+		Author:      req.Author,
+		Genre:       req.Genre,
+		ISBN:        normalizedISBN,
+		Description: req.Description,
+		ImageUrl:    req.ImageUrl,
+		Tags:        tags,
+		Owner:       *owner,
+		UserID:      owner.UID,
+		PickupLocation: entity.Location{
+			Address:   req.Address,
+			Latitude:  req.Latitude,
+			Longitude: req.Longitude,
+		},
+		LocationPrecision: locationPrecision,
+		IsActive:          true,
+		ListingType:       listingType,
+		ClaimMode:         claimMode,
+	}, nil
+}
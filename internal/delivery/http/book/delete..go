@@ -8,7 +8,6 @@ import (
 	"github.com/arjnep/gyanpass/pkg/jwt"
 	"github.com/arjnep/gyanpass/pkg/response"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 func (h *BookHandler) DeleteBook(c *gin.Context) {
@@ -25,16 +24,8 @@ func (h *BookHandler) DeleteBook(c *gin.Context) {
 
 	existingBook, err := h.bookUsecase.GetBookByID(uint(pathBookID))
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			err := response.NewNotFoundError("book", c.Param("id"))
-			c.JSON(err.Status(), gin.H{
-				"error": err,
-			})
-			return
-		}
 		log.Println("Failed Getting Book To Delete:", err)
-		err := response.NewInternalServerError()
-		c.JSON(err.Status(), gin.H{
+		c.JSON(response.Status(err), gin.H{
 			"error": err,
 		})
 		return
@@ -51,10 +42,10 @@ func (h *BookHandler) DeleteBook(c *gin.Context) {
 	err = h.bookUsecase.DeleteBook(existingBook)
 	if err != nil {
 		log.Println("Failed Deleting Book: ", err)
-		err := response.NewInternalServerError()
-		c.JSON(err.Status(), gin.H{
+		c.JSON(response.Status(err), gin.H{
 			"error": err,
 		})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,129 @@
+package book
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type exportBook struct {
+	ID uint `json:"id"`
+	addBookReq
+	IsActive bool `json:"is_active"`
+}
+
+func (h *BookHandler) ExportBooks(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "ndjson" {
+		err := response.NewBadRequestError("format must be csv or ndjson")
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	books, err := h.bookUsecase.GetBooksByUserID(authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Get Books For Export: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if format == "ndjson" {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", `attachment; filename="books.ndjson"`)
+		c.Status(http.StatusOK)
+
+		encoder := json.NewEncoder(c.Writer)
+		for i := range books {
+			if err := encoder.Encode(toExportBook(&books[i])); err != nil {
+				log.Println("Failed Writing Export:", err)
+				return
+			}
+		}
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", `attachment; filename="books.csv"`)
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+	header := append([]string{"id"}, bookCSVColumns...)
+	writer.Write(append(header, "is_active"))
+	for i := range books {
+		book := toExportBook(&books[i])
+		imageIDs := make([]string, len(book.ImageIDs))
+		for j, id := range book.ImageIDs {
+			imageIDs[j] = id.String()
+		}
+		writer.Write([]string{
+			strconv.FormatUint(uint64(book.ID), 10),
+			book.Title,
+			book.Author,
+			book.Genre,
+			book.ISBN,
+			book.ImageUrl,
+			strings.Join(imageIDs, ";"),
+			book.Address,
+			book.Description.Message,
+			book.Description.Condition,
+			book.Description.PreferredExchange,
+			strconv.FormatFloat(book.Latitude, 'f', -1, 64),
+			strconv.FormatFloat(book.Longitude, 'f', -1, 64),
+			book.ListingType,
+			book.ClaimMode,
+			strings.Join(book.Tags, ";"),
+			book.LocationPrecision,
+			strconv.FormatBool(book.IsActive),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Println("Failed Writing Export:", err)
+	}
+}
+
+func toExportBook(book *entity.Book) exportBook {
+	var imageIDs []uuid.UUID
+	for _, image := range book.Images {
+		imageIDs = append(imageIDs, image.ID)
+	}
+	var tags []string
+	for _, tag := range book.Tags {
+		tags = append(tags, tag.Name)
+	}
+	return exportBook{
+		ID: book.ID,
+		addBookReq: addBookReq{
+			Title:             book.Title,
+			Author:            book.Author,
+			Genre:             book.Genre,
+			ISBN:              book.ISBN,
+			ImageUrl:          book.ImageUrl,
+			ImageIDs:          imageIDs,
+			Address:           book.PickupLocation.Address,
+			Description:       book.Description,
+			Latitude:          book.PickupLocation.Latitude,
+			Longitude:         book.PickupLocation.Longitude,
+			ListingType:       book.ListingType,
+			ClaimMode:         book.ClaimMode,
+			Tags:              tags,
+			LocationPrecision: book.LocationPrecision,
+		},
+		IsActive: book.IsActive,
+	}
+}
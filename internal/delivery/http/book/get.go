@@ -44,6 +44,23 @@ func (h *BookHandler) GetUserBooks(c *gin.Context) {
 
 }
 
+func (h *BookHandler) GetArchivedBooks(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	books, err := h.bookUsecase.GetArchivedBooksByUserID(authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Get Archived Books: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"books": books,
+	})
+}
+
 func (h *BookHandler) GetBook(c *gin.Context) {
 	pathBookID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -83,13 +100,20 @@ func (h *BookHandler) GetBook(c *gin.Context) {
 			"title":       book.Title,
 			"author":      book.Author,
 			"genre":       book.Genre,
+			"isbn":        book.ISBN,
 			"description": book.Description,
 			"image_url":   book.ImageUrl,
+			"images":      book.Images,
 			"owner": gin.H{
 				"user_id":    book.Owner.UID,
 				"first_name": book.Owner.FirstName,
 				"last_name":  book.Owner.LastName,
 			},
+			"location": gin.H{
+				"latitude":  book.PublicLatitude,
+				"longitude": book.PublicLongitude,
+				"precision": book.LocationPrecision,
+			},
 			"is_active": book.IsActive,
 		}
 	}
@@ -9,21 +9,27 @@ import (
 )
 
 type BookHandler struct {
-	bookUsecase usecase.BookUsecase
-	jwtService  jwt.Service
-	Cfg         *config.Configuration
+	bookUsecase           usecase.BookUsecase
+	importUsecase         usecase.ImportUsecase
+	recommendationUsecase usecase.RecommendationUsecase
+	jwtService            jwt.Service
+	Cfg                   *config.Configuration
 }
 
 type Config struct {
-	R           *gin.Engine
-	BookUsecase usecase.BookUsecase
-	JwtService  jwt.Service
+	R                     *gin.Engine
+	BookUsecase           usecase.BookUsecase
+	ImportUsecase         usecase.ImportUsecase
+	RecommendationUsecase usecase.RecommendationUsecase
+	JwtService            jwt.Service
 }
 
 func NewBookHandler(c *Config) {
 	h := &BookHandler{
-		bookUsecase: c.BookUsecase,
-		jwtService:  c.JwtService,
+		bookUsecase:           c.BookUsecase,
+		importUsecase:         c.ImportUsecase,
+		recommendationUsecase: c.RecommendationUsecase,
+		jwtService:            c.JwtService,
 	}
 
 	bookRoutes := c.R.Group("/api/books")
@@ -31,7 +37,15 @@ func NewBookHandler(c *Config) {
 		bookRoutes.GET("/", middleware.AuthUser(h.jwtService), h.GetUserBooks)
 		bookRoutes.POST("/", middleware.AuthUser(h.jwtService), h.AddBook)
 		bookRoutes.GET("/search", middleware.Pagination(), h.SearchBooks)
+		bookRoutes.GET("/recommended", middleware.AuthUser(h.jwtService), middleware.Pagination(), h.GetRecommendedBooks)
+		bookRoutes.GET("/archived", middleware.AuthUser(h.jwtService), h.GetArchivedBooks)
+		bookRoutes.GET("/isbn/:isbn", middleware.AuthUser(h.jwtService), h.LookupISBN)
+		bookRoutes.POST("/import", middleware.AuthUser(h.jwtService), h.ImportBooks)
+		bookRoutes.GET("/import", middleware.AuthUser(h.jwtService), h.GetImportJobs)
+		bookRoutes.GET("/import/:id", middleware.AuthUser(h.jwtService), h.GetImportJob)
+		bookRoutes.GET("/export", middleware.AuthUser(h.jwtService), h.ExportBooks)
 		bookRoutes.GET("/:id", middleware.AuthUser(h.jwtService), h.GetBook)
+		bookRoutes.GET("/:id/history", middleware.AuthUser(h.jwtService), h.GetBookHistory)
 		bookRoutes.PUT("/:id", middleware.AuthUser(h.jwtService), h.UpdateBook)
 		bookRoutes.DELETE("/:id", middleware.AuthUser(h.jwtService), h.DeleteBook)
 	}
@@ -0,0 +1,55 @@
+package book
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *BookHandler) GetBookHistory(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	pathBookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		err := response.NewBadRequestError("id of book should be number")
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	sinceVersion, err := strconv.Atoi(c.DefaultQuery("since", "0"))
+	if err != nil || sinceVersion < 0 {
+		err := response.NewBadRequestError("since should be a positive version number")
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	book, err := h.bookUsecase.GetBookByID(uint(pathBookID))
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	versions, err := h.bookUsecase.GetBookHistory(book, authUser.UID, sinceVersion)
+	if err != nil {
+		log.Printf("Failed to Get Book History: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"version":  book.Version,
+		"versions": versions,
+	})
+}
@@ -0,0 +1,307 @@
+package book
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/usecase"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+const maxImportSize = 5 << 20
+
+var bookCSVColumns = []string{
+	"title", "author", "genre", "isbn", "image_url", "image_ids", "address",
+	"message", "condition", "preferred_exchange", "latitude", "longitude",
+	"listing_type", "claim_mode", "tags", "location_precision",
+}
+
+var errTooManyRows = fmt.Errorf("import is limited to %d rows", usecase.MaxImportRows)
+
+func (h *BookHandler) ImportBooks(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	var format string
+	switch c.ContentType() {
+	case "text/csv":
+		format = "csv"
+	case "application/x-ndjson", "application/ndjson":
+		format = "ndjson"
+	default:
+		err := response.NewUnsupportedMediaTypeError(c.FullPath() + " only accepts Content-Type text/csv or application/x-ndjson")
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		err := response.NewBadRequestError("dry_run must be true or false")
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	var rows []usecase.ImportRow
+	if format == "csv" {
+		rows, err = parseCSVRows(body, authUser)
+	} else {
+		rows, err = parseNDJSONRows(body, authUser)
+	}
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			err := response.NewPayloadTooLargeError(maxImportSize, c.Request.ContentLength)
+			c.JSON(err.Status(), gin.H{
+				"error": err,
+			})
+			return
+		}
+		err := response.NewBadRequestError(err.Error())
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	job, err := h.importUsecase.StartImport(authUser.UID, format, dryRun, rows)
+	if err != nil {
+		log.Printf("Failed to Start Import: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"job": job,
+	})
+}
+
+func (h *BookHandler) GetImportJobs(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	jobs, err := h.importUsecase.GetImportJobs(authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Get Import Jobs: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"jobs": jobs,
+	})
+}
+
+func (h *BookHandler) GetImportJob(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	pathJobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("import job", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	job, err := h.importUsecase.GetImportJob(pathJobID, authUser.UID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"job": job,
+	})
+}
+
+func parseCSVRows(r io.Reader, owner *entity.User) ([]usecase.ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("import file is empty")
+	} else if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"condition", "preferred_exchange", "latitude", "longitude"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv header is missing column %q", required)
+		}
+	}
+
+	var rows []usecase.ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		if len(rows) == usecase.MaxImportRows {
+			return nil, errTooManyRows
+		}
+
+		row := usecase.ImportRow{Row: line}
+		if err != nil {
+			row.Err = "wrong number of fields"
+			rows = append(rows, row)
+			continue
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		req := addBookReq{
+			Title:    field("title"),
+			Author:   field("author"),
+			Genre:    field("genre"),
+			ISBN:     field("isbn"),
+			ImageUrl: field("image_url"),
+			Address:  field("address"),
+			Description: entity.Description{
+				Message:           field("message"),
+				Condition:         field("condition"),
+				PreferredExchange: field("preferred_exchange"),
+			},
+			ListingType:       field("listing_type"),
+			ClaimMode:         field("claim_mode"),
+			Tags:              parseTags(field("tags")),
+			LocationPrecision: field("location_precision"),
+		}
+
+		if req.Latitude, err = parseCoordinate(field("latitude")); err != nil {
+			row.Err = "latitude must be a number"
+		} else if req.Longitude, err = parseCoordinate(field("longitude")); err != nil {
+			row.Err = "longitude must be a number"
+		} else if req.ImageIDs, err = parseImageIDs(field("image_ids")); err != nil {
+			row.Err = "image_ids must be uuids separated by ';'"
+		} else {
+			row.Book, row.ImageIDs, row.Err = validateImportRow(&req, owner)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseNDJSONRows(r io.Reader, owner *entity.User) ([]usecase.ImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+
+	var rows []usecase.ImportRow
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if len(rows) == usecase.MaxImportRows {
+			return nil, errTooManyRows
+		}
+
+		row := usecase.ImportRow{Row: line}
+		var req addBookReq
+		if err := json.Unmarshal([]byte(text), &req); err != nil {
+			row.Err = "malformed json"
+		} else {
+			row.Book, row.ImageIDs, row.Err = validateImportRow(&req, owner)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("import file is empty")
+	}
+	return rows, nil
+}
+
+// validateImportRow applies the same binding rules as AddBook to one row.
+func validateImportRow(req *addBookReq, owner *entity.User) (*entity.Book, []uuid.UUID, string) {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		var errs validator.ValidationErrors
+		if errors.As(err, &errs) {
+			messages := make([]string, 0, len(errs))
+			for _, fieldErr := range errs {
+				messages = append(messages, fmt.Sprintf("%s failed on %s", strings.TrimPrefix(fieldErr.Namespace(), "addBookReq."), fieldErr.Tag()))
+			}
+			return nil, nil, strings.Join(messages, "; ")
+		}
+		return nil, nil, err.Error()
+	}
+
+	book, err := req.toBook(owner)
+	if err != nil {
+		return nil, nil, err.Error()
+	}
+	return book, req.ImageIDs, ""
+}
+
+func parseCoordinate(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+func parseTags(s string) []string {
+	var tags []string
+	for _, part := range strings.Split(s, ";") {
+		if tag := strings.TrimSpace(part); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func parseImageIDs(s string) ([]uuid.UUID, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var ids []uuid.UUID
+	for _, part := range strings.Split(s, ";") {
+		id, err := uuid.Parse(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,34 @@
+package book
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/pkg/isbn"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *BookHandler) LookupISBN(c *gin.Context) {
+	normalizedISBN, err := isbn.Normalize(c.Param("isbn"))
+	if err != nil {
+		err := response.NewBadRequestError("isbn is not a valid ISBN-10 or ISBN-13")
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	meta, err := h.bookUsecase.LookupISBN(normalizedISBN)
+	if err != nil {
+		log.Printf("Failed to Lookup ISBN: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"metadata": meta,
+	})
+}
@@ -0,0 +1,68 @@
+package book
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *BookHandler) GetRecommendedBooks(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	page, _ := c.Get("page")
+	size, _ := c.Get("size")
+
+	pageInt, ok := page.(int)
+	if !ok {
+		pageInt = 1
+	}
+	sizeInt, ok := size.(int)
+	if !ok {
+		sizeInt = 10
+	}
+
+	recommendations, total, err := h.recommendationUsecase.GetRecommendations(authUser.UID, pageInt, sizeInt)
+	if err != nil {
+		log.Printf("Failed to Get Recommended Books: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	var booksResponse []gin.H
+	for _, recommendation := range recommendations {
+		book := recommendation.Book
+		thumbnailUrl := ""
+		if len(book.Images) > 0 {
+			thumbnailUrl = book.Images[0].ThumbnailURL
+		}
+		booksResponse = append(booksResponse, gin.H{
+			"id":            book.ID,
+			"title":         book.Title,
+			"author":        book.Author,
+			"genre":         book.Genre,
+			"genre_id":      book.GenreID,
+			"tags":          book.Tags,
+			"image_url":     book.ImageUrl,
+			"thumbnail_url": thumbnailUrl,
+			"listing_type":  book.ListingType,
+			"address":       book.PickupLocation.Address,
+			"score":         recommendation.Score,
+			"reason":        recommendation.Reason,
+		})
+	}
+
+	totalPages := (total + sizeInt - 1) / sizeInt
+
+	c.JSON(http.StatusOK, gin.H{
+		"books":       booksResponse,
+		"page":        pageInt,
+		"size":        sizeInt,
+		"total":       total,
+		"total_pages": totalPages,
+	})
+}
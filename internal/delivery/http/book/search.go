@@ -4,14 +4,39 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/arjnep/gyanpass/pkg/isbn"
 	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/taxonomy"
 	"github.com/gin-gonic/gin"
 )
 
 func (h *BookHandler) SearchBooks(c *gin.Context) {
 	queryParams := map[string]string{
-		"title":   c.Query("title"),
-		"address": c.Query("address"),
+		"title":        c.Query("title"),
+		"address":      c.Query("address"),
+		"listing_type": c.Query("listing_type"),
+		"genre":        taxonomy.Normalize(c.Query("genre")),
+		"tag":          taxonomy.Normalize(c.Query("tag")),
+	}
+
+	if lt := c.Query("listing_type"); lt != "" && lt != "swap" && lt != "giveaway" {
+		err := response.NewBadRequestError("listing_type must be swap or giveaway")
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if c.Query("isbn") != "" {
+		normalizedISBN, err := isbn.Normalize(c.Query("isbn"))
+		if err != nil {
+			err := response.NewBadRequestError("isbn is not a valid ISBN-10 or ISBN-13")
+			c.JSON(err.Status(), gin.H{
+				"error": err,
+			})
+			return
+		}
+		queryParams["isbn"] = normalizedISBN
 	}
 
 	page, _ := c.Get("page")
@@ -36,12 +61,21 @@ func (h *BookHandler) SearchBooks(c *gin.Context) {
 
 	var booksResponse []gin.H
 	for _, book := range books {
+		thumbnailUrl := ""
+		if len(book.Images) > 0 {
+			thumbnailUrl = book.Images[0].ThumbnailURL
+		}
 		booksResponse = append(booksResponse, gin.H{
-			"id":        book.ID,
-			"title":     book.Title,
-			"author":    book.Author,
-			"genre":     book.Genre,
-			"image_url": book.ImageUrl,
+			"id":            book.ID,
+			"title":         book.Title,
+			"author":        book.Author,
+			"genre":         book.Genre,
+			"genre_id":      book.GenreID,
+			"tags":          book.Tags,
+			"isbn":          book.ISBN,
+			"image_url":     book.ImageUrl,
+			"thumbnail_url": thumbnailUrl,
+			"listing_type":  book.ListingType,
 		})
 	}
 
@@ -6,21 +6,29 @@ import (
 	"strconv"
 
 	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/isbn"
 	"github.com/arjnep/gyanpass/pkg/jwt"
 	"github.com/arjnep/gyanpass/pkg/response"
 	"github.com/arjnep/gyanpass/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type updateBookReq struct {
-	Title       string              `gorm:"not null" json:"title" binding:"omitempty"`
-	Author      string              `gorm:"not null" json:"author" binding:"omitempty"`
-	Genre       string              `json:"genre" binding:"omitempty"`
-	Description *entity.Description `json:"description" binding:"omitempty"`
-	ImageUrl    string              `gorm:"not null" json:"image_url" binding:"omitempty"`
-	Address     string              `json:"address" binding:"omitempty"`
-	Latitude    float64             `gorm:"not null" json:"latitude" binding:"omitempty,latitude"`
-	Longitude   float64             `gorm:"not null" json:"longitude" binding:"omitempty,longitude"`
+	Title             string              `gorm:"not null" json:"title" binding:"omitempty"`
+	Author            string              `gorm:"not null" json:"author" binding:"omitempty"`
+	Genre             string              `json:"genre" binding:"omitempty"`
+	ISBN              string              `json:"isbn" binding:"omitempty"`
+	Description       *entity.Description `json:"description" binding:"omitempty"`
+	ImageUrl          string              `gorm:"not null" json:"image_url" binding:"omitempty"`
+	ImageIDs          []uuid.UUID         `json:"image_ids" binding:"omitempty,max=8"`
+	Tags              []string            `json:"tags" binding:"omitempty,max=10"`
+	Address           string              `json:"address" binding:"omitempty"`
+	ListingType       string              `json:"listing_type" binding:"omitempty,oneof=swap giveaway either"`
+	ClaimMode         string              `json:"claim_mode" binding:"omitempty,oneof=choose first"`
+	LocationPrecision string              `json:"location_precision" binding:"omitempty,oneof=neighbourhood district city"`
+	Latitude          float64             `gorm:"not null" json:"latitude" binding:"omitempty,latitude"`
+	Longitude         float64             `gorm:"not null" json:"longitude" binding:"omitempty,longitude"`
 }
 
 func (h *BookHandler) UpdateBook(c *gin.Context) {
@@ -69,6 +77,20 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 		updates["genre"] = req.Genre
 	}
 
+	if req.ISBN != "" {
+		normalizedISBN, err := isbn.Normalize(req.ISBN)
+		if err != nil {
+			err := response.NewBadRequestError("isbn is not a valid ISBN-10 or ISBN-13")
+			c.JSON(err.Status(), gin.H{
+				"error": err,
+			})
+			return
+		}
+		if normalizedISBN != existingBook.ISBN {
+			updates["isbn"] = normalizedISBN
+		}
+	}
+
 	if req.Description != nil {
 		log.Println(req.Description)
 		if req.Description.Message != "" && req.Description.Message != existingBook.Description.Message {
@@ -90,6 +112,18 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 		updates["address"] = req.Address
 	}
 
+	if req.ListingType != "" && req.ListingType != existingBook.ListingType {
+		updates["listing_type"] = req.ListingType
+	}
+
+	if req.ClaimMode != "" && req.ClaimMode != existingBook.ClaimMode {
+		updates["claim_mode"] = req.ClaimMode
+	}
+
+	if req.LocationPrecision != "" && req.LocationPrecision != existingBook.LocationPrecision {
+		updates["location_precision"] = req.LocationPrecision
+	}
+
 	if req.Latitude != 0 && req.Latitude != existingBook.PickupLocation.Latitude {
 		updates["latitude"] = req.Latitude
 	}
@@ -98,7 +132,7 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 		updates["longitude"] = req.Longitude
 	}
 
-	if len(updates) == 0 {
+	if len(updates) == 0 && req.ImageIDs == nil && req.Tags == nil {
 		err := response.NewBadRequestError("No fields to update")
 		c.JSON(err.Status(), gin.H{
 			"error": err,
@@ -106,13 +140,37 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 		return
 	}
 
-	err = h.bookUsecase.UpdateBook(existingBook, updates)
-	if err != nil {
-		log.Printf("Failed to update book: %v\n", err.Error())
-		c.JSON(response.Status(err), gin.H{
-			"error": err,
-		})
-		return
+	if len(updates) > 0 {
+		err = h.bookUsecase.UpdateBook(existingBook, updates)
+		if err != nil {
+			log.Printf("Failed to update book: %v\n", err.Error())
+			c.JSON(response.Status(err), gin.H{
+				"error": err,
+			})
+			return
+		}
+	}
+
+	if req.ImageIDs != nil {
+		err = h.bookUsecase.SetBookImages(existingBook, req.ImageIDs)
+		if err != nil {
+			log.Printf("Failed to update book images: %v\n", err.Error())
+			c.JSON(response.Status(err), gin.H{
+				"error": err,
+			})
+			return
+		}
+	}
+
+	if req.Tags != nil {
+		err = h.bookUsecase.SetBookTags(existingBook, req.Tags)
+		if err != nil {
+			log.Printf("Failed to update book tags: %v\n", err.Error())
+			c.JSON(response.Status(err), gin.H{
+				"error": err,
+			})
+			return
+		}
 	}
 
 	updatedBook, err := h.bookUsecase.GetBookByID(existingBook.ID)
@@ -0,0 +1,109 @@
+package cycle
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *CycleHandler) GetCycles(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	cycles, err := h.tradeCycleUsecase.GetCyclesByUserID(authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Get Trade Cycles: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cycles": cycles,
+	})
+}
+
+func (h *CycleHandler) GetCycle(c *gin.Context) {
+	cycle, ok := h.fetchCycle(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"cycle": cycle,
+	})
+}
+
+func (h *CycleHandler) AcceptCycle(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	cycle, ok := h.fetchCycle(c)
+	if !ok {
+		return
+	}
+
+	err := h.tradeCycleUsecase.AcceptCycle(cycle, authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Accept Trade Cycle: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "trade cycle accepted",
+		"status":  cycle.Status,
+	})
+}
+
+func (h *CycleHandler) DeclineCycle(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	cycle, ok := h.fetchCycle(c)
+	if !ok {
+		return
+	}
+
+	err := h.tradeCycleUsecase.DeclineCycle(cycle, authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Decline Trade Cycle: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "trade cycle declined",
+	})
+}
+
+// fetchCycle loads the cycle in the path for the logged in user, replying
+// with the error itself when it cannot.
+func (h *CycleHandler) fetchCycle(c *gin.Context) (*entity.TradeCycle, bool) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("trade cycle", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+
+	cycle, err := h.tradeCycleUsecase.GetCycleByID(id, authUser.UID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+	return cycle, true
+}
@@ -0,0 +1,34 @@
+package cycle
+
+import (
+	"github.com/arjnep/gyanpass/internal/delivery/middleware"
+	"github.com/arjnep/gyanpass/internal/usecase"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+type CycleHandler struct {
+	tradeCycleUsecase usecase.TradeCycleUsecase
+	jwtService        jwt.Service
+}
+
+type Config struct {
+	R                 *gin.Engine
+	TradeCycleUsecase usecase.TradeCycleUsecase
+	JwtService        jwt.Service
+}
+
+func NewCycleHandler(c *Config) {
+	h := &CycleHandler{
+		tradeCycleUsecase: c.TradeCycleUsecase,
+		jwtService:        c.JwtService,
+	}
+
+	cycleRoutes := c.R.Group("/api/exchange/cycles")
+	{
+		cycleRoutes.GET("/", middleware.AuthUser(h.jwtService), h.GetCycles)
+		cycleRoutes.GET("/:id", middleware.AuthUser(h.jwtService), h.GetCycle)
+		cycleRoutes.POST("/:id/accept", middleware.AuthUser(h.jwtService), h.AcceptCycle)
+		cycleRoutes.POST("/:id/decline", middleware.AuthUser(h.jwtService), h.DeclineCycle)
+	}
+}
@@ -0,0 +1,166 @@
+package exchange
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type cancelExchangeReq struct {
+	Reason   string `json:"reason" binding:"required,max=500"`
+	OneSided bool   `json:"one_sided"`
+}
+
+type disputeExchangeReq struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type resolveDisputeReq struct {
+	Outcome string `json:"outcome" binding:"required,oneof=exchanged cancelled"`
+	AtFault string `json:"at_fault" binding:"omitempty,oneof=requester owner"`
+	Note    string `json:"note" binding:"max=500"`
+}
+
+// CancelExchangeRequest asks to call off an accepted exchange, or calls it
+// off at once when one_sided is set or the other side asked already.
+func (h *ExchangeHandler) CancelExchangeRequest(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	var req cancelExchangeReq
+	ok := utils.BindData(c, &req)
+	if !ok {
+		return
+	}
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fetchedExchangeRequest, err := h.exchangeUsecase.GetExchangeRequestByID(pathExchangeRequestID, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	err = h.exchangeUsecase.CancelExchange(fetchedExchangeRequest, loggedInUserID, req.Reason, req.OneSided)
+	if err != nil {
+		log.Printf("Failed To Cancel Exchange Request %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	message := "exchange request cancelled"
+	if fetchedExchangeRequest.Status != entity.ExchangeCancelled {
+		message = "cancellation requested, waiting for the other side"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"status":  fetchedExchangeRequest.Status,
+	})
+}
+
+// DisputeExchangeRequest hands an accepted exchange to the admins.
+func (h *ExchangeHandler) DisputeExchangeRequest(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	var req disputeExchangeReq
+	ok := utils.BindData(c, &req)
+	if !ok {
+		return
+	}
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fetchedExchangeRequest, err := h.exchangeUsecase.GetExchangeRequestByID(pathExchangeRequestID, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	err = h.exchangeUsecase.DisputeExchange(fetchedExchangeRequest, loggedInUserID, req.Reason)
+	if err != nil {
+		log.Printf("Failed To Dispute Exchange Request %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "exchange request disputed, an admin will review it",
+		"status":  fetchedExchangeRequest.Status,
+	})
+}
+
+func (h *ExchangeHandler) GetDisputedExchanges(c *gin.Context) {
+	requests, err := h.exchangeUsecase.GetDisputedExchanges()
+	if err != nil {
+		log.Printf("Failed To Get Disputed Exchanges %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"requests": requests,
+	})
+}
+
+// ResolveExchangeDispute ends a disputed exchange the way the admin
+// decided.
+func (h *ExchangeHandler) ResolveExchangeDispute(c *gin.Context) {
+	adminID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	var req resolveDisputeReq
+	ok := utils.BindData(c, &req)
+	if !ok {
+		return
+	}
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	request, err := h.exchangeUsecase.ResolveDispute(pathExchangeRequestID, adminID, entity.ExchangeStatus(req.Outcome), req.AtFault, req.Note)
+	if err != nil {
+		log.Printf("Failed To Resolve Exchange Dispute %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"request": request,
+	})
+}
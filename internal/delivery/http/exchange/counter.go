@@ -0,0 +1,102 @@
+package exchange
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type counterReq struct {
+	OfferedBookID  *uint  `json:"offered_book_id" binding:"omitempty"`
+	OfferedBookIDs []uint `json:"offered_book_ids" binding:"omitempty,max=10"`
+	Message        string `json:"message" binding:"omitempty,max=500"`
+}
+
+func (h *ExchangeHandler) CounterExchangeRequest(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	var req counterReq
+	ok := utils.BindData(c, &req)
+	if !ok {
+		return
+	}
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fetchedExchangeRequest, err := h.exchangeUsecase.GetExchangeRequestByID(pathExchangeRequestID, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	offeredBooks, err := h.getBooks(bundleIDs(req.OfferedBookID, req.OfferedBookIDs))
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	err = h.exchangeUsecase.CounterExchange(fetchedExchangeRequest, offeredBooks, loggedInUserID, req.Message)
+	if err != nil {
+		log.Printf("Failed To Counter Exchange Request %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "counter-offer sent",
+		"status":  fetchedExchangeRequest.Status,
+	})
+}
+
+func (h *ExchangeHandler) GetExchangeOffers(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fetchedExchangeRequest, err := h.exchangeUsecase.GetExchangeRequestByID(pathExchangeRequestID, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	offers, err := h.exchangeUsecase.GetExchangeOffers(fetchedExchangeRequest, loggedInUserID)
+	if err != nil {
+		log.Printf("Failed To Get Exchange Offers %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": fetchedExchangeRequest.Status,
+		"offers": offers,
+	})
+}
@@ -11,9 +11,13 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// createReq takes a bundle in requested_book_ids and offered_book_ids. The
+// single-book fields are still accepted and go first in their bundle.
 type createReq struct {
-	RequestedBookID uint `gorm:"not null" json:"requested_book_id" binding:"required"`
-	OfferedBookID   uint `gorm:"not null" json:"offered_book_id" binding:"required"`
+	RequestedBookID  *uint  `json:"requested_book_id" binding:"omitempty"`
+	OfferedBookID    *uint  `json:"offered_book_id" binding:"omitempty"`
+	RequestedBookIDs []uint `json:"requested_book_ids" binding:"omitempty,max=10"`
+	OfferedBookIDs   []uint `json:"offered_book_ids" binding:"omitempty,max=10"`
 }
 
 func (h *ExchangeHandler) CreateExchangeRequest(c *gin.Context) {
@@ -35,7 +39,7 @@ func (h *ExchangeHandler) CreateExchangeRequest(c *gin.Context) {
 	}
 	loggedInUserID := authUser.(*jwt.TokenClaims).User.UID
 
-	requestedBook, err := h.bookUsecase.GetBookByID(req.RequestedBookID)
+	requestedBooks, err := h.getBooks(bundleIDs(req.RequestedBookID, req.RequestedBookIDs))
 	if err != nil {
 		log.Printf("Unable to Get Book By id for unknown reason: %v\n", c)
 		c.JSON(response.Status(err), gin.H{
@@ -43,8 +47,16 @@ func (h *ExchangeHandler) CreateExchangeRequest(c *gin.Context) {
 		})
 		return
 	}
+	if len(requestedBooks) == 0 {
+		err := response.NewBadRequestError("requested_book_id or requested_book_ids is required")
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
 
-	offeredBook, err := h.bookUsecase.GetBookByID(req.OfferedBookID)
+	// Without offered books the request is a claim on giveaways.
+	offeredBooks, err := h.getBooks(bundleIDs(req.OfferedBookID, req.OfferedBookIDs))
 	if err != nil {
 		log.Printf("Unable to Get Book By id for unknown reason: %v\n", c)
 		c.JSON(response.Status(err), gin.H{
@@ -52,21 +64,24 @@ func (h *ExchangeHandler) CreateExchangeRequest(c *gin.Context) {
 		})
 		return
 	}
-
-	if offeredBook.UserID != loggedInUserID {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "offering book not found",
-		})
-		return
+	for _, offeredBook := range offeredBooks {
+		if offeredBook.UserID != loggedInUserID {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "offering book not found",
+			})
+			return
+		}
 	}
 
 	newExchangeRequest := entity.ExchangeRequest{
-		RequestedByID:   loggedInUserID,
-		RequestedToID:   requestedBook.Owner.UID,
-		RequestedBookID: req.RequestedBookID,
-		OfferedBookID:   req.OfferedBookID,
-		RequestedBook:   *requestedBook,
-		OfferedBook:     *offeredBook,
+		RequestedByID: loggedInUserID,
+		RequestedToID: requestedBooks[0].Owner.UID,
+	}
+	for _, book := range requestedBooks {
+		newExchangeRequest.Items = append(newExchangeRequest.Items, entity.ExchangeItem{BookID: book.ID, Book: *book, Side: entity.ItemRequested})
+	}
+	for _, book := range offeredBooks {
+		newExchangeRequest.Items = append(newExchangeRequest.Items, entity.ExchangeItem{BookID: book.ID, Book: *book, Side: entity.ItemOffered})
 	}
 
 	sanitized, err := h.exchangeUsecase.RequestExchange(&newExchangeRequest)
@@ -82,3 +97,23 @@ func (h *ExchangeHandler) CreateExchangeRequest(c *gin.Context) {
 		"request": sanitized,
 	})
 }
+
+// bundleIDs merges a single-book field into its bundle, first.
+func bundleIDs(single *uint, many []uint) []uint {
+	if single == nil {
+		return many
+	}
+	return append([]uint{*single}, many...)
+}
+
+func (h *ExchangeHandler) getBooks(ids []uint) ([]*entity.Book, error) {
+	books := make([]*entity.Book, len(ids))
+	for i, id := range ids {
+		book, err := h.bookUsecase.GetBookByID(id)
+		if err != nil {
+			return nil, err
+		}
+		books[i] = book
+	}
+	return books, nil
+}
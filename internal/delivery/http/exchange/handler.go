@@ -9,36 +9,58 @@ import (
 )
 
 type ExchangeHandler struct {
-	bookUsecase     usecase.BookUsecase
-	exchangeUsecase usecase.ExchangeUsecase
-	jwtService      jwt.Service
-	Cfg             *config.Configuration
+	bookUsecase            usecase.BookUsecase
+	exchangeUsecase        usecase.ExchangeUsecase
+	exchangeMessageUsecase usecase.ExchangeMessageUsecase
+	jwtService             jwt.Service
+	Cfg                    *config.Configuration
 }
 
 type Config struct {
-	R               *gin.Engine
-	BookUsecase     usecase.BookUsecase
-	ExchangeUsecase usecase.ExchangeUsecase
-	JwtService      jwt.Service
+	R                      *gin.Engine
+	BookUsecase            usecase.BookUsecase
+	ExchangeUsecase        usecase.ExchangeUsecase
+	ExchangeMessageUsecase usecase.ExchangeMessageUsecase
+	JwtService             jwt.Service
 }
 
 func NewExchangeHandler(c *Config) {
 	h := &ExchangeHandler{
-		bookUsecase:     c.BookUsecase,
-		exchangeUsecase: c.ExchangeUsecase,
-		jwtService:      c.JwtService,
+		bookUsecase:            c.BookUsecase,
+		exchangeUsecase:        c.ExchangeUsecase,
+		exchangeMessageUsecase: c.ExchangeMessageUsecase,
+		jwtService:             c.JwtService,
 	}
 
 	exchangeRoutes := c.R.Group("/api/exchange/requests")
 	{
 		exchangeRoutes.POST("/", middleware.AuthUser(h.jwtService), h.CreateExchangeRequest)
 		exchangeRoutes.GET("/:id", middleware.AuthUser(h.jwtService), h.GetExchangeRequestByID)
+		exchangeRoutes.GET("/:id/history", middleware.AuthUser(h.jwtService), h.GetExchangeHistory)
+		exchangeRoutes.GET("/:id/offers", middleware.AuthUser(h.jwtService), h.GetExchangeOffers)
 		exchangeRoutes.GET("/", middleware.AuthUser(h.jwtService), h.GetUserExchangeRequests)
 		exchangeRoutes.GET("/made", middleware.AuthUser(h.jwtService), h.GetExchangeRequestsMade)
 		exchangeRoutes.GET("/received", middleware.AuthUser(h.jwtService), h.GetExchangeRequestsReceived)
+		exchangeRoutes.GET("/unread-messages", middleware.AuthUser(h.jwtService), h.GetUnreadExchangeMessages)
+		exchangeRoutes.GET("/:id/messages", middleware.AuthUser(h.jwtService), h.GetExchangeMessages)
+		exchangeRoutes.POST("/:id/messages", middleware.AuthUser(h.jwtService), h.SendExchangeMessage)
 		exchangeRoutes.POST("/:id/accept", middleware.AuthUser(h.jwtService), h.AcceptExchangeRequest)
 		exchangeRoutes.POST("/:id/decline", middleware.AuthUser(h.jwtService), h.DeclineExchangeRequest)
+		exchangeRoutes.POST("/:id/counter", middleware.AuthUser(h.jwtService), h.CounterExchangeRequest)
 		exchangeRoutes.POST("/:id/confirm", middleware.AuthUser(h.jwtService), h.ConfirmExchangeRequest)
+		exchangeRoutes.POST("/:id/handoff/code", middleware.AuthUser(h.jwtService), h.IssueHandoffCode)
+		exchangeRoutes.POST("/:id/handoff/verify", middleware.AuthUser(h.jwtService), h.VerifyHandoff)
+		exchangeRoutes.POST("/:id/meetup/agree", middleware.AuthUser(h.jwtService), h.AgreeMeetup)
+		exchangeRoutes.GET("/:id/meetup/calendar", middleware.AuthUser(h.jwtService), h.GetMeetupCalendar)
+		exchangeRoutes.GET("/:id/meetups", middleware.AuthUser(h.jwtService), h.GetExchangeMeetups)
+		exchangeRoutes.POST("/:id/meetups", middleware.AuthUser(h.jwtService), h.ProposeMeetup)
+		exchangeRoutes.POST("/:id/meetups/:meetup_id/accept", middleware.AuthUser(h.jwtService), h.AcceptMeetup)
+		exchangeRoutes.POST("/:id/meetups/:meetup_id/reschedule", middleware.AuthUser(h.jwtService), h.RescheduleMeetup)
+		exchangeRoutes.POST("/:id/cancel", middleware.AuthUser(h.jwtService), h.CancelExchangeRequest)
+		exchangeRoutes.POST("/:id/dispute", middleware.AuthUser(h.jwtService), h.DisputeExchangeRequest)
+		exchangeRoutes.GET("/disputes", middleware.AuthUser(h.jwtService), middleware.RequireAdmin(), h.GetDisputedExchanges)
+		exchangeRoutes.POST("/:id/resolve", middleware.AuthUser(h.jwtService), middleware.RequireAdmin(), h.ResolveExchangeDispute)
+		exchangeRoutes.POST("/:id/withdraw", middleware.AuthUser(h.jwtService), h.WithdrawExchangeRequest)
 		exchangeRoutes.DELETE("/:id/delete", middleware.AuthUser(h.jwtService), h.DeleteExchangeRequest)
 
 	}
@@ -0,0 +1,96 @@
+package exchange
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type handoffVerifyReq struct {
+	Code      string `json:"code" binding:"required_without=QRPayload,omitempty,numeric,len=6"`
+	QRPayload string `json:"qr_payload" binding:"omitempty,max=300"`
+}
+
+// IssueHandoffCode hands out a new code for the logged in user to show the
+// other side when they meet.
+func (h *ExchangeHandler) IssueHandoffCode(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fetchedExchangeRequest, err := h.exchangeUsecase.GetExchangeRequestByID(pathExchangeRequestID, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	code, err := h.exchangeUsecase.IssueHandoffCode(fetchedExchangeRequest, loggedInUserID)
+	if err != nil {
+		log.Printf("Failed To Issue Handoff Code %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, gin.H{
+		"handoff": code,
+	})
+}
+
+// VerifyHandoff confirms receipt with the code the other side showed.
+func (h *ExchangeHandler) VerifyHandoff(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	var req handoffVerifyReq
+	ok := utils.BindData(c, &req)
+	if !ok {
+		return
+	}
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fetchedExchangeRequest, err := h.exchangeUsecase.GetExchangeRequestByID(pathExchangeRequestID, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	err = h.exchangeUsecase.VerifyHandoff(fetchedExchangeRequest, loggedInUserID, req.Code, req.QRPayload)
+	if err != nil {
+		log.Printf("Failed To Verify Handoff %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "handoff verified",
+		"status":  fetchedExchangeRequest.Status,
+	})
+}
@@ -0,0 +1,46 @@
+package exchange
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *ExchangeHandler) GetExchangeHistory(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fetchedExchangeRequest, err := h.exchangeUsecase.GetExchangeRequestByID(pathExchangeRequestID, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	events, err := h.exchangeUsecase.GetExchangeHistory(fetchedExchangeRequest, loggedInUserID)
+	if err != nil {
+		log.Printf("Failed To Get Exchange History %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": fetchedExchangeRequest.Status,
+		"events": events,
+	})
+}
@@ -0,0 +1,272 @@
+package exchange
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *ExchangeHandler) AgreeMeetup(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fetchedExchangeRequest, err := h.exchangeUsecase.GetExchangeRequestByID(pathExchangeRequestID, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	err = h.exchangeUsecase.AgreeMeetup(fetchedExchangeRequest, loggedInUserID)
+	if err != nil {
+		log.Printf("Failed To Agree Meetup %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "meetup agreed",
+		"request": fetchedExchangeRequest,
+	})
+}
+
+type meetupSlotReq struct {
+	StartsAt  time.Time `json:"starts_at" binding:"required"`
+	Place     string    `json:"place" binding:"required,max=200"`
+	Latitude  *float64  `json:"latitude" binding:"omitempty,latitude"`
+	Longitude *float64  `json:"longitude" binding:"omitempty,longitude"`
+	Note      string    `json:"note" binding:"omitempty,max=500"`
+}
+
+func (req *meetupSlotReq) slot() *entity.ExchangeMeetup {
+	return &entity.ExchangeMeetup{
+		StartsAt:  req.StartsAt,
+		Place:     strings.TrimSpace(req.Place),
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Note:      req.Note,
+	}
+}
+
+func (h *ExchangeHandler) ProposeMeetup(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	var req meetupSlotReq
+	ok := utils.BindData(c, &req)
+	if !ok {
+		return
+	}
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fetchedExchangeRequest, err := h.exchangeUsecase.GetExchangeRequestByID(pathExchangeRequestID, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	slot := req.slot()
+	err = h.exchangeUsecase.ProposeMeetup(fetchedExchangeRequest, slot, loggedInUserID)
+	if err != nil {
+		log.Printf("Failed To Propose Meetup %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "meetup proposed",
+		"meetup":  slot,
+	})
+}
+
+func (h *ExchangeHandler) RescheduleMeetup(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	var req meetupSlotReq
+	ok := utils.BindData(c, &req)
+	if !ok {
+		return
+	}
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+	pathMeetupID, err := uuid.Parse(c.Param("meetup_id"))
+	if err != nil {
+		err := response.NewNotFoundError("meetup", c.Param("meetup_id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fetchedExchangeRequest, err := h.exchangeUsecase.GetExchangeRequestByID(pathExchangeRequestID, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	slot := req.slot()
+	err = h.exchangeUsecase.RescheduleMeetup(fetchedExchangeRequest, pathMeetupID, slot, loggedInUserID)
+	if err != nil {
+		log.Printf("Failed To Reschedule Meetup %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "meetup rescheduled",
+		"meetup":  slot,
+	})
+}
+
+func (h *ExchangeHandler) AcceptMeetup(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+	pathMeetupID, err := uuid.Parse(c.Param("meetup_id"))
+	if err != nil {
+		err := response.NewNotFoundError("meetup", c.Param("meetup_id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fetchedExchangeRequest, err := h.exchangeUsecase.GetExchangeRequestByID(pathExchangeRequestID, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	meetup, err := h.exchangeUsecase.AcceptMeetup(fetchedExchangeRequest, pathMeetupID, loggedInUserID)
+	if err != nil {
+		log.Printf("Failed To Accept Meetup %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "meetup accepted",
+		"meetup":  meetup,
+	})
+}
+
+func (h *ExchangeHandler) GetExchangeMeetups(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fetchedExchangeRequest, err := h.exchangeUsecase.GetExchangeRequestByID(pathExchangeRequestID, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	meetups, err := h.exchangeUsecase.GetExchangeMeetups(fetchedExchangeRequest, loggedInUserID)
+	if err != nil {
+		log.Printf("Failed To Get Meetups %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"meetup_agreed_at": fetchedExchangeRequest.MeetupAgreedAt,
+		"meetups":          meetups,
+	})
+}
+
+// GetMeetupCalendar downloads the agreed meetup as an .ics file for
+// calendar apps.
+func (h *ExchangeHandler) GetMeetupCalendar(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fetchedExchangeRequest, err := h.exchangeUsecase.GetExchangeRequestByID(pathExchangeRequestID, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	ics, err := h.exchangeUsecase.GetMeetupCalendar(fetchedExchangeRequest, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="meetup.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", ics)
+}
@@ -0,0 +1,116 @@
+package exchange
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type messageReq struct {
+	Body     string      `json:"body" binding:"omitempty,max=2000"`
+	ImageIDs []uuid.UUID `json:"image_ids" binding:"omitempty,max=4"`
+}
+
+func (h *ExchangeHandler) SendExchangeMessage(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	var req messageReq
+	ok := utils.BindData(c, &req)
+	if !ok {
+		return
+	}
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fetchedExchangeRequest, err := h.exchangeUsecase.GetExchangeRequestByID(pathExchangeRequestID, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	message, err := h.exchangeMessageUsecase.SendMessage(fetchedExchangeRequest, loggedInUserID, strings.TrimSpace(req.Body), req.ImageIDs)
+	if err != nil {
+		log.Printf("Failed To Send Exchange Message %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": message,
+	})
+}
+
+// GetExchangeMessages returns the request's thread and marks the messages
+// in it sent to the logged in user as read.
+func (h *ExchangeHandler) GetExchangeMessages(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	pathExchangeRequestID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("exchange request", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	fetchedExchangeRequest, err := h.exchangeUsecase.GetExchangeRequestByID(pathExchangeRequestID, loggedInUserID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	thread, err := h.exchangeMessageUsecase.GetThread(fetchedExchangeRequest, loggedInUserID)
+	if err != nil {
+		log.Printf("Failed To Get Exchange Messages %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"thread": thread,
+	})
+}
+
+func (h *ExchangeHandler) GetUnreadExchangeMessages(c *gin.Context) {
+	loggedInUserID := c.MustGet("user").(*jwt.TokenClaims).User.UID
+
+	counts, err := h.exchangeMessageUsecase.GetUnreadCounts(loggedInUserID)
+	if err != nil {
+		log.Printf("Failed To Count Unread Exchange Messages %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	var total int64
+	for _, count := range counts {
+		total += count.Unread
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"total":   total,
+		"threads": counts,
+	})
+}
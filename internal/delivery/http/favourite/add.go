@@ -0,0 +1,33 @@
+package favourite
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *FavouriteHandler) AddFavourite(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	bookID, ok := parseBookID(c)
+	if !ok {
+		return
+	}
+
+	favourite, err := h.favouriteUsecase.AddFavourite(authUser.UID, bookID)
+	if err != nil {
+		log.Printf("Failed to Add Favourite: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Favourite Added",
+		"favourite": favourite,
+	})
+}
@@ -0,0 +1,40 @@
+package favourite
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *FavouriteHandler) GetFavourites(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	favourites, err := h.favouriteUsecase.GetFavourites(authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Get Favourites: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"favourites": favourites,
+	})
+}
+
+func parseBookID(c *gin.Context) (uint, bool) {
+	bookID, err := strconv.Atoi(c.Param("book_id"))
+	if err != nil || bookID <= 0 {
+		err := response.NewBadRequestError("book_id should be number")
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return 0, false
+	}
+	return uint(bookID), true
+}
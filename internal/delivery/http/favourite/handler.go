@@ -0,0 +1,33 @@
+package favourite
+
+import (
+	"github.com/arjnep/gyanpass/internal/delivery/middleware"
+	"github.com/arjnep/gyanpass/internal/usecase"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+type FavouriteHandler struct {
+	favouriteUsecase usecase.FavouriteUsecase
+	jwtService       jwt.Service
+}
+
+type Config struct {
+	R                *gin.Engine
+	FavouriteUsecase usecase.FavouriteUsecase
+	JwtService       jwt.Service
+}
+
+func NewFavouriteHandler(c *Config) {
+	h := &FavouriteHandler{
+		favouriteUsecase: c.FavouriteUsecase,
+		jwtService:       c.JwtService,
+	}
+
+	favouriteRoutes := c.R.Group("/api/favourites")
+	{
+		favouriteRoutes.GET("/", middleware.AuthUser(h.jwtService), h.GetFavourites)
+		favouriteRoutes.POST("/:book_id", middleware.AuthUser(h.jwtService), h.AddFavourite)
+		favouriteRoutes.DELETE("/:book_id", middleware.AuthUser(h.jwtService), h.RemoveFavourite)
+	}
+}
@@ -0,0 +1,32 @@
+package favourite
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *FavouriteHandler) RemoveFavourite(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	bookID, ok := parseBookID(c)
+	if !ok {
+		return
+	}
+
+	err := h.favouriteUsecase.RemoveFavourite(authUser.UID, bookID)
+	if err != nil {
+		log.Printf("Failed to Remove Favourite: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Favourite Removed",
+	})
+}
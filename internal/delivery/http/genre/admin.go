@@ -0,0 +1,106 @@
+package genre
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type createGenreReq struct {
+	Name     string   `json:"name" binding:"required,max=100"`
+	ParentID *uint    `json:"parent_id" binding:"omitempty"`
+	Aliases  []string `json:"aliases" binding:"omitempty,max=20"`
+}
+
+type addAliasReq struct {
+	Alias string `json:"alias" binding:"required,max=100"`
+}
+
+type mergeReq struct {
+	SourceID uint `json:"source_id" binding:"required"`
+}
+
+func (h *GenreHandler) CreateGenre(c *gin.Context) {
+	var req createGenreReq
+	if ok := utils.BindData(c, &req); !ok {
+		return
+	}
+
+	genre := &entity.Genre{
+		Name:     req.Name,
+		ParentID: req.ParentID,
+	}
+
+	err := h.genreUsecase.CreateGenre(genre, req.Aliases)
+	if err != nil {
+		log.Printf("Failed to Create Genre: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"genre": genre,
+	})
+}
+
+func (h *GenreHandler) AddGenreAlias(c *gin.Context) {
+	genre, ok := h.fetchGenre(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req addAliasReq
+	if ok := utils.BindData(c, &req); !ok {
+		return
+	}
+
+	err := h.genreUsecase.AddAlias(genre, req.Alias)
+	if err != nil {
+		log.Printf("Failed to Add Genre Alias: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"genre": genre,
+	})
+}
+
+func (h *GenreHandler) MergeGenres(c *gin.Context) {
+	target, ok := h.fetchGenre(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req mergeReq
+	if ok := utils.BindData(c, &req); !ok {
+		return
+	}
+
+	source, ok := h.fetchGenre(c, fmt.Sprintf("%d", req.SourceID))
+	if !ok {
+		return
+	}
+
+	err := h.genreUsecase.MergeGenres(target, source)
+	if err != nil {
+		log.Printf("Failed to Merge Genres: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"genre": target,
+	})
+}
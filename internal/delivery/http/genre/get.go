@@ -0,0 +1,57 @@
+package genre
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *GenreHandler) GetGenres(c *gin.Context) {
+	genres, err := h.genreUsecase.GetGenres()
+	if err != nil {
+		log.Printf("Failed to Get Genres: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"genres": genres,
+	})
+}
+
+func (h *GenreHandler) GetGenre(c *gin.Context) {
+	genre, ok := h.fetchGenre(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"genre": genre,
+	})
+}
+
+func (h *GenreHandler) fetchGenre(c *gin.Context, param string) (*entity.Genre, bool) {
+	genreID, err := strconv.Atoi(param)
+	if err != nil {
+		err := response.NewBadRequestError("id of genre should be number")
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+
+	genre, err := h.genreUsecase.GetGenreByID(uint(genreID))
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+	return genre, true
+}
@@ -0,0 +1,35 @@
+package genre
+
+import (
+	"github.com/arjnep/gyanpass/internal/delivery/middleware"
+	"github.com/arjnep/gyanpass/internal/usecase"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+type GenreHandler struct {
+	genreUsecase usecase.GenreUsecase
+	jwtService   jwt.Service
+}
+
+type Config struct {
+	R            *gin.Engine
+	GenreUsecase usecase.GenreUsecase
+	JwtService   jwt.Service
+}
+
+func NewGenreHandler(c *Config) {
+	h := &GenreHandler{
+		genreUsecase: c.GenreUsecase,
+		jwtService:   c.JwtService,
+	}
+
+	genreRoutes := c.R.Group("/api/genres")
+	{
+		genreRoutes.GET("/", h.GetGenres)
+		genreRoutes.GET("/:id", h.GetGenre)
+		genreRoutes.POST("/", middleware.AuthUser(h.jwtService), middleware.RequireAdmin(), h.CreateGenre)
+		genreRoutes.POST("/:id/aliases", middleware.AuthUser(h.jwtService), middleware.RequireAdmin(), h.AddGenreAlias)
+		genreRoutes.POST("/:id/merge", middleware.AuthUser(h.jwtService), middleware.RequireAdmin(), h.MergeGenres)
+	}
+}
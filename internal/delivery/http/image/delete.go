@@ -0,0 +1,32 @@
+package image
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *ImageHandler) DeleteImage(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	image, ok := h.fetchImage(c)
+	if !ok {
+		return
+	}
+
+	err := h.imageUsecase.DeleteImage(image, authUser.UID)
+	if err != nil {
+		log.Println("Failed Deleting Image:", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Image Deleted",
+	})
+}
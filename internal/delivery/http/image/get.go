@@ -0,0 +1,83 @@
+package image
+
+import (
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *ImageHandler) GetImage(c *gin.Context) {
+	image, ok := h.fetchImage(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"image": image,
+	})
+}
+
+func (h *ImageHandler) GetImageFile(c *gin.Context) {
+	image, ok := h.fetchImage(c)
+	if !ok {
+		return
+	}
+	h.serveImage(c, image, false)
+}
+
+func (h *ImageHandler) GetImageThumbnail(c *gin.Context) {
+	image, ok := h.fetchImage(c)
+	if !ok {
+		return
+	}
+	h.serveImage(c, image, true)
+}
+
+func (h *ImageHandler) fetchImage(c *gin.Context) (*entity.Image, bool) {
+	pathImageID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("image", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+
+	image, err := h.imageUsecase.GetImageByID(pathImageID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+	return image, true
+}
+
+func (h *ImageHandler) serveImage(c *gin.Context, image *entity.Image, thumbnail bool) {
+	reader, err := h.imageUsecase.OpenImage(image, thumbnail)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+	defer reader.Close()
+
+	contentType := image.ContentType
+	if thumbnail {
+		contentType = "image/jpeg"
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, reader); err != nil {
+		log.Println("Failed Writing Image:", err)
+	}
+}
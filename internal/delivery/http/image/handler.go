@@ -0,0 +1,43 @@
+package image
+
+import (
+	"github.com/arjnep/gyanpass/internal/delivery/middleware"
+	"github.com/arjnep/gyanpass/internal/usecase"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultMaxUploadSize = 5 << 20
+
+type ImageHandler struct {
+	imageUsecase  usecase.ImageUsecase
+	jwtService    jwt.Service
+	maxUploadSize int64
+}
+
+type Config struct {
+	R             *gin.Engine
+	ImageUsecase  usecase.ImageUsecase
+	JwtService    jwt.Service
+	MaxUploadSize int64
+}
+
+func NewImageHandler(c *Config) {
+	h := &ImageHandler{
+		imageUsecase:  c.ImageUsecase,
+		jwtService:    c.JwtService,
+		maxUploadSize: c.MaxUploadSize,
+	}
+	if h.maxUploadSize <= 0 {
+		h.maxUploadSize = defaultMaxUploadSize
+	}
+
+	imageRoutes := c.R.Group("/api/images")
+	{
+		imageRoutes.POST("/", middleware.AuthUser(h.jwtService), h.UploadImage)
+		imageRoutes.GET("/:id", h.GetImage)
+		imageRoutes.GET("/:id/file", h.GetImageFile)
+		imageRoutes.GET("/:id/thumbnail", h.GetImageThumbnail)
+		imageRoutes.DELETE("/:id", middleware.AuthUser(h.jwtService), h.DeleteImage)
+	}
+}
@@ -0,0 +1,94 @@
+package image
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *ImageHandler) UploadImage(c *gin.Context) {
+	authUser, exists := c.Get("user")
+	if !exists {
+		log.Printf("Unable to extract user from request context for unknown reason: %v\n", c)
+		err := response.NewInternalServerError()
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if c.ContentType() != "multipart/form-data" {
+		err := response.NewUnsupportedMediaTypeError(c.FullPath() + " only accepts Content-Type multipart/form-data")
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	// allow some room for the multipart envelope around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+(64<<10))
+
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			err := response.NewPayloadTooLargeError(h.maxUploadSize, c.Request.ContentLength)
+			c.JSON(err.Status(), gin.H{
+				"error": err,
+			})
+			return
+		}
+		err := response.NewBadRequestError("image file is required")
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if fileHeader.Size > h.maxUploadSize {
+		err := response.NewPayloadTooLargeError(h.maxUploadSize, fileHeader.Size)
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Println("Failed Opening Uploaded Image:", err)
+		err := response.NewInternalServerError()
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.maxUploadSize))
+	if err != nil {
+		log.Println("Failed Reading Uploaded Image:", err)
+		err := response.NewInternalServerError()
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	image, err := h.imageUsecase.UploadImage(authUser.(*jwt.TokenClaims).User.UID, data)
+	if err != nil {
+		log.Printf("Failed to upload image: %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"image": image,
+	})
+}
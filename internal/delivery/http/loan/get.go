@@ -0,0 +1,62 @@
+package loan
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *LoanHandler) GetUserLoans(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	loans, err := h.loanUsecase.GetLoansByUserID(authUser.UID)
+	if err != nil {
+		log.Printf("Failed To Get Loans: %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"loans": loans,
+	})
+}
+
+func (h *LoanHandler) GetLoan(c *gin.Context) {
+	loan, ok := h.fetchLoan(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"loan": loan,
+	})
+}
+
+func (h *LoanHandler) fetchLoan(c *gin.Context) (*entity.Loan, bool) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	loanID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("loan", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+
+	loan, err := h.loanUsecase.GetLoanByID(loanID, authUser.UID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+	return loan, true
+}
@@ -0,0 +1,41 @@
+package loan
+
+import (
+	"github.com/arjnep/gyanpass/internal/delivery/middleware"
+	"github.com/arjnep/gyanpass/internal/usecase"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+type LoanHandler struct {
+	bookUsecase usecase.BookUsecase
+	loanUsecase usecase.LoanUsecase
+	jwtService  jwt.Service
+}
+
+type Config struct {
+	R           *gin.Engine
+	BookUsecase usecase.BookUsecase
+	LoanUsecase usecase.LoanUsecase
+	JwtService  jwt.Service
+}
+
+func NewLoanHandler(c *Config) {
+	h := &LoanHandler{
+		bookUsecase: c.BookUsecase,
+		loanUsecase: c.LoanUsecase,
+		jwtService:  c.JwtService,
+	}
+
+	loanRoutes := c.R.Group("/api/loans")
+	{
+		loanRoutes.POST("/", middleware.AuthUser(h.jwtService), h.RequestLoan)
+		loanRoutes.GET("/", middleware.AuthUser(h.jwtService), h.GetUserLoans)
+		loanRoutes.GET("/:id", middleware.AuthUser(h.jwtService), h.GetLoan)
+		loanRoutes.POST("/:id/approve", middleware.AuthUser(h.jwtService), h.ApproveLoan)
+		loanRoutes.POST("/:id/decline", middleware.AuthUser(h.jwtService), h.DeclineLoan)
+		loanRoutes.POST("/:id/cancel", middleware.AuthUser(h.jwtService), h.CancelLoan)
+		loanRoutes.POST("/:id/handover", middleware.AuthUser(h.jwtService), h.HandOverLoan)
+		loanRoutes.POST("/:id/return", middleware.AuthUser(h.jwtService), h.ConfirmReturn)
+	}
+}
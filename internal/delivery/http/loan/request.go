@@ -0,0 +1,56 @@
+package loan
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type requestLoanReq struct {
+	BookID       uint   `json:"book_id" binding:"required"`
+	DurationDays int    `json:"duration_days" binding:"required,min=1,max=180"`
+	Message      string `json:"message" binding:"omitempty,max=500"`
+}
+
+func (h *LoanHandler) RequestLoan(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	var req requestLoanReq
+	if ok := utils.BindData(c, &req); !ok {
+		return
+	}
+
+	book, err := h.bookUsecase.GetBookByID(req.BookID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	loan := &entity.Loan{
+		BookID:       book.ID,
+		Book:         *book,
+		BorrowerID:   authUser.UID,
+		DurationDays: req.DurationDays,
+		Message:      req.Message,
+	}
+
+	err = h.loanUsecase.RequestLoan(loan)
+	if err != nil {
+		log.Printf("Failed To Request Loan: %v\n", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"loan": loan,
+	})
+}
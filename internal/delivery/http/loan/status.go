@@ -0,0 +1,55 @@
+package loan
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *LoanHandler) ApproveLoan(c *gin.Context) {
+	h.changeStatus(c, h.loanUsecase.ApproveLoan, "loan approved")
+}
+
+func (h *LoanHandler) DeclineLoan(c *gin.Context) {
+	h.changeStatus(c, h.loanUsecase.DeclineLoan, "loan declined")
+}
+
+func (h *LoanHandler) CancelLoan(c *gin.Context) {
+	h.changeStatus(c, h.loanUsecase.CancelLoan, "loan cancelled")
+}
+
+func (h *LoanHandler) HandOverLoan(c *gin.Context) {
+	h.changeStatus(c, h.loanUsecase.HandOverLoan, "loan handed over")
+}
+
+func (h *LoanHandler) ConfirmReturn(c *gin.Context) {
+	h.changeStatus(c, h.loanUsecase.ConfirmReturn, "return confirmed")
+}
+
+func (h *LoanHandler) changeStatus(c *gin.Context, action func(*entity.Loan, uuid.UUID) error, message string) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	loan, ok := h.fetchLoan(c)
+	if !ok {
+		return
+	}
+
+	err := action(loan, authUser.UID)
+	if err != nil {
+		log.Printf("Failed To Update Loan %v: %v\n", loan.ID, err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"loan":    loan,
+	})
+}
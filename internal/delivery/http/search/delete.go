@@ -0,0 +1,32 @@
+package search
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *SearchHandler) DeleteSavedSearch(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	search, ok := h.fetchSearch(c)
+	if !ok {
+		return
+	}
+
+	err := h.savedSearchUsecase.DeleteSearch(search, authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Delete Saved Search: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Saved Search Deleted",
+	})
+}
@@ -0,0 +1,69 @@
+package search
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *SearchHandler) GetSavedSearches(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	searches, err := h.savedSearchUsecase.GetSearchesByUserID(authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Get Saved Searches: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"searches": searches,
+	})
+}
+
+func (h *SearchHandler) GetSavedSearch(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	search, ok := h.fetchSearch(c)
+	if !ok {
+		return
+	}
+	if search.UserID != authUser.UID {
+		err := response.NewNotFoundError("saved search", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"search": search,
+	})
+}
+
+func (h *SearchHandler) fetchSearch(c *gin.Context) (*entity.SavedSearch, bool) {
+	searchID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("saved search", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+
+	search, err := h.savedSearchUsecase.GetSearchByID(searchID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+	return search, true
+}
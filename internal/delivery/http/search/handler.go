@@ -0,0 +1,34 @@
+package search
+
+import (
+	"github.com/arjnep/gyanpass/internal/delivery/middleware"
+	"github.com/arjnep/gyanpass/internal/usecase"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	savedSearchUsecase usecase.SavedSearchUsecase
+	jwtService         jwt.Service
+}
+
+type Config struct {
+	R                  *gin.Engine
+	SavedSearchUsecase usecase.SavedSearchUsecase
+	JwtService         jwt.Service
+}
+
+func NewSearchHandler(c *Config) {
+	h := &SearchHandler{
+		savedSearchUsecase: c.SavedSearchUsecase,
+		jwtService:         c.JwtService,
+	}
+
+	searchRoutes := c.R.Group("/api/searches")
+	{
+		searchRoutes.GET("/", middleware.AuthUser(h.jwtService), h.GetSavedSearches)
+		searchRoutes.POST("/", middleware.AuthUser(h.jwtService), h.SaveSearch)
+		searchRoutes.GET("/:id", middleware.AuthUser(h.jwtService), h.GetSavedSearch)
+		searchRoutes.DELETE("/:id", middleware.AuthUser(h.jwtService), h.DeleteSavedSearch)
+	}
+}
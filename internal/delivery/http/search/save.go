@@ -0,0 +1,82 @@
+package search
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/isbn"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/taxonomy"
+	"github.com/arjnep/gyanpass/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type saveSearchReq struct {
+	Name        string   `json:"name" binding:"required,max=100"`
+	Title       string   `json:"title" binding:"omitempty"`
+	Address     string   `json:"address" binding:"omitempty"`
+	ISBN        string   `json:"isbn" binding:"omitempty"`
+	ListingType string   `json:"listing_type" binding:"omitempty,oneof=swap giveaway"`
+	Genre       string   `json:"genre" binding:"omitempty"`
+	Tag         string   `json:"tag" binding:"omitempty"`
+	Latitude    *float64 `json:"latitude" binding:"required_with=Longitude,omitempty,latitude"`
+	Longitude   *float64 `json:"longitude" binding:"required_with=Latitude,omitempty,longitude"`
+	RadiusKm    float64  `json:"radius_km" binding:"omitempty,gt=0,lte=100"`
+	Frequency   string   `json:"frequency" binding:"required,oneof=daily weekly"`
+	Email       bool     `json:"email"`
+}
+
+func (h *SearchHandler) SaveSearch(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	var req saveSearchReq
+	if ok := utils.BindData(c, &req); !ok {
+		return
+	}
+
+	if req.ISBN != "" {
+		normalized, err := isbn.Normalize(req.ISBN)
+		if err != nil {
+			err := response.NewBadRequestError("isbn is not a valid ISBN-10 or ISBN-13")
+			c.JSON(err.Status(), gin.H{
+				"error": err,
+			})
+			return
+		}
+		req.ISBN = normalized
+	}
+
+	search := &entity.SavedSearch{
+		UserID: authUser.UID,
+		Name:   req.Name,
+		Filters: map[string]string{
+			"title":        req.Title,
+			"address":      req.Address,
+			"isbn":         req.ISBN,
+			"listing_type": req.ListingType,
+			"genre":        taxonomy.Normalize(req.Genre),
+			"tag":          taxonomy.Normalize(req.Tag),
+		},
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		RadiusKm:  req.RadiusKm,
+		Frequency: req.Frequency,
+		Email:     req.Email,
+	}
+
+	err := h.savedSearchUsecase.CreateSearch(search)
+	if err != nil {
+		log.Printf("Failed to Save Search: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Search Saved",
+		"search":  search,
+	})
+}
@@ -0,0 +1,45 @@
+package tag
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type mergeReq struct {
+	SourceID uint `json:"source_id" binding:"required"`
+}
+
+func (h *TagHandler) MergeTags(c *gin.Context) {
+	target, ok := h.fetchTag(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req mergeReq
+	if ok := utils.BindData(c, &req); !ok {
+		return
+	}
+
+	source, ok := h.fetchTag(c, fmt.Sprintf("%d", req.SourceID))
+	if !ok {
+		return
+	}
+
+	err := h.tagUsecase.MergeTags(target, source)
+	if err != nil {
+		log.Printf("Failed to Merge Tags: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tag": target,
+	})
+}
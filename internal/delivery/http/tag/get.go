@@ -0,0 +1,46 @@
+package tag
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *TagHandler) SearchTags(c *gin.Context) {
+	tags, err := h.tagUsecase.SearchTags(c.Query("q"))
+	if err != nil {
+		log.Printf("Failed to Search Tags: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tags": tags,
+	})
+}
+
+func (h *TagHandler) fetchTag(c *gin.Context, param string) (*entity.Tag, bool) {
+	tagID, err := strconv.Atoi(param)
+	if err != nil {
+		err := response.NewBadRequestError("id of tag should be number")
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+
+	tag, err := h.tagUsecase.GetTagByID(uint(tagID))
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+	return tag, true
+}
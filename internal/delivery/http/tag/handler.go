@@ -0,0 +1,32 @@
+package tag
+
+import (
+	"github.com/arjnep/gyanpass/internal/delivery/middleware"
+	"github.com/arjnep/gyanpass/internal/usecase"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	tagUsecase usecase.TagUsecase
+	jwtService jwt.Service
+}
+
+type Config struct {
+	R          *gin.Engine
+	TagUsecase usecase.TagUsecase
+	JwtService jwt.Service
+}
+
+func NewTagHandler(c *Config) {
+	h := &TagHandler{
+		tagUsecase: c.TagUsecase,
+		jwtService: c.JwtService,
+	}
+
+	tagRoutes := c.R.Group("/api/tags")
+	{
+		tagRoutes.GET("/", h.SearchTags)
+		tagRoutes.POST("/:id/merge", middleware.AuthUser(h.jwtService), middleware.RequireAdmin(), h.MergeTags)
+	}
+}
@@ -0,0 +1,32 @@
+package wanted
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *WantedHandler) CloseWantedPost(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	post, ok := h.fetchPost(c)
+	if !ok {
+		return
+	}
+
+	err := h.wantedUsecase.ClosePost(post, authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Close Wanted Post: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Wanted Post Closed",
+	})
+}
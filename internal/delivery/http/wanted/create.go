@@ -0,0 +1,53 @@
+package wanted
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type createWantedPostReq struct {
+	Title     string   `json:"title" binding:"required,max=200"`
+	Author    string   `json:"author" binding:"omitempty,max=200"`
+	Note      string   `json:"note" binding:"omitempty,max=500"`
+	Address   string   `json:"address" binding:"omitempty"`
+	Latitude  *float64 `json:"latitude" binding:"required_with=Longitude,omitempty,latitude"`
+	Longitude *float64 `json:"longitude" binding:"required_with=Latitude,omitempty,longitude"`
+}
+
+func (h *WantedHandler) CreateWantedPost(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	var req createWantedPostReq
+	if ok := utils.BindData(c, &req); !ok {
+		return
+	}
+
+	post := &entity.WantedPost{
+		UserID:    authUser.UID,
+		Title:     req.Title,
+		Author:    req.Author,
+		Note:      req.Note,
+		Address:   req.Address,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+	}
+
+	err := h.wantedUsecase.CreatePost(post)
+	if err != nil {
+		log.Printf("Failed to Create Wanted Post: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"post": post,
+	})
+}
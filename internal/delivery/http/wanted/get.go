@@ -0,0 +1,189 @@
+package wanted
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *WantedHandler) SearchWantedPosts(c *gin.Context) {
+	queryParams := map[string]string{
+		"title":   c.Query("title"),
+		"author":  c.Query("author"),
+		"address": c.Query("address"),
+	}
+
+	var location *entity.Location
+	if c.Query("latitude") != "" || c.Query("longitude") != "" {
+		latitude, latErr := strconv.ParseFloat(c.Query("latitude"), 64)
+		longitude, lngErr := strconv.ParseFloat(c.Query("longitude"), 64)
+		if latErr != nil || lngErr != nil || latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+			err := response.NewBadRequestError("latitude and longitude must be valid coordinates")
+			c.JSON(err.Status(), gin.H{
+				"error": err,
+			})
+			return
+		}
+		location = &entity.Location{Latitude: latitude, Longitude: longitude}
+	}
+
+	radius := 0.0
+	if c.Query("radius") != "" {
+		var err error
+		radius, err = strconv.ParseFloat(c.Query("radius"), 64)
+		if err != nil || radius <= 0 || radius > 100 || location == nil {
+			err := response.NewBadRequestError("radius must be between 0 and 100 km and needs latitude and longitude")
+			c.JSON(err.Status(), gin.H{
+				"error": err,
+			})
+			return
+		}
+	}
+
+	page, _ := c.Get("page")
+	size, _ := c.Get("size")
+
+	pageInt, ok := page.(int)
+	if !ok {
+		pageInt = 1
+	}
+	sizeInt, ok := size.(int)
+	if !ok {
+		sizeInt = 10
+	}
+
+	posts, total, err := h.wantedUsecase.SearchPosts(queryParams, location, radius, pageInt, sizeInt)
+	if err != nil {
+		log.Printf("Failed to Search Wanted Posts: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	totalPages := (total + sizeInt - 1) / sizeInt
+
+	c.JSON(http.StatusOK, gin.H{
+		"posts":       posts,
+		"page":        pageInt,
+		"size":        sizeInt,
+		"total":       total,
+		"total_pages": totalPages,
+	})
+}
+
+func (h *WantedHandler) GetUserWantedPosts(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	posts, err := h.wantedUsecase.GetPostsByUserID(authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Get Wanted Posts: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posts": posts,
+	})
+}
+
+// GetWantedPost also lists the poster's available books so an owner
+// answering with "I have this" can pick one to ask for in return.
+func (h *WantedHandler) GetWantedPost(c *gin.Context) {
+	post, ok := h.fetchPost(c)
+	if !ok {
+		return
+	}
+
+	books, err := h.bookUsecase.GetBooksByUserID(post.UserID)
+	if err != nil {
+		log.Printf("Failed to Get Poster Books: %v", err)
+		err := response.NewInternalServerError()
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	var booksResponse []gin.H
+	for _, book := range books {
+		if !book.IsActive {
+			continue
+		}
+		booksResponse = append(booksResponse, gin.H{
+			"id":           book.ID,
+			"title":        book.Title,
+			"author":       book.Author,
+			"image_url":    book.ImageUrl,
+			"listing_type": book.ListingType,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"post":         post,
+		"poster_name":  post.User.FirstName,
+		"poster_books": booksResponse,
+	})
+}
+
+func (h *WantedHandler) GetMatchingWantedPosts(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	bookID, err := strconv.Atoi(c.Param("book_id"))
+	if err != nil {
+		err := response.NewBadRequestError("id of book should be number")
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	book, err := h.bookUsecase.GetBookByID(uint(bookID))
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	posts, err := h.wantedUsecase.GetMatchingPosts(book, authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Get Matching Wanted Posts: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posts": posts,
+	})
+}
+
+func (h *WantedHandler) fetchPost(c *gin.Context) (*entity.WantedPost, bool) {
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("wanted post", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+
+	post, err := h.wantedUsecase.GetPostByID(postID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+	return post, true
+}
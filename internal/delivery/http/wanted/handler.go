@@ -0,0 +1,40 @@
+package wanted
+
+import (
+	"github.com/arjnep/gyanpass/internal/delivery/middleware"
+	"github.com/arjnep/gyanpass/internal/usecase"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+type WantedHandler struct {
+	bookUsecase   usecase.BookUsecase
+	wantedUsecase usecase.WantedUsecase
+	jwtService    jwt.Service
+}
+
+type Config struct {
+	R             *gin.Engine
+	BookUsecase   usecase.BookUsecase
+	WantedUsecase usecase.WantedUsecase
+	JwtService    jwt.Service
+}
+
+func NewWantedHandler(c *Config) {
+	h := &WantedHandler{
+		bookUsecase:   c.BookUsecase,
+		wantedUsecase: c.WantedUsecase,
+		jwtService:    c.JwtService,
+	}
+
+	wantedRoutes := c.R.Group("/api/wanted")
+	{
+		wantedRoutes.GET("/", middleware.Pagination(), h.SearchWantedPosts)
+		wantedRoutes.POST("/", middleware.AuthUser(h.jwtService), h.CreateWantedPost)
+		wantedRoutes.GET("/mine", middleware.AuthUser(h.jwtService), h.GetUserWantedPosts)
+		wantedRoutes.GET("/matching/:book_id", middleware.AuthUser(h.jwtService), h.GetMatchingWantedPosts)
+		wantedRoutes.GET("/:id", middleware.AuthUser(h.jwtService), h.GetWantedPost)
+		wantedRoutes.POST("/:id/close", middleware.AuthUser(h.jwtService), h.CloseWantedPost)
+		wantedRoutes.POST("/:id/have", middleware.AuthUser(h.jwtService), h.HaveThis)
+	}
+}
@@ -0,0 +1,66 @@
+package wanted
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type haveThisReq struct {
+	BookID          uint `json:"book_id" binding:"required"`
+	RequestedBookID uint `json:"requested_book_id"`
+}
+
+// HaveThis is the "I have this" action: the caller offers their copy of
+// the wanted book in exchange for one of the poster's books, or gives it
+// away when requested_book_id is left out.
+func (h *WantedHandler) HaveThis(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	post, ok := h.fetchPost(c)
+	if !ok {
+		return
+	}
+
+	var req haveThisReq
+	if ok := utils.BindData(c, &req); !ok {
+		return
+	}
+
+	book, err := h.bookUsecase.GetBookByID(req.BookID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	var requestedBook *entity.Book
+	if req.RequestedBookID != 0 {
+		requestedBook, err = h.bookUsecase.GetBookByID(req.RequestedBookID)
+		if err != nil {
+			c.JSON(response.Status(err), gin.H{
+				"error": err,
+			})
+			return
+		}
+	}
+
+	request, err := h.wantedUsecase.OfferBook(post, book, requestedBook, authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Offer Book For Wanted Post: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"request": request,
+	})
+}
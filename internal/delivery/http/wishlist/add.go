@@ -0,0 +1,54 @@
+package wishlist
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type addWishlistItemReq struct {
+	Title     string   `json:"title" binding:"omitempty,max=200"`
+	Author    string   `json:"author" binding:"omitempty,max=200"`
+	ISBN      string   `json:"isbn" binding:"omitempty"`
+	Latitude  *float64 `json:"latitude" binding:"required_with=Longitude,omitempty,latitude"`
+	Longitude *float64 `json:"longitude" binding:"required_with=Latitude,omitempty,longitude"`
+	RadiusKm  float64  `json:"radius_km" binding:"omitempty,gt=0,lte=100"`
+}
+
+func (h *WishlistHandler) AddWishlistItem(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	var req addWishlistItemReq
+	if ok := utils.BindData(c, &req); !ok {
+		return
+	}
+
+	item := &entity.WishlistItem{
+		UserID:    authUser.UID,
+		Title:     req.Title,
+		Author:    req.Author,
+		ISBN:      req.ISBN,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		RadiusKm:  req.RadiusKm,
+	}
+
+	err := h.wishlistUsecase.AddItem(item)
+	if err != nil {
+		log.Printf("Failed to Add Wishlist Item: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Wishlist Item Added",
+		"item":    item,
+	})
+}
@@ -0,0 +1,72 @@
+package wishlist
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *WishlistHandler) GetWishlist(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	items, err := h.wishlistUsecase.GetItemsByUserID(authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Get Wishlist: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": items,
+	})
+}
+
+func (h *WishlistHandler) GetWishlistMatches(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	item, ok := h.fetchItem(c)
+	if !ok {
+		return
+	}
+
+	books, err := h.wishlistUsecase.GetMatches(item, authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Get Wishlist Matches: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"item":  item,
+		"books": books,
+	})
+}
+
+func (h *WishlistHandler) fetchItem(c *gin.Context) (*entity.WishlistItem, bool) {
+	itemID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		err := response.NewNotFoundError("wishlist item", c.Param("id"))
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+
+	item, err := h.wishlistUsecase.GetItemByID(itemID)
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+	return item, true
+}
@@ -0,0 +1,34 @@
+package wishlist
+
+import (
+	"github.com/arjnep/gyanpass/internal/delivery/middleware"
+	"github.com/arjnep/gyanpass/internal/usecase"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+type WishlistHandler struct {
+	wishlistUsecase usecase.WishlistUsecase
+	jwtService      jwt.Service
+}
+
+type Config struct {
+	R               *gin.Engine
+	WishlistUsecase usecase.WishlistUsecase
+	JwtService      jwt.Service
+}
+
+func NewWishlistHandler(c *Config) {
+	h := &WishlistHandler{
+		wishlistUsecase: c.WishlistUsecase,
+		jwtService:      c.JwtService,
+	}
+
+	wishlistRoutes := c.R.Group("/api/wishlist")
+	{
+		wishlistRoutes.GET("/", middleware.AuthUser(h.jwtService), h.GetWishlist)
+		wishlistRoutes.POST("/", middleware.AuthUser(h.jwtService), h.AddWishlistItem)
+		wishlistRoutes.GET("/:id/matches", middleware.AuthUser(h.jwtService), h.GetWishlistMatches)
+		wishlistRoutes.DELETE("/:id", middleware.AuthUser(h.jwtService), h.RemoveWishlistItem)
+	}
+}
@@ -0,0 +1,32 @@
+package wishlist
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *WishlistHandler) RemoveWishlistItem(c *gin.Context) {
+	authUser := c.MustGet("user").(*jwt.TokenClaims).User
+
+	item, ok := h.fetchItem(c)
+	if !ok {
+		return
+	}
+
+	err := h.wishlistUsecase.RemoveItem(item, authUser.UID)
+	if err != nil {
+		log.Printf("Failed to Remove Wishlist Item: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Wishlist Item Removed",
+	})
+}
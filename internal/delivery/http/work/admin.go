@@ -0,0 +1,61 @@
+package work
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type mergeReq struct {
+	SourceID uint `json:"source_id" binding:"required"`
+}
+
+func (h *WorkHandler) RebuildWorks(c *gin.Context) {
+	linked, err := h.workUsecase.RebuildWorks()
+	if err != nil {
+		log.Printf("Failed to Rebuild Works: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error":  err,
+			"linked": linked,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"linked": linked,
+	})
+}
+
+func (h *WorkHandler) MergeWorks(c *gin.Context) {
+	target, ok := h.fetchWork(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req mergeReq
+	if ok := utils.BindData(c, &req); !ok {
+		return
+	}
+
+	source, ok := h.fetchWork(c, fmt.Sprintf("%d", req.SourceID))
+	if !ok {
+		return
+	}
+
+	err := h.workUsecase.MergeWorks(target, source)
+	if err != nil {
+		log.Printf("Failed to Merge Works: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"work": target,
+	})
+}
@@ -0,0 +1,112 @@
+package work
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *WorkHandler) SearchWorks(c *gin.Context) {
+	page, _ := c.Get("page")
+	size, _ := c.Get("size")
+
+	pageInt, ok := page.(int)
+	if !ok {
+		pageInt = 1
+	}
+	sizeInt, ok := size.(int)
+	if !ok {
+		sizeInt = 10
+	}
+
+	works, total, err := h.workUsecase.SearchWorks(c.Query("title"), pageInt, sizeInt)
+	if err != nil {
+		log.Printf("Failed to Search Works: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	totalPages := (total + sizeInt - 1) / sizeInt
+
+	c.JSON(http.StatusOK, gin.H{
+		"works":       works,
+		"page":        pageInt,
+		"size":        sizeInt,
+		"total":       total,
+		"total_pages": totalPages,
+	})
+}
+
+func (h *WorkHandler) GetWork(c *gin.Context) {
+	work, ok := h.fetchWork(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var location *entity.Location
+	if c.Query("latitude") != "" || c.Query("longitude") != "" {
+		latitude, latErr := strconv.ParseFloat(c.Query("latitude"), 64)
+		longitude, lngErr := strconv.ParseFloat(c.Query("longitude"), 64)
+		if latErr != nil || lngErr != nil || latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+			err := response.NewBadRequestError("latitude and longitude must be valid coordinates")
+			c.JSON(err.Status(), gin.H{
+				"error": err,
+			})
+			return
+		}
+		location = &entity.Location{Latitude: latitude, Longitude: longitude}
+	}
+
+	radius := 0.0
+	if c.Query("radius") != "" {
+		var err error
+		radius, err = strconv.ParseFloat(c.Query("radius"), 64)
+		if err != nil || radius <= 0 || radius > 100 {
+			err := response.NewBadRequestError("radius must be between 0 and 100 km")
+			c.JSON(err.Status(), gin.H{
+				"error": err,
+			})
+			return
+		}
+	}
+
+	availability, err := h.workUsecase.GetWorkAvailability(work, location, radius)
+	if err != nil {
+		log.Printf("Failed to Get Work Availability: %v", err)
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"work":         work,
+		"availability": availability,
+	})
+}
+
+func (h *WorkHandler) fetchWork(c *gin.Context, param string) (*entity.Work, bool) {
+	workID, err := strconv.Atoi(param)
+	if err != nil {
+		err := response.NewBadRequestError("id of work should be number")
+		c.JSON(err.Status(), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+
+	work, err := h.workUsecase.GetWorkByID(uint(workID))
+	if err != nil {
+		c.JSON(response.Status(err), gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+	return work, true
+}
@@ -0,0 +1,34 @@
+package work
+
+import (
+	"github.com/arjnep/gyanpass/internal/delivery/middleware"
+	"github.com/arjnep/gyanpass/internal/usecase"
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+type WorkHandler struct {
+	workUsecase usecase.WorkUsecase
+	jwtService  jwt.Service
+}
+
+type Config struct {
+	R           *gin.Engine
+	WorkUsecase usecase.WorkUsecase
+	JwtService  jwt.Service
+}
+
+func NewWorkHandler(c *Config) {
+	h := &WorkHandler{
+		workUsecase: c.WorkUsecase,
+		jwtService:  c.JwtService,
+	}
+
+	workRoutes := c.R.Group("/api/works")
+	{
+		workRoutes.GET("/", middleware.Pagination(), h.SearchWorks)
+		workRoutes.GET("/:id", h.GetWork)
+		workRoutes.POST("/rebuild", middleware.AuthUser(h.jwtService), middleware.RequireAdmin(), h.RebuildWorks)
+		workRoutes.POST("/:id/merge", middleware.AuthUser(h.jwtService), middleware.RequireAdmin(), h.MergeWorks)
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/arjnep/gyanpass/pkg/jwt"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin must run after AuthUser.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authUser, exists := c.Get("user")
+		if !exists || authUser.(*jwt.TokenClaims).User.Role != "admin" {
+			err := response.NewAuthorizationError("Admin access required")
+			c.JSON(err.Status(), gin.H{
+				"error": err,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
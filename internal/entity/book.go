@@ -1,18 +1,37 @@
 package entity
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
 
 type Book struct {
-	ID             uint        `gorm:"not null;primaryKey" json:"id"`
-	Title          string      `gorm:"not null" json:"title" binding:"required"`
-	Author         string      `gorm:"not null" json:"author" binding:"required"`
-	Genre          string      `json:"genre" binding:"omitempty"`
-	Description    Description `gorm:"embedded" json:"description" binding:"required"`
-	ImageUrl       string      `gorm:"not null" json:"image_url" binding:"required"`
-	UserID         uuid.UUID   `gorm:"not null" json:"user_id,omitempty"`
-	Owner          User        `gorm:"foreignKey:UserID" json:"owner"`
-	PickupLocation Location    `gorm:"embedded" json:"location,omitempty" binding:"required"`
-	IsActive       bool        `json:"is_active"`
+	ID                uint           `gorm:"not null;primaryKey" json:"id"`
+	Title             string         `gorm:"not null" json:"title" binding:"required"`
+	Author            string         `gorm:"not null" json:"author" binding:"required"`
+	Genre             string         `json:"genre" binding:"omitempty"`
+	GenreID           *uint          `gorm:"index" json:"genre_id,omitempty"`
+	Tags              []Tag          `gorm:"many2many:book_tags" json:"tags,omitempty"`
+	ISBN              string         `gorm:"index" json:"isbn,omitempty" binding:"omitempty"`
+	WorkID            *uint          `gorm:"index" json:"work_id,omitempty"`
+	Work              *Work          `gorm:"foreignKey:WorkID" json:"work,omitempty"`
+	Description       Description    `gorm:"embedded" json:"description" binding:"required"`
+	ImageUrl          string         `gorm:"not null" json:"image_url" binding:"omitempty"`
+	Images            []Image        `gorm:"foreignKey:BookID;constraint:OnDelete:SET NULL" json:"images,omitempty"`
+	UserID            uuid.UUID      `gorm:"not null" json:"user_id,omitempty"`
+	Owner             User           `gorm:"foreignKey:UserID" json:"owner"`
+	PickupLocation    Location       `gorm:"embedded" json:"location,omitempty" binding:"required"`
+	LocationPrecision string         `gorm:"not null;default:neighbourhood" json:"location_precision"` // "neighbourhood", "district", "city": how coarse the public location is
+	PublicLatitude    float64        `gorm:"not null;default:0" json:"-"`
+	PublicLongitude   float64        `gorm:"not null;default:0" json:"-"`
+	IsActive          bool           `json:"is_active"`
+	ActivatedAt       *time.Time     `gorm:"index" json:"-"`                                  // when the book last went on the shelf
+	ListingType       string         `gorm:"not null;default:swap;index" json:"listing_type"` // "swap", "giveaway", "either"
+	ClaimMode         string         `gorm:"not null;default:choose" json:"claim_mode"`       // "choose", "first": how giveaway claims are accepted
+	Version           int            `gorm:"not null;default:1" json:"version"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 
 type Description struct {
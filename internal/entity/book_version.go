@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BookVersion struct {
+	ID        uint          `gorm:"not null;primaryKey" json:"id"`
+	BookID    uint          `gorm:"not null;uniqueIndex:idx_book_versions_book_version" json:"book_id"`
+	Version   int           `gorm:"not null;uniqueIndex:idx_book_versions_book_version" json:"version"`
+	Changes   []FieldChange `gorm:"type:jsonb;serializer:json" json:"changes"`
+	Material  bool          `json:"material"`
+	ChangedBy uuid.UUID     `gorm:"type:uuid;not null" json:"changed_by"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
@@ -1,18 +1,62 @@
 package entity
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ExchangeStatus string
+
+const (
+	ExchangePending   ExchangeStatus = "pending"
+	ExchangeCountered ExchangeStatus = "countered" // the owner proposed another book; the requester answers
+	ExchangeAccepted  ExchangeStatus = "accepted"
+	ExchangeDeclined  ExchangeStatus = "declined"
+	ExchangeWithdrawn ExchangeStatus = "withdrawn"
+	ExchangeExchanged ExchangeStatus = "exchanged"
+	ExchangeExpired   ExchangeStatus = "expired"
+	ExchangeCancelled ExchangeStatus = "cancelled" // called off after it was accepted
+	ExchangeDisputed  ExchangeStatus = "disputed"  // waits for an admin to decide how it ended
+)
 
 type ExchangeRequest struct {
-	ID                   uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	RequestedByID        uuid.UUID `gorm:"not null" json:"requested_by_id" binding:"required"`
-	RequestedToID        uuid.UUID `gorm:"not null" json:"requested_to_id" binding:"required"`
-	RequestedBy          User      `gorm:"foreignKey:RequestedByID" json:"-"`
-	RequestedTo          User      `gorm:"foreignKey:RequestedToID" json:"-"`
-	RequestedBookID      uint      `gorm:"not null" json:"requested_book_id" binding:"required"`
-	RequestedBook        Book      `gorm:"foreignKey:RequestedBookID"`
-	OfferedBookID        uint      `gorm:"not null" json:"offered_book_id" binding:"required"`
-	OfferedBook          Book      `gorm:"foreignKey:OfferedBookID"`
-	Status               string    `gorm:"not null" json:"status"` // "pending", "accepted", "declined", "exchanged"
-	RequestedByConfirmed bool      `json:"requested_by_confirmed"`
-	RequestedToConfirmed bool      `json:"requested_to_confirmed"`
+	ID            uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	RequestedByID uuid.UUID `gorm:"not null" json:"requested_by_id" binding:"required"`
+	RequestedToID uuid.UUID `gorm:"not null" json:"requested_to_id" binding:"required"`
+	RequestedBy   User      `gorm:"foreignKey:RequestedByID" json:"-"`
+	RequestedTo   User      `gorm:"foreignKey:RequestedToID" json:"-"`
+	// RequestedBook and OfferedBook are the first book of each side, kept
+	// for clients that only know single-book exchanges. Items has them all.
+	RequestedBookID            uint              `gorm:"not null" json:"requested_book_id" binding:"required"`
+	RequestedBook              Book              `gorm:"foreignKey:RequestedBookID"`
+	OfferedBookID              *uint             `json:"offered_book_id,omitempty"`
+	OfferedBook                *Book             `gorm:"foreignKey:OfferedBookID" json:",omitempty"`
+	RequestedBookVersion       int               `gorm:"not null;default:1" json:"requested_book_version"`
+	OfferedBookVersion         int               `gorm:"not null;default:1" json:"offered_book_version"`
+	Type                       string            `gorm:"not null;default:swap" json:"type"` // "swap", "claim": a claim takes a giveaway book without offering one, "cycle": a leg of a trade cycle
+	TradeCycleID               *uuid.UUID        `gorm:"type:uuid;index" json:"trade_cycle_id,omitempty"`
+	Status                     ExchangeStatus    `gorm:"not null" json:"status"`
+	RequestedByConfirmed       bool              `json:"requested_by_confirmed"`
+	RequestedToConfirmed       bool              `json:"requested_to_confirmed"`
+	RequestedByHandoffVerified bool              `json:"requested_by_handoff_verified"` // confirmed by entering the owner's handoff code
+	RequestedToHandoffVerified bool              `json:"requested_to_handoff_verified"` // confirmed by entering the requester's handoff code
+	RequestedByMeetupAgreed    bool              `json:"requested_by_meetup_agreed"`
+	RequestedToMeetupAgreed    bool              `json:"requested_to_meetup_agreed"`
+	MeetupAgreedAt             *time.Time        `json:"meetup_agreed_at,omitempty"` // exact pickup points are shown to both sides from then on
+	AcceptedAt                 *time.Time        `json:"accepted_at,omitempty"`
+	LastReminderAt             *time.Time        `json:"-"`
+	CancelRequestedByID        *uuid.UUID        `gorm:"type:uuid" json:"cancel_requested_by_id,omitempty"` // asked the other side to call it off
+	CancelRequestedAt          *time.Time        `json:"cancel_requested_at,omitempty"`
+	CancelReason               string            `json:"cancel_reason,omitempty"`
+	DisputedByID               *uuid.UUID        `gorm:"type:uuid" json:"disputed_by_id,omitempty"`
+	DisputeReason              string            `json:"dispute_reason,omitempty"`
+	DisputeResolvedByID        *uuid.UUID        `gorm:"type:uuid" json:"dispute_resolved_by_id,omitempty"`
+	DisputeResolution          string            `json:"dispute_resolution,omitempty"`
+	CreatedAt                  time.Time         `gorm:"not null;default:now()" json:"created_at"`
+	Items                      []ExchangeItem    `gorm:"foreignKey:ExchangeRequestID;constraint:OnDelete:CASCADE" json:"items"`
+	Offers                     []ExchangeOffer   `gorm:"foreignKey:ExchangeRequestID;constraint:OnDelete:CASCADE" json:"-"`
+	Meetups                    []ExchangeMeetup  `gorm:"foreignKey:ExchangeRequestID;constraint:OnDelete:CASCADE" json:"-"`
+	Messages                   []ExchangeMessage `gorm:"foreignKey:ExchangeRequestID;constraint:OnDelete:CASCADE" json:"-"`
+	Events                     []ExchangeEvent   `gorm:"foreignKey:ExchangeRequestID;constraint:OnDelete:CASCADE" json:"-"`
 }
@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExchangeEvent is one entry in the timeline of an exchange request.
+type ExchangeEvent struct {
+	ID                uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ExchangeRequestID uuid.UUID      `gorm:"type:uuid;not null;index" json:"exchange_request_id"`
+	Action            string         `gorm:"not null" json:"action"`
+	FromStatus        ExchangeStatus `json:"from_status,omitempty"`
+	ToStatus          ExchangeStatus `gorm:"not null" json:"to_status"`
+	ActorID           *uuid.UUID     `gorm:"type:uuid" json:"actor_id,omitempty"` // nil when the system acted
+	Reason            string         `json:"reason,omitempty"`
+	CreatedAt         time.Time      `json:"created_at"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExchangeHandoff is the one-time code one side of an accepted exchange
+// shows the other at the meetup. The other side entering it proves the two
+// met and confirms that they received their books. Only a keyed hash of
+// the code is kept.
+type ExchangeHandoff struct {
+	ExchangeRequestID uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID            uuid.UUID  `gorm:"type:uuid;primaryKey"` // who shows the code
+	CodeHash          string     `gorm:"not null"`
+	ExpiresAt         time.Time  `gorm:"not null"`
+	FailedAttempts    int        `gorm:"not null;default:0"` // since the last lockout, kept across new codes
+	LockedUntil       *time.Time // no code is checked before then
+	UsedAt            *time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// HandoffCode is a freshly issued code, either typed in or scanned as a
+// QR code carrying QRPayload.
+type HandoffCode struct {
+	Code      string    `json:"code"`
+	QRPayload string    `json:"qr_payload"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+)
+
+const (
+	ItemRequested = "requested" // given by the owner the request is made to
+	ItemOffered   = "offered"   // given by the requester
+)
+
+// ExchangeItem puts one book into an exchange request. A request swaps
+// all of its requested books for all of its offered ones.
+type ExchangeItem struct {
+	ExchangeRequestID uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	BookID            uint      `gorm:"primaryKey;index" json:"book_id"`
+	Book              Book      `gorm:"foreignKey:BookID" json:"book"`
+	Side              string    `gorm:"not null" json:"side"`
+	BookVersion       int       `gorm:"not null;default:1" json:"book_version"`
+}
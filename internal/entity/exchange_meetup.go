@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MeetupProposed    = "proposed"
+	MeetupAccepted    = "accepted"
+	MeetupRescheduled = "rescheduled" // replaced by a later slot before or after it was accepted
+	MeetupSuperseded  = "superseded"  // still open when another slot was accepted
+)
+
+// ExchangeMeetup is a slot proposed for handing over the books of an
+// accepted exchange. Either side proposes slots and the other accepts one;
+// at most one slot of a request is accepted at a time.
+type ExchangeMeetup struct {
+	ID                uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ExchangeRequestID uuid.UUID  `gorm:"type:uuid;not null;index" json:"exchange_request_id"`
+	ProposedByID      uuid.UUID  `gorm:"type:uuid;not null" json:"proposed_by_id"`
+	RescheduledFromID *uuid.UUID `gorm:"type:uuid" json:"rescheduled_from_id,omitempty"`
+	StartsAt          time.Time  `gorm:"not null;index" json:"starts_at"`
+	Place             string     `gorm:"not null" json:"place"`
+	Latitude          *float64   `json:"latitude,omitempty"`
+	Longitude         *float64   `json:"longitude,omitempty"`
+	Note              string     `json:"note,omitempty"`
+	Status            string     `gorm:"not null" json:"status"`
+	RespondedAt       *time.Time `json:"responded_at,omitempty"`
+	RemindedAt        *time.Time `json:"-"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExchangeMessage is one message in the thread of an exchange request,
+// which only its two sides can read and write.
+type ExchangeMessage struct {
+	ID                uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ExchangeRequestID uuid.UUID  `gorm:"type:uuid;not null;index:idx_exchange_message_thread,priority:1" json:"exchange_request_id"`
+	SenderID          uuid.UUID  `gorm:"type:uuid;not null" json:"sender_id"`
+	Body              string     `json:"body,omitempty"`
+	Attachments       []Image    `gorm:"foreignKey:MessageID;constraint:OnDelete:SET NULL" json:"attachments,omitempty"`
+	ReadAt            *time.Time `json:"read_at,omitempty"` // when the other side first saw it
+	CreatedAt         time.Time  `gorm:"index:idx_exchange_message_thread,priority:2" json:"created_at"`
+}
+
+// ExchangeThread is the messages of one exchange request. An archived
+// thread belongs to an exchange that is over and can only be read.
+type ExchangeThread struct {
+	ExchangeRequestID uuid.UUID         `json:"exchange_request_id"`
+	Archived          bool              `json:"archived"`
+	Messages          []ExchangeMessage `json:"messages"`
+}
+
+// ExchangeThreadUnread is how many messages of a thread a user has not
+// seen yet.
+type ExchangeThreadUnread struct {
+	ExchangeRequestID uuid.UUID `json:"exchange_request_id"`
+	Unread            int64     `json:"unread"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExchangeOffer is one revision of the books a swap request offers. The
+// owner and the requester counter each other with books from the
+// requester's shelf until one side accepts or walks away.
+type ExchangeOffer struct {
+	ID                 uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ExchangeRequestID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_exchange_offer_revision" json:"exchange_request_id"`
+	Revision           int       `gorm:"not null;uniqueIndex:idx_exchange_offer_revision" json:"revision"`
+	ProposedByID       uuid.UUID `gorm:"type:uuid;not null" json:"proposed_by_id"`
+	OfferedBookID      uint      `gorm:"not null" json:"offered_book_id"` // first of OfferedBookIDs
+	OfferedBook        *Book     `gorm:"foreignKey:OfferedBookID" json:"offered_book,omitempty"`
+	OfferedBookIDs     []uint    `gorm:"type:jsonb;serializer:json" json:"offered_book_ids"`
+	OfferedBookVersion int       `gorm:"not null;default:1" json:"offered_book_version"`
+	Message            string    `json:"message,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+}
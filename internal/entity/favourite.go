@@ -0,0 +1,14 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Favourite struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	BookID    uint      `gorm:"primaryKey;index" json:"book_id"`
+	Book      Book      `gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE" json:"book"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+type Genre struct {
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	Name      string       `gorm:"not null;uniqueIndex" json:"name"`
+	ParentID  *uint        `gorm:"index" json:"parent_id,omitempty"`
+	Aliases   []GenreAlias `gorm:"foreignKey:GenreID;constraint:OnDelete:CASCADE" json:"aliases,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// GenreAlias maps a normalized spelling such as "sci fi" or "sf" to its
+// canonical genre. Every genre is also an alias of its own name.
+type GenreAlias struct {
+	Alias   string `gorm:"primaryKey" json:"alias"`
+	GenreID uint   `gorm:"not null;index" json:"genre_id"`
+}
+
+type Tag struct {
+	ID   uint   `gorm:"primaryKey" json:"id"`
+	Name string `gorm:"not null;uniqueIndex" json:"name"`
+}
+
+// TagCount is a tag with the number of books carrying it.
+type TagCount struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Books int    `json:"books"`
+}
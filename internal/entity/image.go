@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Image struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	BookID       *uint      `gorm:"index" json:"book_id,omitempty"`
+	MessageID    *uuid.UUID `gorm:"type:uuid;index" json:"message_id,omitempty"` // set instead of BookID when attached to an exchange message
+	Position     int        `json:"position"`
+	StorageKey   string     `gorm:"not null" json:"-"`
+	ThumbnailKey string     `gorm:"not null" json:"-"`
+	URL          string     `gorm:"not null" json:"url"`
+	ThumbnailURL string     `gorm:"not null" json:"thumbnail_url"`
+	ContentType  string     `gorm:"not null" json:"content_type"`
+	Size         int64      `json:"size"`
+	Width        int        `json:"width"`
+	Height       int        `json:"height"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
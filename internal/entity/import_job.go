@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ImportJob struct {
+	ID            uuid.UUID        `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID        uuid.UUID        `gorm:"type:uuid;not null;index" json:"user_id"`
+	Format        string           `gorm:"not null" json:"format"` // "csv", "ndjson"
+	DryRun        bool             `json:"dry_run"`
+	Status        string           `gorm:"not null" json:"status"` // "queued", "running", "completed", "failed"
+	TotalRows     int              `json:"total_rows"`
+	ProcessedRows int              `json:"processed_rows"`
+	ImportedRows  int              `json:"imported_rows"`
+	FailedRows    int              `json:"failed_rows"`
+	Errors        []ImportRowError `gorm:"type:jsonb;serializer:json" json:"errors"`
+	Message       string           `json:"message,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	FinishedAt    *time.Time       `json:"finished_at,omitempty"`
+}
+
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
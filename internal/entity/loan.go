@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Loan struct {
+	ID                      uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	BookID                  uint       `gorm:"not null;index" json:"book_id"`
+	Book                    Book       `gorm:"foreignKey:BookID" json:"book"`
+	LenderID                uuid.UUID  `gorm:"type:uuid;not null;index" json:"lender_id"`
+	BorrowerID              uuid.UUID  `gorm:"type:uuid;not null;index" json:"borrower_id"`
+	Lender                  User       `gorm:"foreignKey:LenderID" json:"-"`
+	Borrower                User       `gorm:"foreignKey:BorrowerID" json:"-"`
+	Status                  string     `gorm:"not null;index" json:"status"` // "requested", "approved", "declined", "cancelled", "handed_over", "due", "overdue", "returned"
+	DurationDays            int        `gorm:"not null" json:"duration_days"`
+	Message                 string     `json:"message,omitempty"`
+	HandedOverAt            *time.Time `json:"handed_over_at,omitempty"`
+	DueDate                 *time.Time `gorm:"index" json:"due_date,omitempty"`
+	LenderReturnConfirmed   bool       `json:"lender_return_confirmed"`
+	BorrowerReturnConfirmed bool       `json:"borrower_return_confirmed"`
+	ReturnedAt              *time.Time `json:"returned_at,omitempty"`
+	LastReminderAt          *time.Time `json:"-"`
+	CreatedAt               time.Time  `json:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Recommendation is a precomputed suggestion of a book for a user, rebuilt
+// periodically by the recommendations job.
+type Recommendation struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	BookID    uint      `gorm:"primaryKey;index" json:"book_id"`
+	Book      Book      `gorm:"foreignKey:BookID;constraint:OnDelete:CASCADE" json:"book"`
+	Score     float64   `gorm:"not null;index" json:"score"`
+	Reason    string    `gorm:"not null" json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
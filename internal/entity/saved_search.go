@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SavedSearch struct {
+	ID           uuid.UUID         `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID       uuid.UUID         `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name         string            `gorm:"not null" json:"name"`
+	Filters      map[string]string `gorm:"type:jsonb;serializer:json" json:"filters"`
+	Latitude     *float64          `json:"latitude,omitempty"`
+	Longitude    *float64          `json:"longitude,omitempty"`
+	RadiusKm     float64           `json:"radius_km,omitempty"`
+	Frequency    string            `gorm:"not null" json:"frequency"` // "daily", "weekly"
+	Email        bool              `json:"email"`
+	CoveredUntil *time.Time        `json:"-"` // watermark: books activated up to here are covered by a digest
+	LastRunAt    *time.Time        `json:"last_run_at,omitempty"`
+	NextRunAt    time.Time         `gorm:"not null;index" json:"next_run_at"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CycleProposed = "proposed"
+	CycleAccepted = "accepted"
+	CycleDeclined = "declined"
+	CycleExpired  = "expired"
+)
+
+// TradeCycle is a trade between three or more people where each gives a
+// book to the next one round the cycle. Every gift is an exchange request
+// of its own, a leg, that only goes ahead once everyone accepted.
+type TradeCycle struct {
+	ID           uuid.UUID               `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Signature    string                  `gorm:"not null;index" json:"-"` // sorted book ids, so a declined cycle is not proposed again
+	Status       string                  `gorm:"not null" json:"status"`
+	Participants []TradeCycleParticipant `gorm:"foreignKey:TradeCycleID;constraint:OnDelete:CASCADE" json:"participants"`
+	Legs         []ExchangeRequest       `gorm:"foreignKey:TradeCycleID" json:"legs"`
+	CreatedAt    time.Time               `json:"created_at"`
+}
+
+type TradeCycleParticipant struct {
+	TradeCycleID uuid.UUID  `gorm:"type:uuid;primaryKey" json:"-"`
+	UserID       uuid.UUID  `gorm:"type:uuid;primaryKey;index" json:"user_id"`
+	User         User       `gorm:"foreignKey:UserID" json:"user"`
+	Accepted     bool       `json:"accepted"`
+	RespondedAt  *time.Time `json:"responded_at,omitempty"`
+}
@@ -12,4 +12,7 @@ type User struct {
 	Phone     string    `gorm:"unique;not null" json:"phone,omitempty" binding:"required"`
 	Password  string    `gorm:"not null" json:"-" binding:"required,min=8"`
 	Role      string    `gorm:"default:user" json:"role,omitempty"` // "admin", "user"
+	// CancellationCount is how many accepted exchanges the user called off
+	// alone or was found at fault for, shown to others as reputation.
+	CancellationCount int `gorm:"not null;default:0" json:"cancellation_count"`
 }
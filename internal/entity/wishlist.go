@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WishlistItem struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	ISBN      string    `gorm:"index" json:"isbn"`
+	Latitude  *float64  `json:"latitude,omitempty"`
+	Longitude *float64  `json:"longitude,omitempty"`
+	RadiusKm  float64   `json:"radius_km,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WishlistMatch records that a user was alerted about a book so the same
+// book never triggers a second alert for them.
+type WishlistMatch struct {
+	UserID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	BookID         uint      `gorm:"primaryKey" json:"book_id"`
+	WishlistItemID uuid.UUID `gorm:"type:uuid;not null" json:"wishlist_item_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// PendingBookMatch is a book waiting for a wishlist check that did not fit
+// in the in-memory queue; the scheduler works through them later.
+type PendingBookMatch struct {
+	BookID    uint      `gorm:"primaryKey" json:"book_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+type Work struct {
+	ID        uint      `gorm:"not null;primaryKey" json:"id"`
+	Title     string    `gorm:"not null" json:"title"`
+	Authors   []string  `gorm:"type:jsonb;serializer:json" json:"authors"`
+	ISBNs     []string  `gorm:"column:isbns;type:jsonb;serializer:json" json:"isbns"`
+	Genres    []string  `gorm:"type:jsonb;serializer:json" json:"genres"`
+	DedupKey  string    `gorm:"not null;index" json:"-"`
+	AuthorKey string    `gorm:"not null;index" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WorkAvailability struct {
+	Copies          int          `json:"copies"`
+	Available       int          `json:"available"`
+	AvailableNearby int          `json:"available_nearby"`
+	RadiusKm        float64      `json:"radius_km,omitempty"`
+	NearbyCopies    []NearbyCopy `json:"nearby_copies,omitempty"`
+}
+
+type NearbyCopy struct {
+	BookID     uint    `json:"book_id"`
+	Condition  string  `json:"condition"`
+	Address    string  `json:"address"`
+	OwnerName  string  `json:"owner_name"`
+	DistanceKm float64 `json:"distance_km"`
+}
@@ -1,35 +1,54 @@
 package repository
 
 import (
+	"maps"
+	"time"
+
 	"github.com/arjnep/gyanpass/internal/entity"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BookRepository interface {
 	Create(book *entity.Book) error
 	FindByID(id uint) (*entity.Book, error)
 	FindByUserID(uid uuid.UUID) ([]entity.Book, error)
+	FindDeletedByUserID(uid uuid.UUID) ([]entity.Book, error)
 	FindByQueryParams(queryParams map[string]string, page, size int) ([]entity.Book, int, error)
+	FindWithoutPublicLocation(afterID uint, limit int) ([]entity.Book, error)
 	Update(book *entity.Book, updates map[string]interface{}) error
+	OnAvailabilityChange(listener AvailabilityListener)
 	Delete(book *entity.Book) error
+	LockByIDs(ids []uint) ([]entity.Book, error)
+	WithTx(tx *Tx) BookRepository
 }
 
+// AvailabilityListener is told after a book's is_active flag actually
+// changed, whichever code path changed it.
+type AvailabilityListener func(bookID uint, active bool)
+
 type bookRepository struct {
-	db *gorm.DB
+	db        *gorm.DB
+	listeners []AvailabilityListener
+	tx        *Tx
 }
 
 func NewBookRepository(db *gorm.DB) BookRepository {
-	return &bookRepository{db}
+	return &bookRepository{db: db}
 }
 
 func (r *bookRepository) Create(book *entity.Book) error {
+	if book.IsActive && book.ActivatedAt == nil {
+		now := time.Now()
+		book.ActivatedAt = &now
+	}
 	return r.db.Create(book).Error
 }
 
 func (r *bookRepository) FindByID(id uint) (*entity.Book, error) {
 	var book entity.Book
-	err := r.db.Preload("Owner").First(&book, id).Error
+	err := r.db.Preload("Owner").Preload("Images", orderByPosition).Preload("Tags").First(&book, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -38,35 +57,32 @@ func (r *bookRepository) FindByID(id uint) (*entity.Book, error) {
 
 func (r *bookRepository) FindByUserID(uid uuid.UUID) ([]entity.Book, error) {
 	var books []entity.Book
-	err := r.db.Where("user_id = ?", uid).Find(&books).Error
+	err := r.db.Preload("Images", orderByPosition).Preload("Tags").Where("user_id = ?", uid).Find(&books).Error
 	if err != nil {
 		return nil, err
 	}
 	return books, err
 }
 
+func (r *bookRepository) FindDeletedByUserID(uid uuid.UUID) ([]entity.Book, error) {
+	var books []entity.Book
+	err := r.db.Unscoped().Preload("Images", orderByPosition).Preload("Tags").
+		Where("user_id = ? AND deleted_at IS NOT NULL", uid).Order("deleted_at desc").Find(&books).Error
+	return books, err
+}
+
 func (r *bookRepository) FindByQueryParams(queryParams map[string]string, page, size int) ([]entity.Book, int, error) {
 	var books []entity.Book
 	var total int64
 
-	query := r.db.Model(&entity.Book{})
-	for key, value := range queryParams {
-		if value != "" {
-			switch key {
-			case "title":
-				query = query.Where("title ILIKE ?", "%"+value+"%")
-			case "address":
-				query = query.Where("address ILIKE ?", "%"+value+"%")
-			}
-		}
-	}
+	query := applyBookFilters(r.db.Model(&entity.Book{}), queryParams)
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	offset := (page - 1) * size
-	query = query.Limit(size).Offset(offset).Preload("Owner")
+	query = query.Limit(size).Offset(offset).Preload("Owner").Preload("Images", orderByPosition).Preload("Tags")
 
 	if err := query.Find(&books).Error; err != nil {
 		return nil, 0, err
@@ -75,10 +91,103 @@ func (r *bookRepository) FindByQueryParams(queryParams map[string]string, page,
 	return books, int(total), nil
 }
 
+// FindWithoutPublicLocation pages through books listed before public
+// coordinates existed.
+func (r *bookRepository) FindWithoutPublicLocation(afterID uint, limit int) ([]entity.Book, error) {
+	var books []entity.Book
+	err := r.db.Where("id > ? AND public_latitude = 0 AND public_longitude = 0", afterID).
+		Order("id").Limit(limit).Find(&books).Error
+	return books, err
+}
+
 func (r *bookRepository) Update(book *entity.Book, updates map[string]interface{}) error {
-	return r.db.Model(book).Updates(updates).Error
+	active, ok := updates["is_active"].(bool)
+	if ok && active {
+		// The CASE reads the row as it was, so only a book coming back on
+		// the shelf gets a new activation time.
+		updates = maps.Clone(updates)
+		updates["activated_at"] = gorm.Expr("CASE WHEN is_active THEN activated_at ELSE ? END", time.Now())
+	}
+	if !ok || len(r.listeners) == 0 {
+		return r.db.Model(book).Updates(updates).Error
+	}
+
+	// Callers often set the flag on book before saving it, so the previous
+	// value is read from the row itself.
+	var changed bool
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var previous []bool
+		err := tx.Unscoped().Model(&entity.Book{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", book.ID).Pluck("is_active", &previous).Error
+		if err != nil {
+			return err
+		}
+		changed = len(previous) == 1 && previous[0] != active
+		return tx.Model(book).Updates(updates).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		for _, listener := range r.listeners {
+			if r.tx != nil {
+				listener := listener
+				r.tx.AfterCommit(func() { listener(book.ID, active) })
+			} else {
+				listener(book.ID, active)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *bookRepository) OnAvailabilityChange(listener AvailabilityListener) {
+	r.listeners = append(r.listeners, listener)
 }
 
 func (r *bookRepository) Delete(book *entity.Book) error {
 	return r.db.Delete(book).Error
 }
+
+// LockByIDs loads the books with FOR UPDATE, in id order so that
+// transactions locking overlapping sets cannot deadlock.
+func (r *bookRepository) LockByIDs(ids []uint) ([]entity.Book, error) {
+	var books []entity.Book
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Order("id").Find(&books).Error
+	return books, err
+}
+
+// WithTx returns a copy of the repository working inside tx. Availability
+// listeners are only told once tx commits.
+func (r *bookRepository) WithTx(tx *Tx) BookRepository {
+	return &bookRepository{db: tx.db, listeners: r.listeners, tx: tx}
+}
+
+// applyBookFilters narrows a books query by the search filters shared by
+// SearchBooks and saved searches.
+func applyBookFilters(query *gorm.DB, queryParams map[string]string) *gorm.DB {
+	for key, value := range queryParams {
+		if value != "" {
+			switch key {
+			case "title":
+				query = query.Where("title ILIKE ?", "%"+value+"%")
+			case "address":
+				query = query.Where("address ILIKE ?", "%"+value+"%")
+			case "isbn":
+				query = query.Where("isbn = ?", value)
+			case "listing_type":
+				query = query.Where("listing_type IN ?", []string{value, "either"})
+			case "genre":
+				query = query.Where("genre_id IN ("+genreSubtreeSQL+")", value)
+			case "tag":
+				query = query.Where("EXISTS (SELECT 1 FROM book_tags JOIN tags ON tags.id = book_tags.tag_id WHERE book_tags.book_id = books.id AND tags.name = ?)", value)
+			}
+		}
+	}
+	return query
+}
+
+func orderByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"github.com/arjnep/gyanpass/internal/entity"
+	"gorm.io/gorm"
+)
+
+type BookVersionRepository interface {
+	Create(version *entity.BookVersion) error
+	FindByBookID(bookID uint, sinceVersion int) ([]entity.BookVersion, error)
+	HasMaterialChangeSince(bookID uint, version int) (bool, error)
+	WithTx(tx *Tx) BookVersionRepository
+}
+
+type bookVersionRepository struct {
+	db *gorm.DB
+}
+
+func NewBookVersionRepository(db *gorm.DB) BookVersionRepository {
+	return &bookVersionRepository{db}
+}
+
+func (r *bookVersionRepository) Create(version *entity.BookVersion) error {
+	return r.db.Create(version).Error
+}
+
+func (r *bookVersionRepository) FindByBookID(bookID uint, sinceVersion int) ([]entity.BookVersion, error) {
+	var versions []entity.BookVersion
+	err := r.db.Where("book_id = ? AND version > ?", bookID, sinceVersion).Order("version").Find(&versions).Error
+	return versions, err
+}
+
+func (r *bookVersionRepository) HasMaterialChangeSince(bookID uint, version int) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.BookVersion{}).
+		Where("book_id = ? AND version > ? AND material = ?", bookID, version, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *bookVersionRepository) WithTx(tx *Tx) BookVersionRepository {
+	return &bookVersionRepository{tx.db}
+}
@@ -0,0 +1,23 @@
+package repository
+
+import "fmt"
+
+// distanceExpr is the haversine distance in kilometres between a books row's
+// public, fuzzed coordinates and the point passed as (lat, lat, lng). Exact
+// pickup points are never used for distances shown or filtered on.
+var distanceExpr = distanceExprOver("books.public_latitude", "books.public_longitude")
+
+// distanceExprFor builds the same expression over the latitude and
+// longitude columns of another table.
+func distanceExprFor(table string) string {
+	return distanceExprOver(table+".latitude", table+".longitude")
+}
+
+func distanceExprOver(latColumn, lngColumn string) string {
+	return fmt.Sprintf("(6371 * 2 * asin(least(1, sqrt(power(sin(radians(%[1]s - ?) / 2), 2) + "+
+		"cos(radians(?)) * cos(radians(%[1]s)) * power(sin(radians(%[2]s - ?) / 2), 2)))))", latColumn, lngColumn)
+}
+
+func distanceArgs(lat, lng float64) []interface{} {
+	return []interface{}{lat, lat, lng}
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ExchangeEventRepository interface {
+	Create(event *entity.ExchangeEvent) error
+	FindByRequestID(requestID uuid.UUID) ([]entity.ExchangeEvent, error)
+	WithTx(tx *Tx) ExchangeEventRepository
+}
+
+type exchangeEventRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeEventRepository(db *gorm.DB) ExchangeEventRepository {
+	return &exchangeEventRepository{db}
+}
+
+func (r *exchangeEventRepository) Create(event *entity.ExchangeEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *exchangeEventRepository) FindByRequestID(requestID uuid.UUID) ([]entity.ExchangeEvent, error) {
+	var events []entity.ExchangeEvent
+	err := r.db.Where("exchange_request_id = ?", requestID).Order("created_at, id").Find(&events).Error
+	return events, err
+}
+
+func (r *exchangeEventRepository) WithTx(tx *Tx) ExchangeEventRepository {
+	return &exchangeEventRepository{tx.db}
+}
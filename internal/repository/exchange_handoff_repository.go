@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ExchangeHandoffRepository interface {
+	Find(requestID uuid.UUID, userID uuid.UUID) (*entity.ExchangeHandoff, error)
+	Save(handoff *entity.ExchangeHandoff) error
+	WithTx(tx *Tx) ExchangeHandoffRepository
+}
+
+type exchangeHandoffRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeHandoffRepository(db *gorm.DB) ExchangeHandoffRepository {
+	return &exchangeHandoffRepository{db}
+}
+
+// Find returns the code userID shows for the request,
+// gorm.ErrRecordNotFound when they never asked for one.
+func (r *exchangeHandoffRepository) Find(requestID uuid.UUID, userID uuid.UUID) (*entity.ExchangeHandoff, error) {
+	var handoff entity.ExchangeHandoff
+	err := r.db.Where("exchange_request_id = ? AND user_id = ?", requestID, userID).First(&handoff).Error
+	if err != nil {
+		return nil, err
+	}
+	return &handoff, nil
+}
+
+// Save inserts the code or replaces the one kept for the same request and
+// side.
+func (r *exchangeHandoffRepository) Save(handoff *entity.ExchangeHandoff) error {
+	return r.db.Save(handoff).Error
+}
+
+func (r *exchangeHandoffRepository) WithTx(tx *Tx) ExchangeHandoffRepository {
+	return &exchangeHandoffRepository{tx.db}
+}
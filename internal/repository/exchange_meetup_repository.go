@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ExchangeMeetupRepository interface {
+	Create(meetup *entity.ExchangeMeetup) error
+	FindByID(id uuid.UUID) (*entity.ExchangeMeetup, error)
+	FindByRequestID(requestID uuid.UUID) ([]entity.ExchangeMeetup, error)
+	FindAccepted(requestID uuid.UUID) (*entity.ExchangeMeetup, error)
+	Update(meetup *entity.ExchangeMeetup) error
+	CloseOpen(requestID uuid.UUID, status string, at time.Time) error
+	FindUnremindedStartingBefore(before time.Time, limit int) ([]entity.ExchangeMeetup, error)
+	MarkReminded(id uuid.UUID, at time.Time) error
+	WithTx(tx *Tx) ExchangeMeetupRepository
+}
+
+type exchangeMeetupRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeMeetupRepository(db *gorm.DB) ExchangeMeetupRepository {
+	return &exchangeMeetupRepository{db}
+}
+
+func (r *exchangeMeetupRepository) Create(meetup *entity.ExchangeMeetup) error {
+	return r.db.Create(meetup).Error
+}
+
+func (r *exchangeMeetupRepository) FindByID(id uuid.UUID) (*entity.ExchangeMeetup, error) {
+	var meetup entity.ExchangeMeetup
+	err := r.db.First(&meetup, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &meetup, nil
+}
+
+func (r *exchangeMeetupRepository) FindByRequestID(requestID uuid.UUID) ([]entity.ExchangeMeetup, error) {
+	var meetups []entity.ExchangeMeetup
+	err := r.db.Where("exchange_request_id = ?", requestID).Order("created_at").Find(&meetups).Error
+	return meetups, err
+}
+
+// FindAccepted returns the slot the request's sides agreed on,
+// gorm.ErrRecordNotFound when there is none.
+func (r *exchangeMeetupRepository) FindAccepted(requestID uuid.UUID) (*entity.ExchangeMeetup, error) {
+	var meetup entity.ExchangeMeetup
+	err := r.db.Where("exchange_request_id = ? AND status = ?", requestID, entity.MeetupAccepted).First(&meetup).Error
+	if err != nil {
+		return nil, err
+	}
+	return &meetup, nil
+}
+
+func (r *exchangeMeetupRepository) Update(meetup *entity.ExchangeMeetup) error {
+	return r.db.Save(meetup).Error
+}
+
+// CloseOpen gives every slot of the request still waiting for an answer
+// the given status.
+func (r *exchangeMeetupRepository) CloseOpen(requestID uuid.UUID, status string, at time.Time) error {
+	return r.db.Model(&entity.ExchangeMeetup{}).
+		Where("exchange_request_id = ? AND status = ?", requestID, entity.MeetupProposed).
+		Updates(map[string]interface{}{"status": status, "responded_at": at}).Error
+}
+
+// FindUnremindedStartingBefore returns accepted slots of still accepted
+// exchanges that start between now and before and were not reminded of
+// yet, soonest first.
+func (r *exchangeMeetupRepository) FindUnremindedStartingBefore(before time.Time, limit int) ([]entity.ExchangeMeetup, error) {
+	var meetups []entity.ExchangeMeetup
+	err := r.db.Where("status = ? AND reminded_at IS NULL AND starts_at > ? AND starts_at <= ?", entity.MeetupAccepted, time.Now(), before).
+		Where("exchange_request_id IN (SELECT id FROM exchange_requests WHERE status = ?)", entity.ExchangeAccepted).
+		Order("starts_at").Limit(limit).Find(&meetups).Error
+	return meetups, err
+}
+
+// MarkReminded sets only reminded_at, so that it cannot overwrite an
+// answer given meanwhile.
+func (r *exchangeMeetupRepository) MarkReminded(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&entity.ExchangeMeetup{}).Where("id = ?", id).Update("reminded_at", at).Error
+}
+
+func (r *exchangeMeetupRepository) WithTx(tx *Tx) ExchangeMeetupRepository {
+	return &exchangeMeetupRepository{tx.db}
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ExchangeMessageRepository interface {
+	Create(message *entity.ExchangeMessage) error
+	FindByRequestID(requestID uuid.UUID) ([]entity.ExchangeMessage, error)
+	CountUnread(requestID uuid.UUID, userID uuid.UUID) (int64, error)
+	CountUnreadByUserID(userID uuid.UUID) ([]entity.ExchangeThreadUnread, error)
+	MarkRead(requestID uuid.UUID, userID uuid.UUID, at time.Time) error
+	WithTx(tx *Tx) ExchangeMessageRepository
+}
+
+type exchangeMessageRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeMessageRepository(db *gorm.DB) ExchangeMessageRepository {
+	return &exchangeMessageRepository{db}
+}
+
+// Create inserts the message alone; its attachments are images uploaded
+// before and linked to it afterwards.
+func (r *exchangeMessageRepository) Create(message *entity.ExchangeMessage) error {
+	return r.db.Omit("Attachments").Create(message).Error
+}
+
+func (r *exchangeMessageRepository) FindByRequestID(requestID uuid.UUID) ([]entity.ExchangeMessage, error) {
+	var messages []entity.ExchangeMessage
+	err := r.db.Preload("Attachments", orderByPosition).
+		Where("exchange_request_id = ?", requestID).Order("created_at, id").Find(&messages).Error
+	return messages, err
+}
+
+// CountUnread counts the messages of the request's thread sent to userID
+// that they have not seen yet.
+func (r *exchangeMessageRepository) CountUnread(requestID uuid.UUID, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&entity.ExchangeMessage{}).
+		Where("exchange_request_id = ? AND sender_id <> ? AND read_at IS NULL", requestID, userID).Count(&count).Error
+	return count, err
+}
+
+// CountUnreadByUserID is CountUnread for every thread userID takes part
+// in, leaving out those without unread messages.
+func (r *exchangeMessageRepository) CountUnreadByUserID(userID uuid.UUID) ([]entity.ExchangeThreadUnread, error) {
+	var counts []entity.ExchangeThreadUnread
+	err := r.db.Model(&entity.ExchangeMessage{}).
+		Select("exchange_messages.exchange_request_id, COUNT(*) AS unread").
+		Joins("JOIN exchange_requests ON exchange_requests.id = exchange_messages.exchange_request_id").
+		Where("(exchange_requests.requested_by_id = ? OR exchange_requests.requested_to_id = ?) AND exchange_messages.sender_id <> ? AND exchange_messages.read_at IS NULL", userID, userID, userID).
+		Group("exchange_messages.exchange_request_id").Order("exchange_messages.exchange_request_id").Scan(&counts).Error
+	return counts, err
+}
+
+// MarkRead marks the messages of the request's thread sent to userID as
+// seen, keeping when the earlier ones were first seen.
+func (r *exchangeMessageRepository) MarkRead(requestID uuid.UUID, userID uuid.UUID, at time.Time) error {
+	return r.db.Model(&entity.ExchangeMessage{}).
+		Where("exchange_request_id = ? AND sender_id <> ? AND read_at IS NULL", requestID, userID).
+		Update("read_at", at).Error
+}
+
+func (r *exchangeMessageRepository) WithTx(tx *Tx) ExchangeMessageRepository {
+	return &exchangeMessageRepository{tx.db}
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ExchangeOfferRepository interface {
+	Create(offer *entity.ExchangeOffer) error
+	FindByRequestID(requestID uuid.UUID) ([]entity.ExchangeOffer, error)
+	LatestRevision(requestID uuid.UUID) (int, error)
+	WithTx(tx *Tx) ExchangeOfferRepository
+}
+
+type exchangeOfferRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeOfferRepository(db *gorm.DB) ExchangeOfferRepository {
+	return &exchangeOfferRepository{db}
+}
+
+func (r *exchangeOfferRepository) Create(offer *entity.ExchangeOffer) error {
+	return r.db.Create(offer).Error
+}
+
+func (r *exchangeOfferRepository) FindByRequestID(requestID uuid.UUID) ([]entity.ExchangeOffer, error) {
+	var offers []entity.ExchangeOffer
+	err := r.db.Preload("OfferedBook", unscoped).Preload("OfferedBook.Images", orderByPosition).
+		Where("exchange_request_id = ?", requestID).Order("revision").Find(&offers).Error
+	return offers, err
+}
+
+// LatestRevision returns the request's highest revision, 0 when it has
+// none recorded.
+func (r *exchangeOfferRepository) LatestRevision(requestID uuid.UUID) (int, error) {
+	var revision int
+	err := r.db.Model(&entity.ExchangeOffer{}).Where("exchange_request_id = ?", requestID).
+		Select("COALESCE(MAX(revision), 0)").Scan(&revision).Error
+	return revision, err
+}
+
+func (r *exchangeOfferRepository) WithTx(tx *Tx) ExchangeOfferRepository {
+	return &exchangeOfferRepository{tx.db}
+}
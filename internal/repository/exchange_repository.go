@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/arjnep/gyanpass/internal/entity"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ExchangeRepository interface {
@@ -17,8 +20,22 @@ type ExchangeRepository interface {
 	IsSelfRequest(requestedByID, requestedToID uuid.UUID) bool
 	FindPendingRequests(requestedByID, requestedToID uuid.UUID) ([]entity.ExchangeRequest, error)
 	FindPendingRequestsByBookID(bookID uint) ([]entity.ExchangeRequest, error)
+	HasAcceptedRequestForBook(bookID uint) (bool, error)
 	FindRequestsByBookIDAndUserID(bookID uint, userID uuid.UUID) ([]entity.ExchangeRequest, error)
 	FindRequestsByUserID(userID uuid.UUID) ([]entity.ExchangeRequest, error)
+	LockByID(id uuid.UUID) (*entity.ExchangeRequest, error)
+	FindPendingIdleSince(since time.Time, limit int) ([]entity.ExchangeRequest, error)
+	FindAcceptedBefore(before time.Time, limit int) ([]entity.ExchangeRequest, error)
+	FindCyclesAcceptedBefore(before time.Time, limit int) ([]uuid.UUID, error)
+	FindByTradeCycleID(cycleID uuid.UUID) ([]entity.ExchangeRequest, error)
+	FindUnremindedAcceptedBefore(before time.Time, limit int) ([]entity.ExchangeRequest, error)
+	MarkReminded(id uuid.UUID, at time.Time) error
+	FindItems(requestID uuid.UUID) ([]entity.ExchangeItem, error)
+	ReplaceItems(requestID uuid.UUID, side string, items []entity.ExchangeItem) error
+	BackfillItems() error
+	FindDisputed() ([]entity.ExchangeRequest, error)
+	FindBookIDsInOpenRequests(bookIDs []uint) ([]uint, error)
+	WithTx(tx *Tx) ExchangeRepository
 }
 
 type exchangeRepository struct {
@@ -35,26 +52,26 @@ func (r *exchangeRepository) Create(exchangeRequest *entity.ExchangeRequest) err
 
 func (r *exchangeRepository) FindByID(id uuid.UUID) (*entity.ExchangeRequest, error) {
 	var exchangeRequest entity.ExchangeRequest
-	err := r.db.Preload("RequestedBy").Preload("RequestedTo").Preload("RequestedBook").Preload("RequestedBook.Owner").Preload("OfferedBook").Preload("OfferedBook.Owner").First(&exchangeRequest, id).Error
+	err := r.db.Preload("RequestedBy").Preload("RequestedTo").Preload("RequestedBook", unscoped).Preload("RequestedBook.Owner").Preload("OfferedBook", unscoped).Preload("OfferedBook.Owner").Preload("Items", orderItems).Preload("Items.Book", unscoped).Preload("Items.Book.Owner").First(&exchangeRequest, id).Error
 	return &exchangeRequest, err
 }
 
 func (r *exchangeRepository) FindByRequestedByID(userID uuid.UUID) ([]entity.ExchangeRequest, error) {
 	var exchangeRequests []entity.ExchangeRequest
-	err := r.db.Preload("RequestedBy").Preload("RequestedTo").Preload("RequestedBook").Preload("RequestedBook.Owner").Preload("OfferedBook").Preload("OfferedBook.Owner").Where("requested_by_id = ?", userID).Find(&exchangeRequests).Error
+	err := r.db.Preload("RequestedBy").Preload("RequestedTo").Preload("RequestedBook", unscoped).Preload("RequestedBook.Owner").Preload("OfferedBook", unscoped).Preload("OfferedBook.Owner").Preload("Items", orderItems).Preload("Items.Book", unscoped).Preload("Items.Book.Owner").Where("requested_by_id = ?", userID).Find(&exchangeRequests).Error
 	return exchangeRequests, err
 }
 
 func (r *exchangeRepository) FindByRequestedToID(userID uuid.UUID) ([]entity.ExchangeRequest, error) {
 	var exchangeRequests []entity.ExchangeRequest
-	err := r.db.Preload("RequestedBy").Preload("RequestedTo").Preload("RequestedBook").Preload("RequestedBook.Owner").Preload("OfferedBook").Preload("OfferedBook.Owner").Where("requested_to_id = ?", userID).Find(&exchangeRequests).Error
+	err := r.db.Preload("RequestedBy").Preload("RequestedTo").Preload("RequestedBook", unscoped).Preload("RequestedBook.Owner").Preload("OfferedBook", unscoped).Preload("OfferedBook.Owner").Preload("Items", orderItems).Preload("Items.Book", unscoped).Preload("Items.Book.Owner").Where("requested_to_id = ?", userID).Find(&exchangeRequests).Error
 	return exchangeRequests, err
 }
 
 func (r *exchangeRepository) CanRequest(requestedByID, requestedToID uuid.UUID) (bool, error) {
 	var count int64
-	err := r.db.Model(&entity.ExchangeRequest{}).Where("requested_by_id = ? AND requested_to_id = ? AND status IN (?, ?)",
-		requestedByID, requestedToID, "pending", "accepted").
+	err := r.db.Model(&entity.ExchangeRequest{}).Where("trade_cycle_id IS NULL AND requested_by_id = ? AND requested_to_id = ? AND status IN (?, ?, ?, ?)",
+		requestedByID, requestedToID, "pending", "countered", "accepted", "disputed").
 		Count(&count).Error
 	if err != nil {
 		return false, err
@@ -75,10 +92,21 @@ func (r *exchangeRepository) FindPendingRequests(requestedByID, requestedToID uu
 
 func (r *exchangeRepository) FindPendingRequestsByBookID(bookID uint) ([]entity.ExchangeRequest, error) {
 	var requests []entity.ExchangeRequest
-	err := r.db.Where("(requested_book_id = ? OR offered_book_id = ?) AND status = ?", bookID, bookID, "pending").Find(&requests).Error
+	err := r.db.Preload("RequestedBook", unscoped).
+		Where(withBookSQL+" AND status IN ?", bookID, []string{"pending", "countered"}).Find(&requests).Error
 	return requests, err
 }
 
+// HasAcceptedRequestForBook reports whether an accepted exchange holds the
+// book, counting disputed ones, which hold it until an admin decides.
+func (r *exchangeRepository) HasAcceptedRequestForBook(bookID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.ExchangeRequest{}).
+		Where(withBookSQL+" AND status IN ?", bookID, []string{"accepted", "disputed"}).
+		Count(&count).Error
+	return count > 0, err
+}
+
 func (r *exchangeRepository) Update(exchangeRequest *entity.ExchangeRequest) error {
 	return r.db.Save(exchangeRequest).Error
 }
@@ -89,13 +117,160 @@ func (r *exchangeRepository) Delete(exchangeRequest *entity.ExchangeRequest) err
 
 func (r *exchangeRepository) FindRequestsByBookIDAndUserID(bookID uint, userID uuid.UUID) ([]entity.ExchangeRequest, error) {
 	var requests []entity.ExchangeRequest
-	err := r.db.Where("(requested_book_id = ? OR offered_book_id = ?) AND (requested_by_id = ? OR requested_to_id = ?)", bookID, bookID, userID, userID).Find(&requests).Error
+	err := r.db.Where(withBookSQL+" AND (requested_by_id = ? OR requested_to_id = ?)", bookID, userID, userID).Find(&requests).Error
 	return requests, err
 }
 
 func (r *exchangeRepository) FindRequestsByUserID(userID uuid.UUID) ([]entity.ExchangeRequest, error) {
 	var exchangeRequests []entity.ExchangeRequest
-	err := r.db.Preload("RequestedBy").Preload("RequestedTo").Preload("RequestedBook").Preload("RequestedBook.Owner").Preload("OfferedBook").Preload("OfferedBook.Owner").
+	err := r.db.Preload("RequestedBy").Preload("RequestedTo").Preload("RequestedBook", unscoped).Preload("RequestedBook.Owner").Preload("OfferedBook", unscoped).Preload("OfferedBook.Owner").Preload("Items", orderItems).Preload("Items.Book", unscoped).Preload("Items.Book.Owner").
 		Where("requested_by_id = ? OR requested_to_id = ?", userID, userID).Find(&exchangeRequests).Error
 	return exchangeRequests, err
 }
+
+// LockByID reloads the request's own columns with FOR UPDATE.
+func (r *exchangeRepository) LockByID(id uuid.UUID) (*entity.ExchangeRequest, error) {
+	var exchangeRequest entity.ExchangeRequest
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&exchangeRequest, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &exchangeRequest, nil
+}
+
+// FindPendingIdleSince returns pending or countered requests that were
+// made before since and not countered after it, oldest first. Legs of
+// trade cycles expire with their cycle instead.
+func (r *exchangeRepository) FindPendingIdleSince(since time.Time, limit int) ([]entity.ExchangeRequest, error) {
+	var requests []entity.ExchangeRequest
+	err := r.db.Preload("RequestedBook", unscoped).Preload("OfferedBook", unscoped).Preload("Items.Book", unscoped).
+		Where("trade_cycle_id IS NULL AND status IN ? AND created_at < ?", []entity.ExchangeStatus{entity.ExchangePending, entity.ExchangeCountered}, since).
+		Where("NOT EXISTS (SELECT 1 FROM exchange_offers WHERE exchange_offers.exchange_request_id = exchange_requests.id AND exchange_offers.created_at >= ?)", since).
+		Order("created_at").Limit(limit).Find(&requests).Error
+	return requests, err
+}
+
+// acceptedSinceSQL is when an accepted request started waiting for its
+// confirmations: when it was accepted, or when the meetup agreed for it
+// starts if that is later. Requests accepted before accepted_at existed
+// count from their creation.
+const acceptedSinceSQL = `GREATEST(COALESCE(accepted_at, created_at), (SELECT MAX(starts_at) FROM exchange_meetups
+	WHERE exchange_meetups.exchange_request_id = exchange_requests.id AND exchange_meetups.status = 'accepted'))`
+
+// FindAcceptedBefore returns accepted requests that have been waiting
+// since before the given time and are still not confirmed by both sides.
+// Legs of trade cycles lapse with their cycle instead.
+func (r *exchangeRepository) FindAcceptedBefore(before time.Time, limit int) ([]entity.ExchangeRequest, error) {
+	var requests []entity.ExchangeRequest
+	err := r.db.Preload("RequestedBook", unscoped).Preload("OfferedBook", unscoped).Preload("Items.Book", unscoped).
+		Where("trade_cycle_id IS NULL AND status = ? AND "+acceptedSinceSQL+" < ?", entity.ExchangeAccepted, before).
+		Order(acceptedSinceSQL).Limit(limit).Find(&requests).Error
+	return requests, err
+}
+
+// FindCyclesAcceptedBefore returns the trade cycles whose accepted legs
+// have all been waiting since before the given time, oldest first.
+func (r *exchangeRepository) FindCyclesAcceptedBefore(before time.Time, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&entity.ExchangeRequest{}).
+		Where("trade_cycle_id IS NOT NULL AND status = ?", entity.ExchangeAccepted).
+		Group("trade_cycle_id").Having("MAX("+acceptedSinceSQL+") < ?", before).
+		Order("MAX("+acceptedSinceSQL+")").Limit(limit).Pluck("trade_cycle_id", &ids).Error
+	return ids, err
+}
+
+// FindByTradeCycleID returns every leg of the trade cycle.
+func (r *exchangeRepository) FindByTradeCycleID(cycleID uuid.UUID) ([]entity.ExchangeRequest, error) {
+	var requests []entity.ExchangeRequest
+	err := r.db.Preload("RequestedBook", unscoped).Preload("OfferedBook", unscoped).Preload("Items.Book", unscoped).
+		Where("trade_cycle_id = ?", cycleID).Order("created_at, id").Find(&requests).Error
+	return requests, err
+}
+
+// FindUnremindedAcceptedBefore is FindAcceptedBefore limited to requests
+// whose sides were not reminded yet.
+func (r *exchangeRepository) FindUnremindedAcceptedBefore(before time.Time, limit int) ([]entity.ExchangeRequest, error) {
+	var requests []entity.ExchangeRequest
+	err := r.db.Preload("RequestedBook", unscoped).
+		Where("status = ? AND "+acceptedSinceSQL+" < ? AND last_reminder_at IS NULL", entity.ExchangeAccepted, before).
+		Order(acceptedSinceSQL).Limit(limit).Find(&requests).Error
+	return requests, err
+}
+
+// MarkReminded sets only last_reminder_at, so that it cannot overwrite a
+// state change made meanwhile.
+func (r *exchangeRepository) MarkReminded(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&entity.ExchangeRequest{}).Where("id = ?", id).Update("last_reminder_at", at).Error
+}
+
+func (r *exchangeRepository) FindItems(requestID uuid.UUID) ([]entity.ExchangeItem, error) {
+	var items []entity.ExchangeItem
+	err := r.db.Scopes(orderItems).Where("exchange_request_id = ?", requestID).Find(&items).Error
+	return items, err
+}
+
+// ReplaceItems swaps one side of the request's books for items.
+func (r *exchangeRepository) ReplaceItems(requestID uuid.UUID, side string, items []entity.ExchangeItem) error {
+	err := r.db.Where("exchange_request_id = ? AND side = ?", requestID, side).Delete(&entity.ExchangeItem{}).Error
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.Omit("Book").Create(&items).Error
+}
+
+// BackfillItems gives requests made before bundles existed the items
+// their single requested and offered book stand for.
+func (r *exchangeRepository) BackfillItems() error {
+	err := r.db.Exec(`INSERT INTO exchange_items (exchange_request_id, book_id, side, book_version)
+		SELECT id, requested_book_id, ?, requested_book_version FROM exchange_requests
+		WHERE NOT EXISTS (SELECT 1 FROM exchange_items WHERE exchange_items.exchange_request_id = exchange_requests.id)
+		ON CONFLICT DO NOTHING`, entity.ItemRequested).Error
+	if err != nil {
+		return err
+	}
+	return r.db.Exec(`INSERT INTO exchange_items (exchange_request_id, book_id, side, book_version)
+		SELECT id, offered_book_id, ?, offered_book_version FROM exchange_requests
+		WHERE offered_book_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM exchange_items WHERE exchange_items.exchange_request_id = exchange_requests.id AND side = ?)
+		ON CONFLICT DO NOTHING`, entity.ItemOffered, entity.ItemOffered).Error
+}
+
+// FindDisputed returns the requests waiting for an admin, oldest dispute
+// first.
+func (r *exchangeRepository) FindDisputed() ([]entity.ExchangeRequest, error) {
+	var requests []entity.ExchangeRequest
+	err := r.db.Preload("RequestedBy").Preload("RequestedTo").Preload("RequestedBook", unscoped).Preload("OfferedBook", unscoped).Preload("Items", orderItems).Preload("Items.Book", unscoped).
+		Where("status = ?", entity.ExchangeDisputed).
+		Order("(SELECT MAX(created_at) FROM exchange_events WHERE exchange_events.exchange_request_id = exchange_requests.id AND exchange_events.action IN ('dispute', 'escalate'))").
+		Find(&requests).Error
+	return requests, err
+}
+
+// FindBookIDsInOpenRequests returns those of bookIDs that are part of a
+// request still pending, countered, accepted or disputed.
+func (r *exchangeRepository) FindBookIDsInOpenRequests(bookIDs []uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&entity.ExchangeItem{}).Distinct("book_id").
+		Where("book_id IN ? AND exchange_request_id IN (SELECT id FROM exchange_requests WHERE status IN ?)", bookIDs, []string{"pending", "countered", "accepted", "disputed"}).
+		Pluck("book_id", &ids).Error
+	return ids, err
+}
+
+func (r *exchangeRepository) WithTx(tx *Tx) ExchangeRepository {
+	return &exchangeRepository{tx.db}
+}
+
+// withBookSQL matches requests that have the given book on either side.
+const withBookSQL = "id IN (SELECT exchange_request_id FROM exchange_items WHERE book_id = ?)"
+
+// orderItems lists requested books before offered ones.
+func orderItems(db *gorm.DB) *gorm.DB {
+	return db.Order("side DESC, book_id")
+}
+
+// unscoped keeps soft-deleted books visible inside exchange history.
+func unscoped(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
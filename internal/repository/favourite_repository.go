@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FavouriteRepository interface {
+	Add(favourite *entity.Favourite) error
+	Remove(userID uuid.UUID, bookID uint) (bool, error)
+	FindByUserID(userID uuid.UUID) ([]entity.Favourite, error)
+	FindUserIDsByBookID(bookID uint) ([]uuid.UUID, error)
+}
+
+type favouriteRepository struct {
+	db *gorm.DB
+}
+
+func NewFavouriteRepository(db *gorm.DB) FavouriteRepository {
+	return &favouriteRepository{db}
+}
+
+func (r *favouriteRepository) Add(favourite *entity.Favourite) error {
+	return r.db.Omit("Book").Clauses(clause.OnConflict{DoNothing: true}).Create(favourite).Error
+}
+
+func (r *favouriteRepository) Remove(userID uuid.UUID, bookID uint) (bool, error) {
+	result := r.db.Where("user_id = ? AND book_id = ?", userID, bookID).Delete(&entity.Favourite{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindByUserID returns the user's favourites whose books still exist.
+func (r *favouriteRepository) FindByUserID(userID uuid.UUID) ([]entity.Favourite, error) {
+	var favourites []entity.Favourite
+	err := r.db.Preload("Book").Preload("Book.Images", orderByPosition).
+		Where("user_id = ? AND book_id IN (SELECT id FROM books WHERE deleted_at IS NULL)", userID).
+		Order("created_at desc").Find(&favourites).Error
+	return favourites, err
+}
+
+func (r *favouriteRepository) FindUserIDsByBookID(bookID uint) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+	err := r.db.Model(&entity.Favourite{}).Where("book_id = ?", bookID).Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"github.com/arjnep/gyanpass/internal/entity"
+	"gorm.io/gorm"
+)
+
+// genreSubtreeSQL selects the ids of the genre an alias points at and of
+// every genre below it.
+const genreSubtreeSQL = "WITH RECURSIVE subtree AS (" +
+	"SELECT genre_id AS id FROM genre_aliases WHERE alias = ? " +
+	"UNION SELECT genres.id FROM genres JOIN subtree ON genres.parent_id = subtree.id" +
+	") SELECT id FROM subtree"
+
+type GenreRepository interface {
+	Create(genre *entity.Genre) error
+	FindByID(id uint) (*entity.Genre, error)
+	FindAll() ([]entity.Genre, error)
+	FindByAlias(alias string) (*entity.Genre, error)
+	AddAlias(alias *entity.GenreAlias) error
+	IsDescendant(genreID uint, ancestorID uint) (bool, error)
+	Merge(target *entity.Genre, source *entity.Genre) error
+}
+
+type genreRepository struct {
+	db *gorm.DB
+}
+
+func NewGenreRepository(db *gorm.DB) GenreRepository {
+	return &genreRepository{db}
+}
+
+func (r *genreRepository) Create(genre *entity.Genre) error {
+	return r.db.Create(genre).Error
+}
+
+func (r *genreRepository) FindByID(id uint) (*entity.Genre, error) {
+	var genre entity.Genre
+	err := r.db.Preload("Aliases").First(&genre, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &genre, nil
+}
+
+func (r *genreRepository) FindAll() ([]entity.Genre, error) {
+	var genres []entity.Genre
+	err := r.db.Preload("Aliases").Order("name").Find(&genres).Error
+	return genres, err
+}
+
+func (r *genreRepository) FindByAlias(alias string) (*entity.Genre, error) {
+	var genre entity.Genre
+	err := r.db.Joins("JOIN genre_aliases ON genre_aliases.genre_id = genres.id").
+		Where("genre_aliases.alias = ?", alias).First(&genre).Error
+	if err != nil {
+		return nil, err
+	}
+	return &genre, nil
+}
+
+func (r *genreRepository) AddAlias(alias *entity.GenreAlias) error {
+	return r.db.Create(alias).Error
+}
+
+func (r *genreRepository) IsDescendant(genreID uint, ancestorID uint) (bool, error) {
+	var count int64
+	err := r.db.Raw("WITH RECURSIVE subtree AS ("+
+		"SELECT id FROM genres WHERE parent_id = ? "+
+		"UNION SELECT genres.id FROM genres JOIN subtree ON genres.parent_id = subtree.id"+
+		") SELECT COUNT(*) FROM subtree WHERE id = ?", ancestorID, genreID).Scan(&count).Error
+	return count > 0, err
+}
+
+// Merge folds source into target: its books, child genres and aliases move
+// over, and source is deleted.
+func (r *genreRepository) Merge(target *entity.Genre, source *entity.Genre) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&entity.Book{}).Where("genre_id = ?", source.ID).
+			Updates(map[string]interface{}{"genre_id": target.ID, "genre": target.Name}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&entity.Genre{}).Where("parent_id = ?", source.ID).Update("parent_id", target.ID).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&entity.GenreAlias{}).Where("genre_id = ?", source.ID).Update("genre_id", target.ID).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(source).Error
+	})
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ImageRepository interface {
+	Create(image *entity.Image) error
+	FindByID(id uuid.UUID) (*entity.Image, error)
+	FindByIDs(ids []uuid.UUID) ([]entity.Image, error)
+	FindByBookID(bookID uint) ([]entity.Image, error)
+	SetBookImages(bookID uint, ids []uuid.UUID) error
+	SetMessageImages(messageID uuid.UUID, ids []uuid.UUID) error
+	Delete(image *entity.Image) error
+	WithTx(tx *Tx) ImageRepository
+}
+
+type imageRepository struct {
+	db *gorm.DB
+}
+
+func NewImageRepository(db *gorm.DB) ImageRepository {
+	return &imageRepository{db}
+}
+
+func (r *imageRepository) Create(image *entity.Image) error {
+	return r.db.Create(image).Error
+}
+
+func (r *imageRepository) FindByID(id uuid.UUID) (*entity.Image, error) {
+	var image entity.Image
+	err := r.db.First(&image, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+func (r *imageRepository) FindByIDs(ids []uuid.UUID) ([]entity.Image, error) {
+	var images []entity.Image
+	err := r.db.Where("id IN ?", ids).Find(&images).Error
+	return images, err
+}
+
+func (r *imageRepository) FindByBookID(bookID uint) ([]entity.Image, error) {
+	var images []entity.Image
+	err := r.db.Where("book_id = ?", bookID).Order("position").Find(&images).Error
+	return images, err
+}
+
+func (r *imageRepository) SetBookImages(bookID uint, ids []uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		detach := tx.Model(&entity.Image{}).Where("book_id = ?", bookID)
+		if len(ids) > 0 {
+			detach = detach.Where("id NOT IN ?", ids)
+		}
+		if err := detach.Updates(map[string]interface{}{"book_id": nil, "position": 0}).Error; err != nil {
+			return err
+		}
+		for position, id := range ids {
+			err := tx.Model(&entity.Image{}).Where("id = ?", id).
+				Updates(map[string]interface{}{"book_id": bookID, "position": position}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SetMessageImages attaches the images to a message in the given order.
+// Messages cannot be edited, so nothing is detached. It returns
+// gorm.ErrRecordNotFound when an image got attached elsewhere meanwhile.
+func (r *imageRepository) SetMessageImages(messageID uuid.UUID, ids []uuid.UUID) error {
+	for position, id := range ids {
+		result := r.db.Model(&entity.Image{}).Where("id = ? AND book_id IS NULL AND message_id IS NULL", id).
+			Updates(map[string]interface{}{"message_id": messageID, "position": position})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+	}
+	return nil
+}
+
+func (r *imageRepository) Delete(image *entity.Image) error {
+	return r.db.Delete(image).Error
+}
+
+func (r *imageRepository) WithTx(tx *Tx) ImageRepository {
+	return &imageRepository{tx.db}
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ImportJobRepository interface {
+	Create(job *entity.ImportJob) error
+	FindByID(id uuid.UUID) (*entity.ImportJob, error)
+	FindByUserID(userID uuid.UUID) ([]entity.ImportJob, error)
+	Update(job *entity.ImportJob) error
+	FailUnfinished(message string) (int64, error)
+}
+
+type importJobRepository struct {
+	db *gorm.DB
+}
+
+func NewImportJobRepository(db *gorm.DB) ImportJobRepository {
+	return &importJobRepository{db}
+}
+
+func (r *importJobRepository) Create(job *entity.ImportJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *importJobRepository) FindByID(id uuid.UUID) (*entity.ImportJob, error) {
+	var job entity.ImportJob
+	err := r.db.First(&job, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *importJobRepository) FindByUserID(userID uuid.UUID) ([]entity.ImportJob, error) {
+	var jobs []entity.ImportJob
+	err := r.db.Omit("errors").Where("user_id = ?", userID).Order("created_at desc").Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *importJobRepository) Update(job *entity.ImportJob) error {
+	return r.db.Save(job).Error
+}
+
+func (r *importJobRepository) FailUnfinished(message string) (int64, error) {
+	result := r.db.Model(&entity.ImportJob{}).
+		Where("status IN ?", []string{"queued", "running"}).
+		Updates(map[string]interface{}{"status": "failed", "message": message, "finished_at": time.Now()})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Loan statuses during which the book is out of its owner's hands or
+// promised to a borrower.
+var openLoanStatuses = []string{"approved", "handed_over", "due", "overdue"}
+
+type LoanRepository interface {
+	Create(loan *entity.Loan) error
+	FindByID(id uuid.UUID) (*entity.Loan, error)
+	FindByUserID(userID uuid.UUID) ([]entity.Loan, error)
+	LockByID(id uuid.UUID) (*entity.Loan, error)
+	Update(loan *entity.Loan, from []string, columns ...string) error
+	FindRequestedByBookID(bookID uint) ([]entity.Loan, error)
+	HasOpenLoanForBook(bookID uint) (bool, error)
+	HasRequest(borrowerID uuid.UUID, bookID uint) (bool, error)
+	FindDueBefore(before time.Time) ([]entity.Loan, error)
+	FindOverdue(now time.Time, remindedBefore time.Time) ([]entity.Loan, error)
+	WithTx(tx *Tx) LoanRepository
+}
+
+type loanRepository struct {
+	db *gorm.DB
+}
+
+func NewLoanRepository(db *gorm.DB) LoanRepository {
+	return &loanRepository{db}
+}
+
+func (r *loanRepository) Create(loan *entity.Loan) error {
+	return r.db.Create(loan).Error
+}
+
+func (r *loanRepository) FindByID(id uuid.UUID) (*entity.Loan, error) {
+	var loan entity.Loan
+	err := r.preload().First(&loan, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+func (r *loanRepository) FindByUserID(userID uuid.UUID) ([]entity.Loan, error) {
+	var loans []entity.Loan
+	err := r.preload().Where("lender_id = ? OR borrower_id = ?", userID, userID).
+		Order("created_at desc").Find(&loans).Error
+	return loans, err
+}
+
+// LockByID loads the loan alone with FOR UPDATE.
+func (r *loanRepository) LockByID(id uuid.UUID) (*entity.Loan, error) {
+	var loan entity.Loan
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&loan, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// Update writes only the given columns of the loan and only while its
+// status is still one of from, so that a stale copy cannot undo a change
+// made meanwhile. It returns gorm.ErrRecordNotFound when the loan moved
+// on.
+func (r *loanRepository) Update(loan *entity.Loan, from []string, columns ...string) error {
+	result := r.db.Model(loan).Where("status IN ?", from).Select(columns).Updates(loan)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *loanRepository) FindRequestedByBookID(bookID uint) ([]entity.Loan, error) {
+	var loans []entity.Loan
+	err := r.preload().Where("book_id = ? AND status = ?", bookID, "requested").Find(&loans).Error
+	return loans, err
+}
+
+func (r *loanRepository) HasOpenLoanForBook(bookID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.Loan{}).Where("book_id = ? AND status IN ?", bookID, openLoanStatuses).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *loanRepository) HasRequest(borrowerID uuid.UUID, bookID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.Loan{}).
+		Where("borrower_id = ? AND book_id = ? AND status IN ?", borrowerID, bookID, append([]string{"requested"}, openLoanStatuses...)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindDueBefore returns handed over loans whose due date falls before the
+// given time and that have not been reminded yet.
+func (r *loanRepository) FindDueBefore(before time.Time) ([]entity.Loan, error) {
+	var loans []entity.Loan
+	err := r.preload().Where("status = ? AND due_date <= ?", "handed_over", before).Find(&loans).Error
+	return loans, err
+}
+
+// FindOverdue returns loans past their due date that were not reminded
+// since remindedBefore.
+func (r *loanRepository) FindOverdue(now time.Time, remindedBefore time.Time) ([]entity.Loan, error) {
+	var loans []entity.Loan
+	err := r.preload().
+		Where("status IN ? AND due_date < ?", []string{"handed_over", "due", "overdue"}, now).
+		Where("last_reminder_at IS NULL OR last_reminder_at < ? OR status <> ?", remindedBefore, "overdue").
+		Find(&loans).Error
+	return loans, err
+}
+
+func (r *loanRepository) WithTx(tx *Tx) LoanRepository {
+	return &loanRepository{tx.db}
+}
+
+func (r *loanRepository) preload() *gorm.DB {
+	return r.db.Preload("Lender").Preload("Borrower").Preload("Book", unscoped).Preload("Book.Owner")
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RecommendationRepository interface {
+	FindUserIDs(after uuid.UUID, limit int) ([]uuid.UUID, error)
+	FindCandidates(userID uuid.UUID, lat, lng *float64, limit int) ([]entity.Book, error)
+	Replace(userID uuid.UUID, recommendations []entity.Recommendation) error
+	FindByUserID(userID uuid.UUID, page, size int) ([]entity.Recommendation, int, error)
+}
+
+type recommendationRepository struct {
+	db *gorm.DB
+}
+
+func NewRecommendationRepository(db *gorm.DB) RecommendationRepository {
+	return &recommendationRepository{db}
+}
+
+// FindUserIDs pages through every user in uid order, starting after the
+// given uid.
+func (r *recommendationRepository) FindUserIDs(after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+	err := r.db.Model(&entity.User{}).Where("uid > ?", after).Order("uid").Limit(limit).Pluck("uid", &userIDs).Error
+	return userIDs, err
+}
+
+// FindCandidates returns active books the user could still ask for: not
+// their own, not already favourited and not in one of their open requests.
+// Nearby books come first when a location is known, newest otherwise.
+func (r *recommendationRepository) FindCandidates(userID uuid.UUID, lat, lng *float64, limit int) ([]entity.Book, error) {
+	var books []entity.Book
+	query := r.db.Preload("Tags").
+		Where("is_active AND user_id <> ?", userID).
+		Where("id NOT IN (SELECT book_id FROM favourites WHERE user_id = ?)", userID).
+		Where("id NOT IN (SELECT exchange_items.book_id FROM exchange_items JOIN exchange_requests ON exchange_requests.id = exchange_items.exchange_request_id WHERE exchange_items.side = ? AND exchange_requests.requested_by_id = ? AND exchange_requests.status IN ?)", entity.ItemRequested, userID, []string{"pending", "countered", "accepted", "disputed"})
+	if lat != nil && lng != nil {
+		query = query.Order(gorm.Expr(distanceExpr, distanceArgs(*lat, *lng)...))
+	} else {
+		query = query.Order("id desc")
+	}
+	err := query.Limit(limit).Find(&books).Error
+	return books, err
+}
+
+func (r *recommendationRepository) Replace(userID uuid.UUID, recommendations []entity.Recommendation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.Recommendation{}).Error; err != nil {
+			return err
+		}
+		if len(recommendations) == 0 {
+			return nil
+		}
+		return tx.Omit("Book").Create(&recommendations).Error
+	})
+}
+
+// FindByUserID pages through the user's stored recommendations, skipping
+// books that stopped being available since the job last ran.
+func (r *recommendationRepository) FindByUserID(userID uuid.UUID, page, size int) ([]entity.Recommendation, int, error) {
+	var recommendations []entity.Recommendation
+	var total int64
+
+	query := r.db.Model(&entity.Recommendation{}).
+		Where("user_id = ? AND book_id IN (SELECT id FROM books WHERE is_active AND deleted_at IS NULL)", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Book").Preload("Book.Images", orderByPosition).Preload("Book.Tags").
+		Order("score desc").Limit(size).Offset((page - 1) * size).Find(&recommendations).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return recommendations, int(total), nil
+}
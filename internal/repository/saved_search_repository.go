@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SavedSearchRepository interface {
+	Create(search *entity.SavedSearch) error
+	FindByID(id uuid.UUID) (*entity.SavedSearch, error)
+	FindByUserID(userID uuid.UUID) ([]entity.SavedSearch, error)
+	CountByUserID(userID uuid.UUID) (int, error)
+	FindDue(now time.Time, after *entity.SavedSearch, limit int) ([]entity.SavedSearch, error)
+	Claim(search *entity.SavedSearch, nextRunAt time.Time) (bool, error)
+	Update(search *entity.SavedSearch, updates map[string]interface{}) error
+	Delete(search *entity.SavedSearch) error
+	FindNewBooks(search *entity.SavedSearch, since time.Time, until time.Time, limit int) ([]entity.Book, int, error)
+}
+
+type savedSearchRepository struct {
+	db *gorm.DB
+}
+
+func NewSavedSearchRepository(db *gorm.DB) SavedSearchRepository {
+	return &savedSearchRepository{db}
+}
+
+func (r *savedSearchRepository) Create(search *entity.SavedSearch) error {
+	return r.db.Create(search).Error
+}
+
+func (r *savedSearchRepository) FindByID(id uuid.UUID) (*entity.SavedSearch, error) {
+	var search entity.SavedSearch
+	err := r.db.First(&search, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+func (r *savedSearchRepository) FindByUserID(userID uuid.UUID) ([]entity.SavedSearch, error) {
+	var searches []entity.SavedSearch
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").Find(&searches).Error
+	return searches, err
+}
+
+func (r *savedSearchRepository) CountByUserID(userID uuid.UUID) (int, error) {
+	var count int64
+	err := r.db.Model(&entity.SavedSearch{}).Where("user_id = ?", userID).Count(&count).Error
+	return int(count), err
+}
+
+// FindDue pages through the searches due by now, in order of their next
+// run and then id, starting after the given search when there is one.
+func (r *savedSearchRepository) FindDue(now time.Time, after *entity.SavedSearch, limit int) ([]entity.SavedSearch, error) {
+	var searches []entity.SavedSearch
+	query := r.db.Where("next_run_at <= ?", now)
+	if after != nil {
+		query = query.Where("(next_run_at, id) > (?, ?)", after.NextRunAt, after.ID)
+	}
+	err := query.Order("next_run_at, id").Limit(limit).Find(&searches).Error
+	return searches, err
+}
+
+// Claim moves the search's next run forward only if no other instance has
+// done so since it was loaded, so each digest is sent once.
+func (r *savedSearchRepository) Claim(search *entity.SavedSearch, nextRunAt time.Time) (bool, error) {
+	result := r.db.Model(&entity.SavedSearch{}).
+		Where("id = ? AND next_run_at = ?", search.ID, search.NextRunAt).
+		Update("next_run_at", nextRunAt)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *savedSearchRepository) Update(search *entity.SavedSearch, updates map[string]interface{}) error {
+	return r.db.Model(search).Updates(updates).Error
+}
+
+func (r *savedSearchRepository) Delete(search *entity.SavedSearch) error {
+	return r.db.Delete(search).Error
+}
+
+// FindNewBooks returns active books matching the search that went on the
+// shelf after since and up to until, along with their total count.
+func (r *savedSearchRepository) FindNewBooks(search *entity.SavedSearch, since time.Time, until time.Time, limit int) ([]entity.Book, int, error) {
+	var books []entity.Book
+	var total int64
+
+	query := applyBookFilters(r.db.Model(&entity.Book{}), search.Filters).
+		Where("books.activated_at > ? AND books.activated_at <= ?", since, until).
+		Where("books.user_id <> ? AND books.is_active = ?", search.UserID, true)
+	if search.RadiusKm > 0 && search.Latitude != nil && search.Longitude != nil {
+		query = query.Where(distanceExpr+" <= ?", append(distanceArgs(*search.Latitude, *search.Longitude), search.RadiusKm)...)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("books.activated_at").Limit(limit).Find(&books).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return books, int(total), nil
+}
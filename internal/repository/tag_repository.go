@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"github.com/arjnep/gyanpass/internal/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository interface {
+	FindOrCreate(names []string) ([]entity.Tag, error)
+	FindByID(id uint) (*entity.Tag, error)
+	Search(prefix string, limit int) ([]entity.TagCount, error)
+	SetBookTags(book *entity.Book, tags []entity.Tag) error
+	Merge(target *entity.Tag, source *entity.Tag) error
+	WithTx(tx *Tx) TagRepository
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db}
+}
+
+func (r *tagRepository) FindOrCreate(names []string) ([]entity.Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	tags := make([]entity.Tag, len(names))
+	for i, name := range names {
+		tags[i] = entity.Tag{Name: name}
+	}
+	err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var found []entity.Tag
+	err = r.db.Where("name IN ?", names).Find(&found).Error
+	return found, err
+}
+
+func (r *tagRepository) FindByID(id uint) (*entity.Tag, error) {
+	var tag entity.Tag
+	err := r.db.First(&tag, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) Search(prefix string, limit int) ([]entity.TagCount, error) {
+	var tags []entity.TagCount
+	query := r.db.Model(&entity.Tag{}).
+		Select("tags.id, tags.name, COUNT(books.id) AS books").
+		Joins("LEFT JOIN book_tags ON book_tags.tag_id = tags.id").
+		Joins("LEFT JOIN books ON books.id = book_tags.book_id AND books.deleted_at IS NULL").
+		Group("tags.id, tags.name")
+	if prefix != "" {
+		query = query.Where("tags.name LIKE ?", prefix+"%")
+	}
+	err := query.Order("books DESC, tags.name").Limit(limit).Scan(&tags).Error
+	return tags, err
+}
+
+func (r *tagRepository) SetBookTags(book *entity.Book, tags []entity.Tag) error {
+	return r.db.Model(book).Association("Tags").Replace(tags)
+}
+
+// Merge moves every book tagged with source over to target and deletes
+// source.
+func (r *tagRepository) Merge(target *entity.Tag, source *entity.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("INSERT INTO book_tags (book_id, tag_id) SELECT book_id, ? FROM book_tags WHERE tag_id = ? ON CONFLICT DO NOTHING",
+			target.ID, source.ID).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Exec("DELETE FROM book_tags WHERE tag_id = ?", source.ID).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(source).Error
+	})
+}
+
+func (r *tagRepository) WithTx(tx *Tx) TagRepository {
+	return &tagRepository{tx.db}
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TradeCycleRepository interface {
+	Create(cycle *entity.TradeCycle) error
+	FindByID(id uuid.UUID) (*entity.TradeCycle, error)
+	FindByUserID(userID uuid.UUID) ([]entity.TradeCycle, error)
+	FindProposedBefore(before time.Time, limit int) ([]entity.TradeCycle, error)
+	ExistsBySignature(signature string) (bool, error)
+	LockByID(id uuid.UUID) (*entity.TradeCycle, error)
+	UpdateStatus(cycle *entity.TradeCycle, status string) error
+	AcceptParticipant(cycleID, userID uuid.UUID, at time.Time) error
+	WithTx(tx *Tx) TradeCycleRepository
+}
+
+type tradeCycleRepository struct {
+	db *gorm.DB
+}
+
+func NewTradeCycleRepository(db *gorm.DB) TradeCycleRepository {
+	return &tradeCycleRepository{db}
+}
+
+// Create inserts the cycle with its participants. Legs are created on
+// their own, as exchange requests.
+func (r *tradeCycleRepository) Create(cycle *entity.TradeCycle) error {
+	return r.db.Omit("Legs", "Participants.User").Create(cycle).Error
+}
+
+func (r *tradeCycleRepository) FindByID(id uuid.UUID) (*entity.TradeCycle, error) {
+	var cycle entity.TradeCycle
+	err := r.preload().First(&cycle, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cycle, nil
+}
+
+func (r *tradeCycleRepository) FindByUserID(userID uuid.UUID) ([]entity.TradeCycle, error) {
+	var cycles []entity.TradeCycle
+	err := r.preload().
+		Where("id IN (SELECT trade_cycle_id FROM trade_cycle_participants WHERE user_id = ?)", userID).
+		Order("created_at desc").Find(&cycles).Error
+	return cycles, err
+}
+
+// FindProposedBefore returns cycles still waiting on someone since before.
+func (r *tradeCycleRepository) FindProposedBefore(before time.Time, limit int) ([]entity.TradeCycle, error) {
+	var cycles []entity.TradeCycle
+	err := r.preload().Where("status = ? AND created_at < ?", entity.CycleProposed, before).
+		Order("created_at").Limit(limit).Find(&cycles).Error
+	return cycles, err
+}
+
+func (r *tradeCycleRepository) ExistsBySignature(signature string) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.TradeCycle{}).Where("signature = ?", signature).Count(&count).Error
+	return count > 0, err
+}
+
+// LockByID reloads the cycle's own columns with FOR UPDATE, and its
+// participants, which only change while the cycle is locked.
+func (r *tradeCycleRepository) LockByID(id uuid.UUID) (*entity.TradeCycle, error) {
+	var cycle entity.TradeCycle
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Participants").First(&cycle, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cycle, nil
+}
+
+func (r *tradeCycleRepository) UpdateStatus(cycle *entity.TradeCycle, status string) error {
+	err := r.db.Model(cycle).Update("status", status).Error
+	if err != nil {
+		return err
+	}
+	cycle.Status = status
+	return nil
+}
+
+func (r *tradeCycleRepository) AcceptParticipant(cycleID, userID uuid.UUID, at time.Time) error {
+	return r.db.Model(&entity.TradeCycleParticipant{}).
+		Where("trade_cycle_id = ? AND user_id = ?", cycleID, userID).
+		Updates(map[string]interface{}{"accepted": true, "responded_at": at}).Error
+}
+
+func (r *tradeCycleRepository) WithTx(tx *Tx) TradeCycleRepository {
+	return &tradeCycleRepository{tx.db}
+}
+
+func (r *tradeCycleRepository) preload() *gorm.DB {
+	return r.db.Preload("Participants").Preload("Participants.User").
+		Preload("Legs").Preload("Legs.RequestedBy").Preload("Legs.RequestedTo").
+		Preload("Legs.RequestedBook", unscoped).Preload("Legs.RequestedBook.Owner").
+		Preload("Legs.Items", orderItems).Preload("Legs.Items.Book", unscoped).Preload("Legs.Items.Book.Owner")
+}
@@ -0,0 +1,46 @@
+package repository
+
+import "gorm.io/gorm"
+
+// Tx is one database transaction shared by the repositories bound to it
+// with their WithTx methods.
+type Tx struct {
+	db          *gorm.DB
+	afterCommit []func()
+}
+
+// AfterCommit queues fn to run once the transaction has committed. Nothing
+// queued runs when it rolls back.
+func (tx *Tx) AfterCommit(fn func()) {
+	tx.afterCommit = append(tx.afterCommit, fn)
+}
+
+type Transactor interface {
+	Run(fn func(tx *Tx) error) error
+}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db}
+}
+
+// Run executes fn in a transaction, committing when it returns nil and
+// rolling back otherwise.
+func (t *transactor) Run(fn func(tx *Tx) error) error {
+	var tx *Tx
+	err := t.db.Transaction(func(db *gorm.DB) error {
+		tx = &Tx{db: db}
+		return fn(tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, callback := range tx.afterCommit {
+		callback()
+	}
+	return nil
+}
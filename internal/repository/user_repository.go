@@ -13,6 +13,8 @@ type UserRepository interface {
 	FindByID(id uuid.UUID) (*entity.User, error)
 	Update(*entity.User, map[string]interface{}) error
 	Delete(*entity.User) error
+	IncrementCancellationCount(id uuid.UUID) error
+	WithTx(tx *Tx) UserRepository
 }
 
 type userRepository struct {
@@ -52,3 +54,12 @@ func (r *userRepository) Update(user *entity.User, updates map[string]interface{
 func (r *userRepository) Delete(user *entity.User) error {
 	return r.db.Delete(user).Error
 }
+
+func (r *userRepository) IncrementCancellationCount(id uuid.UUID) error {
+	return r.db.Model(&entity.User{}).Where("uid = ?", id).
+		UpdateColumn("cancellation_count", gorm.Expr("cancellation_count + 1")).Error
+}
+
+func (r *userRepository) WithTx(tx *Tx) UserRepository {
+	return &userRepository{tx.db}
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var wantedDistanceExpr = distanceExprFor("wanted_posts")
+
+type WantedRepository interface {
+	Create(post *entity.WantedPost) error
+	FindByID(id uuid.UUID) (*entity.WantedPost, error)
+	FindByUserID(userID uuid.UUID) ([]entity.WantedPost, error)
+	Search(queryParams map[string]string, location *entity.Location, radiusKm float64, page, size int) ([]entity.WantedPost, int, error)
+	FindMatchingBook(book *entity.Book, limit int) ([]entity.WantedPost, error)
+	MatchesBook(postID uuid.UUID, book *entity.Book) (bool, error)
+	Update(post *entity.WantedPost, updates map[string]interface{}) error
+}
+
+type wantedRepository struct {
+	db *gorm.DB
+}
+
+func NewWantedRepository(db *gorm.DB) WantedRepository {
+	return &wantedRepository{db}
+}
+
+func (r *wantedRepository) Create(post *entity.WantedPost) error {
+	return r.db.Create(post).Error
+}
+
+func (r *wantedRepository) FindByID(id uuid.UUID) (*entity.WantedPost, error) {
+	var post entity.WantedPost
+	err := r.db.Preload("User").First(&post, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+func (r *wantedRepository) FindByUserID(userID uuid.UUID) ([]entity.WantedPost, error) {
+	var posts []entity.WantedPost
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").Find(&posts).Error
+	return posts, err
+}
+
+func (r *wantedRepository) Search(queryParams map[string]string, location *entity.Location, radiusKm float64, page, size int) ([]entity.WantedPost, int, error) {
+	var posts []entity.WantedPost
+	var total int64
+
+	query := r.db.Model(&entity.WantedPost{}).Where("status = ?", "open")
+	for key, value := range queryParams {
+		if value != "" {
+			switch key {
+			case "title":
+				query = query.Where("title ILIKE ?", "%"+value+"%")
+			case "author":
+				query = query.Where("author ILIKE ?", "%"+value+"%")
+			case "address":
+				query = query.Where("address ILIKE ?", "%"+value+"%")
+			}
+		}
+	}
+	if location != nil && radiusKm > 0 {
+		query = query.Where(wantedDistanceExpr+" <= ?", append(distanceArgs(location.Latitude, location.Longitude), radiusKm)...)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	err := query.Order("created_at desc").Limit(size).Offset(offset).Find(&posts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return posts, int(total), nil
+}
+
+// matchingBook narrows to open posts by other users whose title and, when
+// given, author fit the book: the reverse of searching books for a post.
+func matchingBook(book *entity.Book) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("status = ? AND user_id <> ?", "open", book.UserID).
+			Where("strpos(lower(?), lower(title)) > 0 OR strpos(lower(title), lower(?)) > 0", book.Title, book.Title).
+			Where("author = '' OR strpos(lower(?), lower(author)) > 0", book.Author)
+	}
+}
+
+// FindMatchingBook returns the open posts the book could satisfy, newest
+// first.
+func (r *wantedRepository) FindMatchingBook(book *entity.Book, limit int) ([]entity.WantedPost, error) {
+	var posts []entity.WantedPost
+	err := r.db.Scopes(matchingBook(book)).
+		Order("created_at desc").Limit(limit).Find(&posts).Error
+	return posts, err
+}
+
+// MatchesBook reports whether the book could satisfy the post, by the same
+// rules as FindMatchingBook.
+func (r *wantedRepository) MatchesBook(postID uuid.UUID, book *entity.Book) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.WantedPost{}).Scopes(matchingBook(book)).
+		Where("id = ?", postID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *wantedRepository) Update(post *entity.WantedPost, updates map[string]interface{}) error {
+	return r.db.Model(post).Updates(updates).Error
+}
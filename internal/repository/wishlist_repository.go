@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WishlistRepository interface {
+	Create(item *entity.WishlistItem) error
+	FindByID(id uuid.UUID) (*entity.WishlistItem, error)
+	FindByUserID(userID uuid.UUID) ([]entity.WishlistItem, error)
+	FindAfter(afterID uuid.UUID, limit int) ([]entity.WishlistItem, error)
+	CountByUserID(userID uuid.UUID) (int, error)
+	Delete(item *entity.WishlistItem) error
+	FindCandidatesForBook(book *entity.Book) ([]entity.WishlistItem, error)
+	FindMatchingBooks(item *entity.WishlistItem, limit int) ([]entity.Book, error)
+	RecordMatch(match *entity.WishlistMatch) (bool, error)
+	AddPendingMatch(bookID uint) error
+	FindPendingMatches(limit int) ([]entity.PendingBookMatch, error)
+	DeletePendingMatch(bookID uint) error
+}
+
+type wishlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository(db *gorm.DB) WishlistRepository {
+	return &wishlistRepository{db}
+}
+
+func (r *wishlistRepository) Create(item *entity.WishlistItem) error {
+	return r.db.Create(item).Error
+}
+
+func (r *wishlistRepository) FindByID(id uuid.UUID) (*entity.WishlistItem, error) {
+	var item entity.WishlistItem
+	err := r.db.First(&item, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (r *wishlistRepository) FindByUserID(userID uuid.UUID) ([]entity.WishlistItem, error) {
+	var items []entity.WishlistItem
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").Find(&items).Error
+	return items, err
+}
+
+// FindAfter pages through every wishlist item by id.
+func (r *wishlistRepository) FindAfter(afterID uuid.UUID, limit int) ([]entity.WishlistItem, error) {
+	var items []entity.WishlistItem
+	err := r.db.Where("id > ?", afterID).Order("id").Limit(limit).Find(&items).Error
+	return items, err
+}
+
+func (r *wishlistRepository) CountByUserID(userID uuid.UUID) (int, error) {
+	var count int64
+	err := r.db.Model(&entity.WishlistItem{}).Where("user_id = ?", userID).Count(&count).Error
+	return int(count), err
+}
+
+func (r *wishlistRepository) Delete(item *entity.WishlistItem) error {
+	return r.db.Delete(item).Error
+}
+
+// FindCandidatesForBook narrows wishlist items down to those whose ISBN,
+// title or author could match the book. Radius and combined title/author
+// checks are left to the caller.
+func (r *wishlistRepository) FindCandidatesForBook(book *entity.Book) ([]entity.WishlistItem, error) {
+	var items []entity.WishlistItem
+	err := r.db.Where("user_id <> ?", book.UserID).
+		Where(r.db.Where("isbn <> '' AND isbn = ?", book.ISBN).
+			Or("title <> '' AND strpos(lower(?), lower(title)) > 0", book.Title).
+			Or("title = '' AND isbn = '' AND author <> '' AND strpos(lower(?), lower(author)) > 0", book.Author)).
+		Find(&items).Error
+	return items, err
+}
+
+func (r *wishlistRepository) FindMatchingBooks(item *entity.WishlistItem, limit int) ([]entity.Book, error) {
+	var books []entity.Book
+	query := r.db.Preload("Images", orderByPosition).
+		Where("books.user_id <> ? AND books.is_active = ?", item.UserID, true)
+
+	match := r.db.Where("1 = 0")
+	if item.ISBN != "" {
+		match = match.Or("books.isbn = ?", item.ISBN)
+	}
+	if item.Title != "" && item.Author != "" {
+		match = match.Or("strpos(lower(books.title), lower(?)) > 0 AND strpos(lower(books.author), lower(?)) > 0", item.Title, item.Author)
+	} else if item.Title != "" {
+		match = match.Or("strpos(lower(books.title), lower(?)) > 0", item.Title)
+	} else if item.Author != "" && item.ISBN == "" {
+		match = match.Or("strpos(lower(books.author), lower(?)) > 0", item.Author)
+	}
+	query = query.Where(match)
+
+	if item.RadiusKm > 0 && item.Latitude != nil && item.Longitude != nil {
+		query = query.Where(distanceExpr+" <= ?", append(distanceArgs(*item.Latitude, *item.Longitude), item.RadiusKm)...)
+	}
+
+	err := query.Order("books.id desc").Limit(limit).Find(&books).Error
+	return books, err
+}
+
+func (r *wishlistRepository) RecordMatch(match *entity.WishlistMatch) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(match)
+	return result.RowsAffected == 1, result.Error
+}
+
+// AddPendingMatch keeps a book for a later wishlist check; a book already
+// waiting is kept once.
+func (r *wishlistRepository) AddPendingMatch(bookID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.PendingBookMatch{BookID: bookID}).Error
+}
+
+// FindPendingMatches returns the books waiting longest first.
+func (r *wishlistRepository) FindPendingMatches(limit int) ([]entity.PendingBookMatch, error) {
+	var matches []entity.PendingBookMatch
+	err := r.db.Order("created_at").Limit(limit).Find(&matches).Error
+	return matches, err
+}
+
+func (r *wishlistRepository) DeletePendingMatch(bookID uint) error {
+	return r.db.Delete(&entity.PendingBookMatch{}, "book_id = ?", bookID).Error
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"gorm.io/gorm"
+)
+
+type WorkRepository interface {
+	Create(work *entity.Work) error
+	FindByID(id uint) (*entity.Work, error)
+	FindByISBN(isbn string) (*entity.Work, error)
+	FindByDedupKey(key string) (*entity.Work, error)
+	FindByAuthorKey(key string) ([]entity.Work, error)
+	Search(title string, page, size int) ([]entity.Work, int, error)
+	Update(work *entity.Work) error
+	Merge(target *entity.Work, source *entity.Work) error
+	CountCopies(workID uint) (int, int, error)
+	FindNearbyCopies(workID uint, lat, lng, radiusKm float64, limit int) ([]entity.NearbyCopy, error)
+	FindBooksWithoutWork(limit int) ([]entity.Book, error)
+	AssignWork(bookID uint, workID uint) error
+}
+
+type workRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkRepository(db *gorm.DB) WorkRepository {
+	return &workRepository{db}
+}
+
+func (r *workRepository) Create(work *entity.Work) error {
+	return r.db.Create(work).Error
+}
+
+func (r *workRepository) FindByID(id uint) (*entity.Work, error) {
+	var work entity.Work
+	err := r.db.First(&work, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &work, nil
+}
+
+func (r *workRepository) FindByISBN(isbn string) (*entity.Work, error) {
+	needle, err := json.Marshal([]string{isbn})
+	if err != nil {
+		return nil, err
+	}
+	var work entity.Work
+	err = r.db.Where("isbns @> ?", string(needle)).Order("id").First(&work).Error
+	if err != nil {
+		return nil, err
+	}
+	return &work, nil
+}
+
+func (r *workRepository) FindByDedupKey(key string) (*entity.Work, error) {
+	var work entity.Work
+	err := r.db.Where("dedup_key = ?", key).Order("id").First(&work).Error
+	if err != nil {
+		return nil, err
+	}
+	return &work, nil
+}
+
+func (r *workRepository) FindByAuthorKey(key string) ([]entity.Work, error) {
+	var works []entity.Work
+	err := r.db.Where("author_key = ?", key).Order("id").Find(&works).Error
+	return works, err
+}
+
+func (r *workRepository) Search(title string, page, size int) ([]entity.Work, int, error) {
+	var works []entity.Work
+	var total int64
+
+	query := r.db.Model(&entity.Work{})
+	if title != "" {
+		query = query.Where("title ILIKE ?", "%"+title+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * size
+	if err := query.Order("title").Limit(size).Offset(offset).Find(&works).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return works, int(total), nil
+}
+
+func (r *workRepository) Update(work *entity.Work) error {
+	return r.db.Save(work).Error
+}
+
+func (r *workRepository) Merge(target *entity.Work, source *entity.Work) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&entity.Book{}).Where("work_id = ?", source.ID).Update("work_id", target.ID).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Save(target).Error; err != nil {
+			return err
+		}
+		return tx.Delete(source).Error
+	})
+}
+
+func (r *workRepository) CountCopies(workID uint) (int, int, error) {
+	var counts struct {
+		Copies    int
+		Available int
+	}
+	err := r.db.Model(&entity.Book{}).
+		Select("COUNT(*) AS copies, COUNT(*) FILTER (WHERE is_active) AS available").
+		Where("work_id = ?", workID).
+		Scan(&counts).Error
+	return counts.Copies, counts.Available, err
+}
+
+func (r *workRepository) FindNearbyCopies(workID uint, lat, lng, radiusKm float64, limit int) ([]entity.NearbyCopy, error) {
+	var copies []entity.NearbyCopy
+	args := distanceArgs(lat, lng)
+	err := r.db.Table("books").
+		Select("books.id AS book_id, books.condition, books.address, users.first_name AS owner_name, "+distanceExpr+" AS distance_km", args...).
+		Joins("JOIN users ON users.uid = books.user_id").
+		Where("books.work_id = ? AND books.is_active = ? AND books.deleted_at IS NULL", workID, true).
+		Where(distanceExpr+" <= ?", append(args, radiusKm)...).
+		Order("distance_km").
+		Limit(limit).
+		Scan(&copies).Error
+	return copies, err
+}
+
+func (r *workRepository) FindBooksWithoutWork(limit int) ([]entity.Book, error) {
+	var books []entity.Book
+	err := r.db.Where("work_id IS NULL").Order("id").Limit(limit).Find(&books).Error
+	return books, err
+}
+
+func (r *workRepository) AssignWork(bookID uint, workID uint) error {
+	return r.db.Model(&entity.Book{}).Where("id = ?", bookID).Update("work_id", workID).Error
+}
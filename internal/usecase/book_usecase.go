@@ -1,34 +1,183 @@
 package usecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/arjnep/gyanpass/internal/entity"
 	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/geo"
+	"github.com/arjnep/gyanpass/pkg/metadata"
+	"github.com/arjnep/gyanpass/pkg/notification"
 	"github.com/arjnep/gyanpass/pkg/response"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+const (
+	maxBookImages     = 8
+	metadataTimeout   = 5 * time.Second
+	backfillBatchSize = 500
+)
+
 type BookUsecase interface {
-	AddBook(book *entity.Book) error
+	AddBook(book *entity.Book, imageIDs []uuid.UUID) error
+	ValidateBook(book *entity.Book, imageIDs []uuid.UUID) error
 	GetBookByID(id uint) (*entity.Book, error)
 	GetBooksByUserID(uid uuid.UUID) ([]entity.Book, error)
+	GetArchivedBooksByUserID(uid uuid.UUID) ([]entity.Book, error)
 	SearchBooks(queryParams map[string]string, page, size int) ([]entity.Book, int, error)
 	UpdateBook(book *entity.Book, updates map[string]interface{}) error
 	DeleteBook(book *entity.Book) error
+	SetBookImages(book *entity.Book, imageIDs []uuid.UUID) error
+	SetBookTags(book *entity.Book, names []string) error
+	GetBookHistory(book *entity.Book, userID uuid.UUID, sinceVersion int) ([]entity.BookVersion, error)
+	LookupISBN(isbn string) (*metadata.Metadata, error)
+	BackfillPublicLocations() error
 }
 
 type bookUsecase struct {
-	bookRepo repository.BookRepository
+	bookRepo            repository.BookRepository
+	imageRepo           repository.ImageRepository
+	workRepo            repository.WorkRepository
+	exchangeRepo        repository.ExchangeRepository
+	exchangeEventRepo   repository.ExchangeEventRepository
+	loanRepo            repository.LoanRepository
+	bookVersionRepo     repository.BookVersionRepository
+	genreRepo           repository.GenreRepository
+	tagRepo             repository.TagRepository
+	metadataProvider    metadata.MetadataProvider
+	wishlistUsecase     WishlistUsecase
+	transactor          repository.Transactor
+	notificationService notification.Service
 }
 
-func NewBookUsecase(bookRepo repository.BookRepository) BookUsecase {
-	return &bookUsecase{bookRepo}
+// BookUsecaseConfig holds what NewBookUsecase is built from.
+type BookUsecaseConfig struct {
+	BookRepo            repository.BookRepository
+	ImageRepo           repository.ImageRepository
+	WorkRepo            repository.WorkRepository
+	ExchangeRepo        repository.ExchangeRepository
+	ExchangeEventRepo   repository.ExchangeEventRepository
+	LoanRepo            repository.LoanRepository
+	BookVersionRepo     repository.BookVersionRepository
+	GenreRepo           repository.GenreRepository
+	TagRepo             repository.TagRepository
+	MetadataProvider    metadata.MetadataProvider
+	WishlistUsecase     WishlistUsecase
+	Transactor          repository.Transactor
+	NotificationService notification.Service
 }
 
-func (u *bookUsecase) AddBook(book *entity.Book) error {
-	return u.bookRepo.Create(book)
+func NewBookUsecase(c *BookUsecaseConfig) BookUsecase {
+	return &bookUsecase{
+		bookRepo:            c.BookRepo,
+		imageRepo:           c.ImageRepo,
+		workRepo:            c.WorkRepo,
+		exchangeRepo:        c.ExchangeRepo,
+		exchangeEventRepo:   c.ExchangeEventRepo,
+		loanRepo:            c.LoanRepo,
+		bookVersionRepo:     c.BookVersionRepo,
+		genreRepo:           c.GenreRepo,
+		tagRepo:             c.TagRepo,
+		metadataProvider:    c.MetadataProvider,
+		wishlistUsecase:     c.WishlistUsecase,
+		transactor:          c.Transactor,
+		notificationService: c.NotificationService,
+	}
+}
+
+func (u *bookUsecase) AddBook(book *entity.Book, imageIDs []uuid.UUID) error {
+	images, err := u.prepareBook(book, imageIDs)
+	if err != nil {
+		return err
+	}
+
+	if len(book.Tags) > 0 {
+		names := make([]string, len(book.Tags))
+		for i, tag := range book.Tags {
+			names[i] = tag.Name
+		}
+		book.Tags, err = u.tagRepo.FindOrCreate(names)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+	}
+
+	err = u.bookRepo.Create(book)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+
+	if len(images) > 0 {
+		err = u.imageRepo.SetBookImages(book.ID, imageIDs)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		book.Images = images
+	}
+
+	u.linkWork(book)
+	u.wishlistUsecase.QueueBookMatch(book.ID)
+	return nil
+}
+
+func (u *bookUsecase) ValidateBook(book *entity.Book, imageIDs []uuid.UUID) error {
+	_, err := u.prepareBook(book, imageIDs)
+	return err
+}
+
+// prepareBook runs every check AddBook does before inserting, filling in
+// metadata for the ISBN and the cover from the attached images.
+func (u *bookUsecase) prepareBook(book *entity.Book, imageIDs []uuid.UUID) ([]entity.Image, error) {
+	images, err := u.findAttachableImages(book, imageIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(images) > 0 {
+		book.ImageUrl = images[0].URL
+	}
+
+	if book.ISBN != "" {
+		u.enrichBook(book)
+	}
+	if err := resolveGenre(u.genreRepo, book); err != nil {
+		log.Printf("Failed Resolving Genre %q: %v\n", book.Genre, err)
+	}
+
+	var names []string
+	for _, tag := range book.Tags {
+		names = append(names, tag.Name)
+	}
+	names, err = normalizeTags(names)
+	if err != nil {
+		return nil, err
+	}
+	book.Tags = nil
+	for _, name := range names {
+		book.Tags = append(book.Tags, entity.Tag{Name: name})
+	}
+
+	if book.LocationPrecision == "" {
+		book.LocationPrecision = geo.PrecisionNeighbourhood
+	} else if !geo.ValidPrecision(book.LocationPrecision) {
+		return nil, response.NewBadRequestError("location_precision must be neighbourhood, district or city")
+	}
+	setPublicLocation(book)
+
+	if book.Title == "" || book.Author == "" {
+		return nil, response.NewBadRequestError("title and author are required when no metadata is found for the isbn")
+	}
+	if book.ImageUrl == "" {
+		return nil, response.NewBadRequestError("image_url or image_ids is required when no cover is found for the isbn")
+	}
+	return images, nil
 }
 
 func (u *bookUsecase) GetBookByID(id uint) (*entity.Book, error) {
@@ -54,9 +203,532 @@ func (u *bookUsecase) SearchBooks(queryParams map[string]string, page, size int)
 }
 
 func (u *bookUsecase) UpdateBook(book *entity.Book, updates map[string]interface{}) error {
-	return u.bookRepo.Update(book, updates)
+	if genre, ok := updates["genre"].(string); ok {
+		resolved := entity.Book{Genre: genre}
+		if err := resolveGenre(u.genreRepo, &resolved); err != nil {
+			log.Printf("Failed Resolving Genre %q: %v\n", genre, err)
+		}
+		if resolved.Genre == book.Genre {
+			delete(updates, "genre")
+		} else {
+			updates["genre"] = resolved.Genre
+		}
+		if !equalIDs(resolved.GenreID, book.GenreID) {
+			updates["genre_id"] = resolved.GenreID
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+	}
+
+	_, latitudeChanged := updates["latitude"]
+	_, longitudeChanged := updates["longitude"]
+	_, precisionChanged := updates["location_precision"]
+	if latitudeChanged || longitudeChanged || precisionChanged {
+		moved := *book
+		if latitude, ok := updates["latitude"].(float64); ok {
+			moved.PickupLocation.Latitude = latitude
+		}
+		if longitude, ok := updates["longitude"].(float64); ok {
+			moved.PickupLocation.Longitude = longitude
+		}
+		if precision, ok := updates["location_precision"].(string); ok {
+			moved.LocationPrecision = precision
+		}
+		setPublicLocation(&moved)
+		updates["public_latitude"] = moved.PublicLatitude
+		updates["public_longitude"] = moved.PublicLongitude
+	}
+
+	var changes []entity.FieldChange
+	for field, value := range updates {
+		if field == "genre_id" || field == "public_latitude" || field == "public_longitude" {
+			// Derived from other fields, which are recorded instead.
+			continue
+		}
+		changes = append(changes, entity.FieldChange{
+			Field: field,
+			Old:   bookFieldValue(book, field),
+			New:   fmt.Sprint(value),
+		})
+	}
+	slices.SortFunc(changes, func(a, b entity.FieldChange) int { return strings.Compare(a.Field, b.Field) })
+
+	if err := u.changeBook(book, updates, changes, nil); err != nil {
+		return err
+	}
+
+	_, titleChanged := updates["title"]
+	_, authorChanged := updates["author"]
+	_, isbnChanged := updates["isbn"]
+	if titleChanged || authorChanged || isbnChanged {
+		updated := *book
+		if title, ok := updates["title"].(string); ok {
+			updated.Title = title
+		}
+		if author, ok := updates["author"].(string); ok {
+			updated.Author = author
+		}
+		if isbn, ok := updates["isbn"].(string); ok {
+			updated.ISBN = isbn
+		}
+		u.linkWork(&updated)
+	}
+	u.wishlistUsecase.QueueBookMatch(book.ID)
+	return nil
+}
+
+// BackfillPublicLocations gives books listed before location privacy their
+// public coordinates. It runs once at startup and is a no-op afterwards.
+func (u *bookUsecase) BackfillPublicLocations() error {
+	var afterID uint
+	for {
+		books, err := u.bookRepo.FindWithoutPublicLocation(afterID, backfillBatchSize)
+		if err != nil {
+			return err
+		}
+		for i := range books {
+			setPublicLocation(&books[i])
+			err := u.bookRepo.Update(&books[i], map[string]interface{}{
+				"public_latitude":  books[i].PublicLatitude,
+				"public_longitude": books[i].PublicLongitude,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(books) < backfillBatchSize {
+			return nil
+		}
+		afterID = books[len(books)-1].ID
+	}
+}
+
+// linkWork attaches the book to its canonical work. Failures are only
+// logged: the book stays unlinked and is picked up by the next rebuild.
+func (u *bookUsecase) linkWork(book *entity.Book) {
+	work, err := resolveWork(u.workRepo, book)
+	if err != nil {
+		log.Printf("Failed Resolving Work For Book %d: %v\n", book.ID, err)
+		return
+	}
+	if book.WorkID != nil && *book.WorkID == work.ID {
+		return
+	}
+	if err := u.workRepo.AssignWork(book.ID, work.ID); err != nil {
+		log.Printf("Failed Linking Book %d To Work %d: %v\n", book.ID, work.ID, err)
+		return
+	}
+	book.WorkID = &work.ID
+}
+
+func (u *bookUsecase) GetArchivedBooksByUserID(uid uuid.UUID) ([]entity.Book, error) {
+	books, err := u.bookRepo.FindDeletedByUserID(uid)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	return books, nil
 }
 
+// DeleteBook archives the book. Exchange and loan history keep pointing at
+// the row, and pending requests involving it are declined, telling the
+// other side of each why; the owner removing it already knows. The book is
+// locked first, as exchanges and loans lock it, so that none of them can
+// take it while it is being archived.
 func (u *bookUsecase) DeleteBook(book *entity.Book) error {
-	return u.bookRepo.Delete(book)
+	err := u.transactor.Run(func(tx *repository.Tx) error {
+		bookRepo := u.bookRepo.WithTx(tx)
+		exchangeRepo := u.exchangeRepo.WithTx(tx)
+		exchangeEventRepo := u.exchangeEventRepo.WithTx(tx)
+		loanRepo := u.loanRepo.WithTx(tx)
+
+		locked, err := bookRepo.LockByIDs([]uint{book.ID})
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		if len(locked) == 0 {
+			return response.NewNotFoundError("book", fmt.Sprintf("%v", book.ID))
+		}
+
+		accepted, err := exchangeRepo.HasAcceptedRequestForBook(book.ID)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		if accepted {
+			return response.NewConflictError("exchange request", "book is part of an accepted exchange")
+		}
+		lent, err := loanRepo.HasOpenLoanForBook(book.ID)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		if lent {
+			return response.NewConflictError("loan", "book is lent out or promised to a borrower")
+		}
+
+		pendingRequests, err := exchangeRepo.FindPendingRequestsByBookID(book.ID)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		for _, pendingRequest := range pendingRequests {
+			err := applyExchangeTransition(exchangeRepo, exchangeEventRepo, &pendingRequest, actionAutoDecline, nil, "book was removed")
+			if err != nil {
+				return err
+			}
+
+			recipientID := pendingRequest.RequestedByID
+			if recipientID == book.UserID {
+				recipientID = pendingRequest.RequestedToID
+			}
+			msg := "Exchange Request involving book " + book.Title + " is declined because the book was removed."
+			notifyAfterCommit(tx, u.notificationService, recipientID, exchangeNotification, msg)
+		}
+
+		requestedLoans, err := loanRepo.FindRequestedByBookID(book.ID)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		for _, loan := range requestedLoans {
+			loan.Status = "declined"
+			err := loanRepo.Update(&loan, []string{"requested"}, "status")
+			if err != nil && err == gorm.ErrRecordNotFound {
+				// Cancelled by its borrower meanwhile.
+				continue
+			} else if err != nil {
+				return response.NewInternalServerError()
+			}
+
+			msg := "Your request to borrow " + book.Title + " is declined because the book was removed."
+			notifyAfterCommit(tx, u.notificationService, loan.BorrowerID, loanNotification, msg)
+		}
+
+		if err := bookRepo.Delete(book); err != nil {
+			return response.NewInternalServerError()
+		}
+		return nil
+	})
+	return transactionError(err)
+}
+
+func (u *bookUsecase) LookupISBN(isbn string) (*metadata.Metadata, error) {
+	if u.metadataProvider == nil {
+		return nil, response.NewServiceUnavailableError()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), metadataTimeout)
+	defer cancel()
+
+	meta, err := u.metadataProvider.Lookup(ctx, isbn)
+	if errors.Is(err, metadata.ErrNotFound) {
+		return nil, response.NewNotFoundError("isbn", isbn)
+	} else if err != nil {
+		log.Println("Failed Looking Up ISBN:", err)
+		return nil, response.NewServiceUnavailableError()
+	}
+	return meta, nil
+}
+
+// enrichBook replaces the typed title and author with the canonical ones
+// for the ISBN and fills genre and cover when the user left them empty. A
+// failed lookup leaves the book untouched.
+func (u *bookUsecase) enrichBook(book *entity.Book) {
+	if u.metadataProvider == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), metadataTimeout)
+	defer cancel()
+
+	meta, err := u.metadataProvider.Lookup(ctx, book.ISBN)
+	if err != nil {
+		if !errors.Is(err, metadata.ErrNotFound) {
+			log.Printf("Failed Looking Up ISBN %s: %v\n", book.ISBN, err)
+		}
+		return
+	}
+
+	if meta.Title != "" {
+		book.Title = meta.Title
+	}
+	if len(meta.Authors) > 0 {
+		book.Author = strings.Join(meta.Authors, ", ")
+	}
+	if book.Genre == "" && len(meta.Genres) > 0 {
+		book.Genre = meta.Genres[0]
+	}
+	if book.ImageUrl == "" {
+		book.ImageUrl = meta.CoverURL
+	}
+}
+
+func (u *bookUsecase) SetBookImages(book *entity.Book, imageIDs []uuid.UUID) error {
+	images, err := u.findAttachableImages(book, imageIDs)
+	if err != nil {
+		return err
+	}
+
+	oldImageIDs := make([]string, len(book.Images))
+	for i, image := range book.Images {
+		oldImageIDs[i] = image.ID.String()
+	}
+	newImageIDs := make([]string, len(imageIDs))
+	for i, id := range imageIDs {
+		newImageIDs[i] = id.String()
+	}
+	if slices.Equal(oldImageIDs, newImageIDs) {
+		return nil
+	}
+
+	updates := map[string]interface{}{}
+	if len(images) > 0 && images[0].URL != book.ImageUrl {
+		updates["image_url"] = images[0].URL
+	}
+	changes := []entity.FieldChange{{
+		Field: "images",
+		Old:   strings.Join(oldImageIDs, ","),
+		New:   strings.Join(newImageIDs, ","),
+	}}
+	err = u.changeBook(book, updates, changes, func(tx *repository.Tx) error {
+		if err := u.imageRepo.WithTx(tx).SetBookImages(book.ID, imageIDs); err != nil {
+			return response.NewInternalServerError()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	book.Images = images
+	return nil
+}
+
+func (u *bookUsecase) SetBookTags(book *entity.Book, names []string) error {
+	names, err := normalizeTags(names)
+	if err != nil {
+		return err
+	}
+
+	oldNames := make([]string, len(book.Tags))
+	for i, tag := range book.Tags {
+		oldNames[i] = tag.Name
+	}
+	slices.Sort(oldNames)
+	newNames := slices.Sorted(slices.Values(names))
+	if slices.Equal(oldNames, newNames) {
+		return nil
+	}
+
+	var tags []entity.Tag
+	changes := []entity.FieldChange{{
+		Field: "tags",
+		Old:   strings.Join(oldNames, ","),
+		New:   strings.Join(newNames, ","),
+	}}
+	err = u.changeBook(book, nil, changes, func(tx *repository.Tx) error {
+		tagRepo := u.tagRepo.WithTx(tx)
+		var err error
+		tags, err = tagRepo.FindOrCreate(names)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		if err := tagRepo.SetBookTags(book, tags); err != nil {
+			return response.NewInternalServerError()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	book.Tags = tags
+	return nil
+}
+
+func (u *bookUsecase) GetBookHistory(book *entity.Book, userID uuid.UUID, sinceVersion int) ([]entity.BookVersion, error) {
+	versions, err := u.bookVersionRepo.FindByBookID(book.ID, sinceVersion)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	if userID == book.UserID {
+		return versions, nil
+	}
+
+	for i := range versions {
+		versions[i].Changes = slices.DeleteFunc(versions[i].Changes, func(change entity.FieldChange) bool {
+			return privateBookFields[change.Field]
+		})
+	}
+	return versions, nil
+}
+
+// changeBook runs apply, writes updates with the next version of the book
+// and records changes as that version, all in one transaction. The book is
+// locked first so that concurrent edits get consecutive versions. When a
+// field a swap partner relies on changed, everyone with a pending request
+// on the book is told that they may withdraw it.
+func (u *bookUsecase) changeBook(book *entity.Book, updates map[string]interface{}, changes []entity.FieldChange, apply func(tx *repository.Tx) error) error {
+	material := false
+	var summary []string
+	for _, change := range changes {
+		if materialBookFields[change.Field] {
+			material = true
+			if change.Field == "images" {
+				summary = append(summary, "photos changed")
+			} else {
+				summary = append(summary, fmt.Sprintf("%s from %q to %q", change.Field, change.Old, change.New))
+			}
+		}
+	}
+
+	var next int
+	err := u.transactor.Run(func(tx *repository.Tx) error {
+		bookRepo := u.bookRepo.WithTx(tx)
+		locked, err := bookRepo.LockByIDs([]uint{book.ID})
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		if len(locked) == 0 {
+			return response.NewNotFoundError("book", fmt.Sprintf("%v", book.ID))
+		}
+		next = locked[0].Version + 1
+
+		if apply != nil {
+			if err := apply(tx); err != nil {
+				return err
+			}
+		}
+
+		versioned := maps.Clone(updates)
+		if versioned == nil {
+			versioned = map[string]interface{}{}
+		}
+		versioned["version"] = next
+		if err := bookRepo.Update(book, versioned); err != nil {
+			return response.NewInternalServerError()
+		}
+		err = u.bookVersionRepo.WithTx(tx).Create(&entity.BookVersion{
+			BookID:    book.ID,
+			Version:   next,
+			Changes:   changes,
+			Material:  material,
+			ChangedBy: book.UserID,
+		})
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+
+		if !material {
+			return nil
+		}
+		pendingRequests, err := u.exchangeRepo.WithTx(tx).FindPendingRequestsByBookID(book.ID)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		for _, pendingRequest := range pendingRequests {
+			recipientID := pendingRequest.RequestedByID
+			if recipientID == book.UserID {
+				recipientID = pendingRequest.RequestedToID
+			}
+			msg := "Book " + book.Title + " in your pending exchange request was changed (" + strings.Join(summary, ", ") + "). You can withdraw the request."
+			notifyAfterCommit(tx, u.notificationService, recipientID, exchangeNotification, msg)
+		}
+		return nil
+	})
+	if err != nil {
+		return transactionError(err)
+	}
+	book.Version = next
+	return nil
+}
+
+var materialBookFields = map[string]bool{
+	"title":     true,
+	"author":    true,
+	"isbn":      true,
+	"condition": true,
+	"images":    true,
+}
+
+var privateBookFields = map[string]bool{
+	"address":   true,
+	"latitude":  true,
+	"longitude": true,
+}
+
+func equalIDs(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func bookFieldValue(book *entity.Book, field string) string {
+	switch field {
+	case "title":
+		return book.Title
+	case "author":
+		return book.Author
+	case "genre":
+		return book.Genre
+	case "isbn":
+		return book.ISBN
+	case "image_url":
+		return book.ImageUrl
+	case "message":
+		return book.Description.Message
+	case "condition":
+		return book.Description.Condition
+	case "preferred_exchange":
+		return book.Description.PreferredExchange
+	case "address":
+		return book.PickupLocation.Address
+	case "latitude":
+		return fmt.Sprint(book.PickupLocation.Latitude)
+	case "longitude":
+		return fmt.Sprint(book.PickupLocation.Longitude)
+	case "listing_type":
+		return book.ListingType
+	case "claim_mode":
+		return book.ClaimMode
+	case "location_precision":
+		return book.LocationPrecision
+	}
+	return ""
+}
+
+// findAttachableImages returns the images in the order of imageIDs after
+// checking that each one belongs to the book owner and is not used elsewhere.
+func (u *bookUsecase) findAttachableImages(book *entity.Book, imageIDs []uuid.UUID) ([]entity.Image, error) {
+	if len(imageIDs) == 0 {
+		return nil, nil
+	}
+	if len(imageIDs) > maxBookImages {
+		return nil, response.NewBadRequestError(fmt.Sprintf("a book can have at most %d images", maxBookImages))
+	}
+
+	fetched, err := u.imageRepo.FindByIDs(imageIDs)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	byID := make(map[uuid.UUID]entity.Image, len(fetched))
+	for _, image := range fetched {
+		byID[image.ID] = image
+	}
+
+	images := make([]entity.Image, 0, len(imageIDs))
+	seen := make(map[uuid.UUID]bool, len(imageIDs))
+	for _, id := range imageIDs {
+		image, ok := byID[id]
+		if !ok || image.UserID != book.UserID {
+			return nil, response.NewNotFoundError("image", id.String())
+		}
+		if seen[id] {
+			return nil, response.NewBadRequestError("duplicate image id " + id.String())
+		}
+		if image.BookID != nil && *image.BookID != book.ID || image.MessageID != nil {
+			return nil, response.NewConflictError("image", id.String())
+		}
+		seen[id] = true
+		images = append(images, image)
+	}
+	return images, nil
 }
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/response"
+)
+
+// maxBundleSize caps the books on each side of an exchange request.
+const maxBundleSize = 10
+
+// sideItems returns one side of the request's books. Requests built in
+// memory without items stand for their single requested and offered book.
+func sideItems(request *entity.ExchangeRequest, side string) []entity.ExchangeItem {
+	if len(request.Items) == 0 {
+		if side == entity.ItemRequested {
+			return []entity.ExchangeItem{{ExchangeRequestID: request.ID, BookID: request.RequestedBookID, Book: request.RequestedBook, Side: side, BookVersion: request.RequestedBookVersion}}
+		}
+		if request.OfferedBook == nil {
+			return nil
+		}
+		return []entity.ExchangeItem{{ExchangeRequestID: request.ID, BookID: request.OfferedBook.ID, Book: *request.OfferedBook, Side: side, BookVersion: request.OfferedBookVersion}}
+	}
+
+	var items []entity.ExchangeItem
+	for _, item := range request.Items {
+		if item.Side == side {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// bookCopies returns every copy of the request's books held in memory.
+// Once items are loaded the first book of each side is there twice, so
+// changes to a book have to reach all of its copies.
+func bookCopies(request *entity.ExchangeRequest) []*entity.Book {
+	books := []*entity.Book{&request.RequestedBook}
+	if request.OfferedBook != nil {
+		books = append(books, request.OfferedBook)
+	}
+	for i := range request.Items {
+		books = append(books, &request.Items[i].Book)
+	}
+	return books
+}
+
+// setBooksActive sets is_active on the given books, in the database and on
+// every in-memory copy of them.
+func setBooksActive(tx *exchangeTx, request *entity.ExchangeRequest, bookIDs []uint, active bool) error {
+	for _, book := range bookCopies(request) {
+		if slices.Contains(bookIDs, book.ID) {
+			book.IsActive = active
+		}
+	}
+	for _, bookID := range bookIDs {
+		err := tx.bookRepo.Update(&entity.Book{ID: bookID}, map[string]interface{}{
+			"is_active": active,
+		})
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+	}
+	return nil
+}
+
+// exchangeBookIDs returns the ids of every book the request swaps, in the
+// order items are stored in.
+func exchangeBookIDs(request *entity.ExchangeRequest) []uint {
+	requested := itemBookIDs(sideItems(request, entity.ItemRequested))
+	offered := itemBookIDs(sideItems(request, entity.ItemOffered))
+	slices.Sort(requested)
+	slices.Sort(offered)
+	return append(requested, offered...)
+}
+
+func itemBookIDs(items []entity.ExchangeItem) []uint {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.BookID
+	}
+	return ids
+}
+
+// buildBundle fills in the request's items, or its first book of each side
+// from them, and checks that every book is on the right shelf once.
+func buildBundle(request *entity.ExchangeRequest) error {
+	if len(request.Items) == 0 {
+		request.Items = append(sideItems(request, entity.ItemRequested), sideItems(request, entity.ItemOffered)...)
+	}
+
+	requested := sideItems(request, entity.ItemRequested)
+	offered := sideItems(request, entity.ItemOffered)
+	if len(requested) == 0 {
+		return response.NewBadRequestError("an exchange request needs at least one requested book")
+	}
+	if len(requested) > maxBundleSize || len(offered) > maxBundleSize {
+		return response.NewBadRequestError(fmt.Sprintf("at most %d books can be exchanged on each side", maxBundleSize))
+	}
+
+	seen := make(map[uint]bool, len(request.Items))
+	for i := range request.Items {
+		item := &request.Items[i]
+		if seen[item.BookID] {
+			return response.NewBadRequestError("a book can only be in a request once")
+		}
+		seen[item.BookID] = true
+		item.BookVersion = item.Book.Version
+
+		owner := request.RequestedToID
+		if item.Side == entity.ItemOffered {
+			owner = request.RequestedByID
+		}
+		if item.Book.UserID != owner {
+			return response.NewBadRequestError(fmt.Sprintf("%s book %d does not belong to the right side of the exchange", item.Side, item.BookID))
+		}
+	}
+
+	requested = sideItems(request, entity.ItemRequested)
+	request.RequestedBookID = requested[0].BookID
+	request.RequestedBook = requested[0].Book
+	request.RequestedBookVersion = requested[0].BookVersion
+	request.OfferedBookID, request.OfferedBook, request.OfferedBookVersion = nil, nil, 1
+	if offered := sideItems(request, entity.ItemOffered); len(offered) > 0 {
+		book := offered[0].Book
+		request.OfferedBookID = &book.ID
+		request.OfferedBook = &book
+		request.OfferedBookVersion = offered[0].BookVersion
+	}
+	return nil
+}
+
+// bundleTitles names a side's books for notifications.
+func bundleTitles(items []entity.ExchangeItem) string {
+	titles := make([]string, len(items))
+	for i, item := range items {
+		titles[i] = item.Book.Title
+	}
+	return strings.Join(titles, ", ")
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+)
+
+func TestAcceptDeclinesRequestsSharingABook(t *testing.T) {
+	s := newExchangeStore()
+	requester, owner, other, third := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	for _, id := range []uint{1, 2, 3} {
+		s.addBook(id, owner, true)
+	}
+	s.addBook(10, requester, true)
+	s.addBook(11, requester, true)
+	s.addBook(20, other, true)
+	s.addBook(30, third, true)
+
+	bundle := s.addRequest(t, requester, owner, entity.ExchangePending, []uint{1, 2}, []uint{10})
+	sameRequested := s.addRequest(t, other, owner, entity.ExchangePending, []uint{2}, []uint{20})
+	sameOffered := s.addRequest(t, requester, third, entity.ExchangeCountered, []uint{30}, []uint{10, 11})
+	untouched := s.addRequest(t, other, owner, entity.ExchangePending, []uint{3}, []uint{20})
+
+	if err := s.usecase().AcceptExchange(bundle, owner); err != nil {
+		t.Fatalf("AcceptExchange: %v", err)
+	}
+
+	wantStatus := map[*entity.ExchangeRequest]entity.ExchangeStatus{
+		bundle:        entity.ExchangeAccepted,
+		sameRequested: entity.ExchangeDeclined,
+		sameOffered:   entity.ExchangeDeclined,
+		untouched:     entity.ExchangePending,
+	}
+	for request, want := range wantStatus {
+		if got := s.requests[request.ID].Status; got != want {
+			t.Errorf("request for book %d is %q, want %q", request.RequestedBookID, got, want)
+		}
+	}
+	for id, want := range map[uint]bool{1: false, 2: false, 10: false, 3: true, 11: true, 20: true} {
+		if got := s.books[id].IsActive; got != want {
+			t.Errorf("book %d active = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestAcceptBundleWithTakenBook(t *testing.T) {
+	s := newExchangeStore()
+	requester, owner := uuid.New(), uuid.New()
+	s.addBook(1, owner, true)
+	s.addBook(2, owner, false) // already promised elsewhere
+	s.addBook(10, requester, true)
+	bundle := s.addRequest(t, requester, owner, entity.ExchangePending, []uint{1, 2}, []uint{10})
+
+	if err := s.usecase().AcceptExchange(bundle, owner); err == nil {
+		t.Fatal("accepted a bundle with a book no longer available")
+	}
+	if got := s.requests[bundle.ID].Status; got != entity.ExchangePending {
+		t.Errorf("request is %q, want it still pending", got)
+	}
+	if !s.books[1].IsActive || !s.books[10].IsActive {
+		t.Error("books of the refused bundle were taken off the shelf")
+	}
+}
@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// cancelAnswerWindow is how long the other side has to answer a request
+// to cancel. Cancelling alone after it passed unanswered does not count
+// against the one who asked.
+const cancelAnswerWindow = 72 * time.Hour
+
+// CancelExchange calls off an accepted exchange and lists its books
+// again. Cancelling asks the other side first; once they cancel too, the
+// exchange is called off with nobody at fault. Cancelling oneSided calls
+// it off at once, counted against userID unless the other side left an
+// earlier request of theirs unanswered. Once a side confirmed receiving
+// their books, only a dispute can end the exchange otherwise.
+func (u *exchangeUsecase) CancelExchange(request *entity.ExchangeRequest, userID uuid.UUID, reason string, oneSided bool) error {
+	if request.RequestedByID != userID && request.RequestedToID != userID {
+		return response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+	}
+	recipientID, name := exchangeCounterpart(request, userID)
+
+	return u.inTransaction(request, func(tx *exchangeTx) error {
+		now := time.Now()
+		askedBy := request.CancelRequestedByID
+
+		if askedBy != nil && *askedBy == recipientID {
+			err := tx.apply(request, actionCancel, &userID, "both sides agreed: "+request.CancelReason)
+			if err != nil {
+				return err
+			}
+			if err := reactivateBooks(tx, request); err != nil {
+				return err
+			}
+			msg := name + " agreed to cancel the exchange for book " + request.RequestedBook.Title + ". The books are listed again."
+			notifyAfterCommit(tx.Tx, u.notificationService, recipientID, exchangeNotification, msg)
+			return nil
+		}
+
+		if !oneSided {
+			if askedBy != nil {
+				return response.NewBadRequestError("you already asked to cancel, wait for the other side or cancel one-sided")
+			}
+			request.CancelRequestedByID = &userID
+			request.CancelRequestedAt = &now
+			request.CancelReason = reason
+			err := tx.apply(request, actionRequestCancel, &userID, reason)
+			if err != nil {
+				return err
+			}
+			msg := name + " asks to cancel the exchange for book " + request.RequestedBook.Title + ": " + reason + ". Cancel too to agree, or open a dispute."
+			notifyAfterCommit(tx.Tx, u.notificationService, recipientID, exchangeNotification, msg)
+			return nil
+		}
+
+		if request.RequestedByConfirmed || request.RequestedToConfirmed {
+			return response.NewBadRequestError("a handover was already confirmed, open a dispute instead")
+		}
+		unanswered := askedBy != nil && request.CancelRequestedAt != nil && now.Sub(*request.CancelRequestedAt) >= cancelAnswerWindow
+		err := tx.apply(request, actionCancel, &userID, reason)
+		if err != nil {
+			return err
+		}
+		if err := reactivateBooks(tx, request); err != nil {
+			return err
+		}
+		if !unanswered {
+			if err := tx.userRepo.IncrementCancellationCount(userID); err != nil {
+				return response.NewInternalServerError()
+			}
+		}
+		msg := name + " cancelled the exchange for book " + request.RequestedBook.Title + ": " + reason + ". The books are listed again."
+		notifyAfterCommit(tx.Tx, u.notificationService, recipientID, exchangeNotification, msg)
+		return nil
+	})
+}
+
+// DisputeExchange hands an accepted exchange to the admins, for instance
+// when the sides disagree on whether the books changed hands. Its books
+// stay off the shelf until an admin decides.
+func (u *exchangeUsecase) DisputeExchange(request *entity.ExchangeRequest, userID uuid.UUID, reason string) error {
+	if request.RequestedByID != userID && request.RequestedToID != userID {
+		return response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+	}
+	recipientID, name := exchangeCounterpart(request, userID)
+
+	return u.inTransaction(request, func(tx *exchangeTx) error {
+		request.DisputedByID = &userID
+		request.DisputeReason = reason
+		err := tx.apply(request, actionDispute, &userID, reason)
+		if err != nil {
+			return err
+		}
+		msg := name + " opened a dispute about the exchange for book " + request.RequestedBook.Title + ": " + reason + ". An admin will review it."
+		notifyAfterCommit(tx.Tx, u.notificationService, recipientID, exchangeNotification, msg)
+		return nil
+	})
+}
+
+func (u *exchangeUsecase) GetDisputedExchanges() ([]entity.ExchangeRequest, error) {
+	requests, err := u.exchangeRepo.FindDisputed()
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	return requests, nil
+}
+
+// ResolveDispute ends a disputed exchange as exchanged or cancelled, the
+// latter listing its books again. The side found atFault, "requester" or
+// "owner", has it counted against their reputation. The disputed legs of a
+// trade cycle are resolved together, the same way.
+func (u *exchangeUsecase) ResolveDispute(id uuid.UUID, adminID uuid.UUID, outcome entity.ExchangeStatus, atFault string, note string) (*entity.ExchangeRequest, error) {
+	action := actionResolveCancelled
+	switch outcome {
+	case entity.ExchangeExchanged:
+		action = actionResolveExchanged
+	case entity.ExchangeCancelled:
+	default:
+		return nil, response.NewBadRequestError("outcome must be exchanged or cancelled")
+	}
+	var faultID *uuid.UUID
+
+	request, err := u.exchangeRepo.FindByID(id)
+	if err != nil && err == gorm.ErrRecordNotFound {
+		return nil, response.NewNotFoundError("exchange request", fmt.Sprintf("%v", id))
+	} else if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	switch atFault {
+	case "":
+	case string(actorRequester):
+		faultID = &request.RequestedByID
+	case string(actorOwner):
+		faultID = &request.RequestedToID
+	default:
+		return nil, response.NewBadRequestError("at_fault must be requester or owner")
+	}
+
+	legs := []*entity.ExchangeRequest{request}
+	if request.TradeCycleID != nil {
+		others, err := u.exchangeRepo.FindByTradeCycleID(*request.TradeCycleID)
+		if err != nil {
+			return nil, response.NewInternalServerError()
+		}
+		for i := range others {
+			if others[i].ID != request.ID {
+				legs = append(legs, &others[i])
+			}
+		}
+	}
+
+	err = u.inCycleTransaction(legs, func(tx *exchangeTx) error {
+		for _, leg := range legs {
+			// The request asked for has to be disputed; the other legs
+			// of its cycle are resolved along with it if they are.
+			if leg != request && leg.Status != entity.ExchangeDisputed {
+				continue
+			}
+			leg.DisputeResolvedByID = &adminID
+			leg.DisputeResolution = note
+			err := tx.applyAsAdmin(leg, action, adminID, note)
+			if err != nil {
+				return err
+			}
+			if outcome == entity.ExchangeCancelled {
+				if err := reactivateBooks(tx, leg); err != nil {
+					return err
+				}
+			}
+
+			msg := "The dispute about the exchange for book " + leg.RequestedBook.Title + " was resolved: the exchange is " + string(outcome) + "."
+			if note != "" {
+				msg += " " + note
+			}
+			notifyAfterCommit(tx.Tx, u.notificationService, leg.RequestedByID, exchangeNotification, msg)
+			notifyAfterCommit(tx.Tx, u.notificationService, leg.RequestedToID, exchangeNotification, msg)
+		}
+		if faultID != nil {
+			if err := tx.userRepo.IncrementCancellationCount(*faultID); err != nil {
+				return response.NewInternalServerError()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+)
+
+const (
+	exchangeExpiryBatch    = 100
+	exchangeReminderWindow = 48 * time.Hour
+)
+
+// RunExpiry ends requests that waited too long: pending requests nobody
+// answered or countered expire, and accepted exchanges not confirmed by
+// both sides in time lapse, putting both books back on the shelf, or go to
+// the admins as disputed when one side already confirmed. An agreed meetup
+// later than the acceptance starts that time anew. Both sides of an
+// accepted exchange are reminded once before it lapses. Trade cycles lapse
+// as a whole once every leg still accepted ran out of time.
+func (u *exchangeUsecase) RunExpiry(ctx context.Context) {
+	now := time.Now()
+
+	if u.timeouts.Pending > 0 {
+		u.expireBatches(ctx, func() ([]entity.ExchangeRequest, error) {
+			return u.exchangeRepo.FindPendingIdleSince(now.Add(-u.timeouts.Pending), exchangeExpiryBatch)
+		}, u.expireRequest)
+	}
+
+	if u.timeouts.Accepted <= 0 {
+		return
+	}
+	u.expireBatches(ctx, func() ([]entity.ExchangeRequest, error) {
+		return u.exchangeRepo.FindAcceptedBefore(now.Add(-u.timeouts.Accepted), exchangeExpiryBatch)
+	}, u.lapseRequest)
+	u.lapseCycles(ctx, now.Add(-u.timeouts.Accepted))
+
+	window := exchangeReminderWindow
+	if window >= u.timeouts.Accepted {
+		window = u.timeouts.Accepted / 2
+	}
+	requests, err := u.exchangeRepo.FindUnremindedAcceptedBefore(now.Add(window-u.timeouts.Accepted), exchangeExpiryBatch)
+	if err != nil {
+		log.Printf("Failed Finding Exchange Requests To Remind: %v\n", err)
+		return
+	}
+	for _, request := range requests {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := u.exchangeRepo.MarkReminded(request.ID, now); err != nil {
+			log.Printf("Failed Updating Exchange Request %v: %v\n", request.ID, err)
+			continue
+		}
+		since := request.CreatedAt
+		if request.AcceptedAt != nil {
+			since = *request.AcceptedAt
+		}
+		if meetup, err := u.exchangeMeetupRepo.FindAccepted(request.ID); err == nil && meetup.StartsAt.After(since) {
+			since = meetup.StartsAt
+		}
+		deadline := since.Add(u.timeouts.Accepted)
+		msg := "Confirm the exchange for book " + request.RequestedBook.Title + " by " + deadline.UTC().Format("2 Jan 2006 15:04 MST") + " or it will lapse."
+		u.notify(request.RequestedByID, msg)
+		u.notify(request.RequestedToID, msg)
+	}
+}
+
+// expireBatches ends the requests find returns, batch by batch, until none
+// are left or a whole batch failed.
+func (u *exchangeUsecase) expireBatches(ctx context.Context, find func() ([]entity.ExchangeRequest, error), end func(request *entity.ExchangeRequest) error) {
+	for ctx.Err() == nil {
+		requests, err := find()
+		if err != nil {
+			log.Printf("Failed Finding Stale Exchange Requests: %v\n", err)
+			return
+		}
+
+		ended := 0
+		for i := range requests {
+			if ctx.Err() != nil {
+				return
+			}
+			err := end(&requests[i])
+			if err != nil && response.Status(err) == http.StatusBadRequest {
+				// Answered or confirmed meanwhile.
+				continue
+			} else if err != nil {
+				log.Printf("Failed Expiring Exchange Request %v: %v\n", requests[i].ID, err)
+				continue
+			}
+			ended++
+		}
+		if len(requests) < exchangeExpiryBatch || ended == 0 {
+			return
+		}
+	}
+}
+
+func (u *exchangeUsecase) expireRequest(request *entity.ExchangeRequest) error {
+	return u.inTransaction(request, func(tx *exchangeTx) error {
+		err := tx.apply(request, actionExpire, nil, "not answered in time")
+		if err != nil {
+			return err
+		}
+		notifyAfterCommit(tx.Tx, u.notificationService, request.RequestedByID, exchangeNotification, "Your Exchange Request For Book "+request.RequestedBook.Title+" expired without an answer.")
+		notifyAfterCommit(tx.Tx, u.notificationService, request.RequestedToID, exchangeNotification, "The Exchange Request For Your Book "+request.RequestedBook.Title+" expired without an answer.")
+		return nil
+	})
+}
+
+func (u *exchangeUsecase) lapseRequest(request *entity.ExchangeRequest) error {
+	return u.inTransaction(request, func(tx *exchangeTx) error {
+		if request.RequestedByConfirmed || request.RequestedToConfirmed {
+			err := tx.apply(request, actionEscalate, nil, "not confirmed by both sides in time")
+			if err != nil {
+				return err
+			}
+			msg := "The Exchange For Book " + request.RequestedBook.Title + " was confirmed by one side only in time. An admin will review it."
+			notifyAfterCommit(tx.Tx, u.notificationService, request.RequestedByID, exchangeNotification, msg)
+			notifyAfterCommit(tx.Tx, u.notificationService, request.RequestedToID, exchangeNotification, msg)
+			return nil
+		}
+
+		err := tx.apply(request, actionLapse, nil, "not confirmed in time")
+		if err != nil {
+			return err
+		}
+		if err := reactivateBooks(tx, request); err != nil {
+			return err
+		}
+		msg := "The Exchange For Book " + request.RequestedBook.Title + " lapsed as it was not confirmed in time. The books are listed again."
+		notifyAfterCommit(tx.Tx, u.notificationService, request.RequestedByID, exchangeNotification, msg)
+		notifyAfterCommit(tx.Tx, u.notificationService, request.RequestedToID, exchangeNotification, msg)
+		return nil
+	})
+}
+
+func (u *exchangeUsecase) lapseCycles(ctx context.Context, before time.Time) {
+	for ctx.Err() == nil {
+		cycleIDs, err := u.exchangeRepo.FindCyclesAcceptedBefore(before, exchangeExpiryBatch)
+		if err != nil {
+			log.Printf("Failed Finding Stale Trade Cycles: %v\n", err)
+			return
+		}
+
+		ended := 0
+		for _, cycleID := range cycleIDs {
+			if ctx.Err() != nil {
+				return
+			}
+			err := u.lapseCycle(cycleID)
+			if err != nil && response.Status(err) == http.StatusBadRequest {
+				continue
+			} else if err != nil {
+				log.Printf("Failed Expiring Trade Cycle %v: %v\n", cycleID, err)
+				continue
+			}
+			ended++
+		}
+		if len(cycleIDs) < exchangeExpiryBatch || ended == 0 {
+			return
+		}
+	}
+}
+
+// lapseCycle ends the legs of a trade cycle still accepted. If any book in
+// the cycle was handed over, they all go to the admins, as lapsing some
+// legs would leave a participant giving without getting; otherwise they
+// all lapse and their books are listed again.
+func (u *exchangeUsecase) lapseCycle(cycleID uuid.UUID) error {
+	found, err := u.exchangeRepo.FindByTradeCycleID(cycleID)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	legs := make([]*entity.ExchangeRequest, len(found))
+	for i := range found {
+		legs[i] = &found[i]
+	}
+
+	return u.inCycleTransaction(legs, func(tx *exchangeTx) error {
+		handedOver := false
+		for _, leg := range legs {
+			if leg.Status == entity.ExchangeAccepted || leg.Status == entity.ExchangeExchanged {
+				handedOver = handedOver || leg.RequestedByConfirmed || leg.RequestedToConfirmed
+			}
+		}
+
+		ended := 0
+		for _, leg := range legs {
+			if leg.Status != entity.ExchangeAccepted {
+				continue
+			}
+			if handedOver {
+				err := tx.apply(leg, actionEscalate, nil, "trade cycle not confirmed by everyone in time")
+				if err != nil {
+					return err
+				}
+				msg := "The Exchange For Book " + leg.RequestedBook.Title + " was not confirmed in time while others in its trade cycle were. An admin will review it."
+				notifyAfterCommit(tx.Tx, u.notificationService, leg.RequestedByID, exchangeNotification, msg)
+				notifyAfterCommit(tx.Tx, u.notificationService, leg.RequestedToID, exchangeNotification, msg)
+			} else {
+				err := tx.apply(leg, actionLapse, nil, "trade cycle not confirmed in time")
+				if err != nil {
+					return err
+				}
+				if err := reactivateBooks(tx, leg); err != nil {
+					return err
+				}
+				msg := "The Exchange For Book " + leg.RequestedBook.Title + " lapsed with its trade cycle as it was not confirmed in time. The books are listed again."
+				notifyAfterCommit(tx.Tx, u.notificationService, leg.RequestedByID, exchangeNotification, msg)
+				notifyAfterCommit(tx.Tx, u.notificationService, leg.RequestedToID, exchangeNotification, msg)
+			}
+			ended++
+		}
+		if ended == 0 {
+			return response.NewBadRequestError("trade cycle has no accepted legs left")
+		}
+		return nil
+	})
+}
+
+func (u *exchangeUsecase) notify(userID uuid.UUID, msg string) {
+	err := u.notificationService.SendNotification(userID, "exchange request", msg)
+	if err != nil {
+		log.Println("Failed Sending Exchange Request Notification:", err)
+	}
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+)
+
+// acceptedCycle stores a trade cycle of three accepted legs where each
+// user gets the next one's book, and returns the cycle's id and its legs.
+func acceptedCycle(t *testing.T, s *exchangeStore) (uuid.UUID, []*entity.ExchangeRequest) {
+	t.Helper()
+	cycleID := uuid.New()
+	users := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for i, user := range users {
+		s.addBook(uint(i+1), user, false)
+	}
+	var legs []*entity.ExchangeRequest
+	for i, receiver := range users {
+		giver := (i + 1) % len(users)
+		leg := s.addRequest(t, receiver, users[giver], entity.ExchangeAccepted, []uint{uint(giver + 1)}, nil)
+		leg.Type = "cycle"
+		leg.TradeCycleID = &cycleID
+		s.requests[leg.ID] = copyRequest(leg)
+		legs = append(legs, leg)
+	}
+	return cycleID, legs
+}
+
+func TestLapseCycleUnconfirmed(t *testing.T) {
+	s := newExchangeStore()
+	cycleID, legs := acceptedCycle(t, s)
+
+	if err := s.usecase().lapseCycle(cycleID); err != nil {
+		t.Fatalf("lapseCycle: %v", err)
+	}
+	for _, leg := range legs {
+		if got := s.requests[leg.ID].Status; got != entity.ExchangeExpired {
+			t.Errorf("leg for book %d is %q, want expired", leg.RequestedBookID, got)
+		}
+	}
+	for id, book := range s.books {
+		if !book.IsActive {
+			t.Errorf("book %d not listed again", id)
+		}
+	}
+
+	if err := s.usecase().lapseCycle(cycleID); response.Status(err) != http.StatusBadRequest {
+		t.Errorf("lapsing the cycle again: got %v, want status %d", err, http.StatusBadRequest)
+	}
+}
+
+func TestLapseCycleAfterAHandover(t *testing.T) {
+	s := newExchangeStore()
+	cycleID, legs := acceptedCycle(t, s)
+	handedOver := s.requests[legs[1].ID]
+	handedOver.RequestedByConfirmed = true
+	s.requests[legs[1].ID] = handedOver
+
+	if err := s.usecase().lapseCycle(cycleID); err != nil {
+		t.Fatalf("lapseCycle: %v", err)
+	}
+	// Lapsing the other legs would leave one participant giving a book
+	// without getting theirs, so the whole cycle goes to the admins.
+	for _, leg := range legs {
+		if got := s.requests[leg.ID].Status; got != entity.ExchangeDisputed {
+			t.Errorf("leg for book %d is %q, want disputed", leg.RequestedBookID, got)
+		}
+	}
+	for id, book := range s.books {
+		if book.IsActive {
+			t.Errorf("book %d listed again while disputed", id)
+		}
+	}
+
+	admin := uuid.New()
+	owner := legs[2].RequestedToID
+	_, err := s.usecase().ResolveDispute(legs[2].ID, admin, entity.ExchangeCancelled, string(actorOwner), "never showed up")
+	if err != nil {
+		t.Fatalf("ResolveDispute: %v", err)
+	}
+	for _, leg := range legs {
+		stored := s.requests[leg.ID]
+		if stored.Status != entity.ExchangeCancelled {
+			t.Errorf("leg for book %d is %q after resolving, want cancelled", leg.RequestedBookID, stored.Status)
+		}
+		if stored.DisputeResolvedByID == nil || *stored.DisputeResolvedByID != admin {
+			t.Errorf("leg for book %d not resolved by the admin", leg.RequestedBookID)
+		}
+	}
+	for id, book := range s.books {
+		if !book.IsActive {
+			t.Errorf("book %d not listed again after cancelling", id)
+		}
+	}
+	if len(s.cancellation) != 1 || s.cancellation[owner] != 1 {
+		t.Errorf("cancellations counted %v, want one against the owner at fault", s.cancellation)
+	}
+}
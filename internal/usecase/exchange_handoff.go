@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	handoffCodeDigits  = 6
+	handoffCodeTTL     = 15 * time.Minute
+	handoffMaxAttempts = 5 // wrong codes before checking pauses
+	handoffLockout     = 15 * time.Minute
+	handoffQRPrefix    = "gyanpass-handoff:"
+)
+
+// IssueHandoffCode gives userID a new one-time code to show the other side
+// at the meetup, replacing any code issued before. Wrong guesses at the
+// replaced code still count towards the lockout.
+func (u *exchangeUsecase) IssueHandoffCode(request *entity.ExchangeRequest, userID uuid.UUID) (*entity.HandoffCode, error) {
+	if request.RequestedByID != userID && request.RequestedToID != userID {
+		return nil, response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+	}
+
+	var issued *entity.HandoffCode
+	err := u.inTransaction(request, func(tx *exchangeTx) error {
+		if _, err := checkExchangeTransition(request, actionConfirm, &userID); err != nil {
+			return err
+		}
+		if request.RequestedByID == userID && request.RequestedToHandoffVerified || request.RequestedToID == userID && request.RequestedByHandoffVerified {
+			return response.NewBadRequestError("the other side already confirmed receiving the books")
+		}
+
+		handoff, err := tx.exchangeHandoffRepo.Find(request.ID, userID)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return response.NewInternalServerError()
+		} else if err != nil {
+			handoff = &entity.ExchangeHandoff{ExchangeRequestID: request.ID, UserID: userID}
+		}
+		now := time.Now()
+		if handoff.LockedUntil != nil && now.Before(*handoff.LockedUntil) {
+			return response.NewTooManyRequestsError(handoff.LockedUntil.Sub(now))
+		}
+
+		code, err := newHandoffCode()
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		handoff.CodeHash = u.handoffCodeHash(request.ID, userID, code)
+		handoff.ExpiresAt = now.Add(handoffCodeTTL)
+		handoff.UsedAt = nil
+		if err := tx.exchangeHandoffRepo.Save(handoff); err != nil {
+			return response.NewInternalServerError()
+		}
+
+		issued = &entity.HandoffCode{
+			Code:      code,
+			QRPayload: u.handoffQRPayload(request.ID, userID, code, handoff.ExpiresAt),
+			ExpiresAt: handoff.ExpiresAt,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issued, nil
+}
+
+// VerifyHandoff checks the code the other side showed userID, typed in or
+// scanned from its QR code. A correct code confirms that userID received
+// their books, completing the exchange once both sides have. Too many
+// wrong codes pause checking the other side's code for a while.
+func (u *exchangeUsecase) VerifyHandoff(request *entity.ExchangeRequest, userID uuid.UUID, code string, qrPayload string) error {
+	if request.RequestedByID != userID && request.RequestedToID != userID {
+		return response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+	}
+	ownerID, name := exchangeCounterpart(request, userID)
+
+	if qrPayload != "" {
+		var err error
+		code, err = u.parseHandoffQRPayload(qrPayload, request.ID, ownerID)
+		if err != nil {
+			return err
+		}
+	}
+	if code == "" {
+		return response.NewBadRequestError("code or qr_payload is required")
+	}
+
+	// Wrong codes are counted in the same transaction, so it commits them
+	// and hands the refusal back here.
+	var refused error
+	err := u.inTransaction(request, func(tx *exchangeTx) error {
+		if _, err := checkExchangeTransition(request, actionConfirm, &userID); err != nil {
+			return err
+		}
+		if request.RequestedByID == userID && request.RequestedByHandoffVerified || request.RequestedToID == userID && request.RequestedToHandoffVerified {
+			return response.NewBadRequestError("you already confirmed receiving the books")
+		}
+
+		handoff, err := tx.exchangeHandoffRepo.Find(request.ID, ownerID)
+		if err != nil && err == gorm.ErrRecordNotFound {
+			return response.NewBadRequestError("the other side has no handoff code yet, ask them to show theirs")
+		} else if err != nil {
+			return response.NewInternalServerError()
+		}
+		now := time.Now()
+		if handoff.LockedUntil != nil && now.Before(*handoff.LockedUntil) {
+			return response.NewTooManyRequestsError(handoff.LockedUntil.Sub(now))
+		}
+		if handoff.UsedAt != nil || now.After(handoff.ExpiresAt) {
+			return response.NewBadRequestError("handoff code expired, ask for a new one")
+		}
+
+		if !hmac.Equal([]byte(u.handoffCodeHash(request.ID, ownerID, code)), []byte(handoff.CodeHash)) {
+			handoff.FailedAttempts++
+			refused = response.NewBadRequestError("wrong handoff code")
+			if handoff.FailedAttempts >= handoffMaxAttempts {
+				lockedUntil := now.Add(handoffLockout)
+				handoff.LockedUntil = &lockedUntil
+				handoff.FailedAttempts = 0
+				refused = response.NewTooManyRequestsError(handoffLockout)
+			}
+			if err := tx.exchangeHandoffRepo.Save(handoff); err != nil {
+				return response.NewInternalServerError()
+			}
+			return nil
+		}
+
+		handoff.UsedAt = &now
+		handoff.FailedAttempts = 0
+		if err := tx.exchangeHandoffRepo.Save(handoff); err != nil {
+			return response.NewInternalServerError()
+		}
+		if request.RequestedByID == userID {
+			request.RequestedByHandoffVerified = true
+		} else {
+			request.RequestedToHandoffVerified = true
+		}
+		err = confirmExchange(tx, request, userID, "handoff code verified")
+		if err != nil {
+			return err
+		}
+
+		msg := name + " entered your handoff code and confirmed receiving the books for book " + request.RequestedBook.Title + "."
+		notifyAfterCommit(tx.Tx, u.notificationService, ownerID, exchangeNotification, msg)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return refused
+}
+
+func newHandoffCode() (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < handoffCodeDigits; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", handoffCodeDigits, n), nil
+}
+
+// handoffCodeHash keys the hash with the server key, so that the short
+// codes cannot be recovered from the database alone.
+func (u *exchangeUsecase) handoffCodeHash(requestID uuid.UUID, ownerID uuid.UUID, code string) string {
+	return hex.EncodeToString(u.handoffMAC("code", requestID.String(), ownerID.String(), code))
+}
+
+func (u *exchangeUsecase) handoffMAC(parts ...string) []byte {
+	mac := hmac.New(sha256.New, u.handoffKey)
+	mac.Write([]byte("handoff:" + strings.Join(parts, ":")))
+	return mac.Sum(nil)
+}
+
+// handoffQRPayload is the code together with whose code it is for which
+// request, signed so that the scanning side can tell it came from here.
+func (u *exchangeUsecase) handoffQRPayload(requestID uuid.UUID, ownerID uuid.UUID, code string, expiresAt time.Time) string {
+	fields := []string{requestID.String(), ownerID.String(), code, strconv.FormatInt(expiresAt.Unix(), 10)}
+	signature := base64.RawURLEncoding.EncodeToString(u.handoffMAC(append([]string{"qr"}, fields...)...))
+	return handoffQRPrefix + strings.Join(fields, ":") + ":" + signature
+}
+
+// parseHandoffQRPayload checks a scanned payload was signed here for the
+// given request and code owner and returns its code.
+func (u *exchangeUsecase) parseHandoffQRPayload(payload string, requestID uuid.UUID, ownerID uuid.UUID) (string, error) {
+	invalid := response.NewBadRequestError("invalid handoff QR code")
+
+	fields := strings.Split(strings.TrimPrefix(payload, handoffQRPrefix), ":")
+	if !strings.HasPrefix(payload, handoffQRPrefix) || len(fields) != 5 {
+		return "", invalid
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(fields[4])
+	if err != nil || !hmac.Equal(signature, u.handoffMAC(append([]string{"qr"}, fields[:4]...)...)) {
+		return "", invalid
+	}
+	if fields[0] != requestID.String() || fields[1] != ownerID.String() {
+		return "", invalid
+	}
+	expiresAt, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return "", invalid
+	}
+	if time.Now().Unix() > expiresAt {
+		return "", response.NewBadRequestError("handoff code expired, ask for a new one")
+	}
+	return fields[2], nil
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+)
+
+func TestNewHandoffCode(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		code, err := newHandoffCode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(code) != handoffCodeDigits || strings.Trim(code, "0123456789") != "" {
+			t.Fatalf("newHandoffCode() = %q, want %d digits", code, handoffCodeDigits)
+		}
+	}
+}
+
+func TestHandoffCodeHash(t *testing.T) {
+	u := &exchangeUsecase{handoffKey: []byte("key")}
+	requestID, ownerID := uuid.New(), uuid.New()
+
+	hash := u.handoffCodeHash(requestID, ownerID, "123456")
+	if hash != u.handoffCodeHash(requestID, ownerID, "123456") {
+		t.Error("hash not deterministic")
+	}
+	if hash == u.handoffCodeHash(requestID, ownerID, "123457") {
+		t.Error("different codes hash alike")
+	}
+	if hash == u.handoffCodeHash(requestID, uuid.New(), "123456") {
+		t.Error("codes of different owners hash alike")
+	}
+	other := &exchangeUsecase{handoffKey: []byte("other key")}
+	if hash == other.handoffCodeHash(requestID, ownerID, "123456") {
+		t.Error("hash does not depend on the key")
+	}
+}
+
+func TestParseHandoffQRPayload(t *testing.T) {
+	u := &exchangeUsecase{handoffKey: []byte("key")}
+	requestID, ownerID := uuid.New(), uuid.New()
+	valid := u.handoffQRPayload(requestID, ownerID, "123456", time.Now().Add(time.Minute))
+
+	tests := []struct {
+		name       string
+		payload    string
+		requestID  uuid.UUID
+		ownerID    uuid.UUID
+		wantCode   string
+		wantStatus int
+	}{
+		{"valid", valid, requestID, ownerID, "123456", 0},
+		{"other request", valid, uuid.New(), ownerID, "", http.StatusBadRequest},
+		{"other owner", valid, requestID, uuid.New(), "", http.StatusBadRequest},
+		{"tampered code", strings.Replace(valid, ":123456:", ":654321:", 1), requestID, ownerID, "", http.StatusBadRequest},
+		{"signed with another key", (&exchangeUsecase{handoffKey: []byte("other")}).handoffQRPayload(requestID, ownerID, "123456", time.Now().Add(time.Minute)), requestID, ownerID, "", http.StatusBadRequest},
+		{"expired", u.handoffQRPayload(requestID, ownerID, "123456", time.Now().Add(-time.Minute)), requestID, ownerID, "", http.StatusBadRequest},
+		{"missing prefix", strings.TrimPrefix(valid, handoffQRPrefix), requestID, ownerID, "", http.StatusBadRequest},
+		{"garbage", "gyanpass-handoff:x", requestID, ownerID, "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := u.parseHandoffQRPayload(tt.payload, tt.requestID, tt.ownerID)
+			if tt.wantStatus != 0 {
+				if err == nil {
+					t.Fatalf("got code %q, want status %d", code, tt.wantStatus)
+				}
+				if got := response.Status(err); got != tt.wantStatus {
+					t.Fatalf("got status %d, want %d", got, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v, want none", err)
+			}
+			if code != tt.wantCode {
+				t.Errorf("got code %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
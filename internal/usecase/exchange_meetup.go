@@ -0,0 +1,275 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/calendar"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	meetupMaxLead       = 30 * 24 * time.Hour // how far ahead a slot may be proposed
+	meetupDuration      = time.Hour           // length of the calendar event
+	meetupReminderLead  = 24 * time.Hour
+	meetupReminderBatch = 100
+	meetupTimeLayout    = "Mon 2 Jan 2006 15:04 MST"
+)
+
+// ProposeMeetup offers the other side a slot for handing over the books
+// of an accepted exchange.
+func (u *exchangeUsecase) ProposeMeetup(request *entity.ExchangeRequest, slot *entity.ExchangeMeetup, userID uuid.UUID) error {
+	if err := validateMeetupSlot(slot); err != nil {
+		return err
+	}
+
+	return u.inTransaction(request, func(tx *exchangeTx) error {
+		err := tx.apply(request, actionProposeMeetup, &userID, describeMeetup(slot))
+		if err != nil {
+			return err
+		}
+		return u.createMeetup(tx, request, slot, userID, "proposed")
+	})
+}
+
+// RescheduleMeetup replaces a slot that is still open or already accepted
+// with a new one. Until the other side accepts the new slot, none is
+// agreed.
+func (u *exchangeUsecase) RescheduleMeetup(request *entity.ExchangeRequest, meetupID uuid.UUID, slot *entity.ExchangeMeetup, userID uuid.UUID) error {
+	if err := validateMeetupSlot(slot); err != nil {
+		return err
+	}
+
+	return u.inTransaction(request, func(tx *exchangeTx) error {
+		err := tx.apply(request, actionRescheduleMeetup, &userID, describeMeetup(slot))
+		if err != nil {
+			return err
+		}
+
+		previous, err := findMeetup(tx, request, meetupID)
+		if err != nil {
+			return err
+		}
+		if previous.Status != entity.MeetupProposed && previous.Status != entity.MeetupAccepted {
+			return response.NewBadRequestError("cannot reschedule a slot that is " + previous.Status)
+		}
+		now := time.Now()
+		previous.Status = entity.MeetupRescheduled
+		previous.RespondedAt = &now
+		if err := tx.exchangeMeetupRepo.Update(previous); err != nil {
+			return response.NewInternalServerError()
+		}
+
+		slot.RescheduledFromID = &previous.ID
+		return u.createMeetup(tx, request, slot, userID, "asked to reschedule the meetup to")
+	})
+}
+
+// createMeetup stores slot as proposed by userID and tells the other side.
+func (u *exchangeUsecase) createMeetup(tx *exchangeTx, request *entity.ExchangeRequest, slot *entity.ExchangeMeetup, userID uuid.UUID, verb string) error {
+	slot.ExchangeRequestID = request.ID
+	slot.ProposedByID = userID
+	slot.Status = entity.MeetupProposed
+	if err := tx.exchangeMeetupRepo.Create(slot); err != nil {
+		return response.NewInternalServerError()
+	}
+
+	recipientID, name := exchangeCounterpart(request, userID)
+	msg := name + " " + verb + " " + describeMeetup(slot) + " for book " + request.RequestedBook.Title + "."
+	notifyAfterCommit(tx.Tx, u.notificationService, recipientID, exchangeNotification, msg)
+	return nil
+}
+
+// AcceptMeetup agrees on a slot the other side proposed. A slot accepted
+// before is rescheduled by it and the slots still open are superseded.
+// Agreeing on a slot also agrees on the meetup, so the exact pickup
+// points become visible to both sides.
+func (u *exchangeUsecase) AcceptMeetup(request *entity.ExchangeRequest, meetupID uuid.UUID, userID uuid.UUID) (*entity.ExchangeMeetup, error) {
+	var meetup *entity.ExchangeMeetup
+	err := u.inTransaction(request, func(tx *exchangeTx) error {
+		var err error
+		meetup, err = findMeetup(tx, request, meetupID)
+		if err != nil {
+			return err
+		}
+		if meetup.ProposedByID == userID {
+			return response.NewBadRequestError("you cannot accept a slot you proposed")
+		}
+		if meetup.Status != entity.MeetupProposed {
+			return response.NewBadRequestError("cannot accept a slot that is " + meetup.Status)
+		}
+		now := time.Now()
+		if !meetup.StartsAt.After(now) {
+			return response.NewBadRequestError("slot has already passed, propose another one")
+		}
+
+		err = tx.apply(request, actionAcceptMeetup, &userID, describeMeetup(meetup))
+		if err != nil {
+			return err
+		}
+
+		previous, err := tx.exchangeMeetupRepo.FindAccepted(request.ID)
+		if err == nil {
+			previous.Status = entity.MeetupRescheduled
+			previous.RespondedAt = &now
+			err = tx.exchangeMeetupRepo.Update(previous)
+		}
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return response.NewInternalServerError()
+		}
+		meetup.Status = entity.MeetupAccepted
+		meetup.RespondedAt = &now
+		if err := tx.exchangeMeetupRepo.Update(meetup); err != nil {
+			return response.NewInternalServerError()
+		}
+		if err := tx.exchangeMeetupRepo.CloseOpen(request.ID, entity.MeetupSuperseded, now); err != nil {
+			return response.NewInternalServerError()
+		}
+
+		// The confirmation deadline now counts from the meetup, so the
+		// sides are reminded of it again.
+		request.LastReminderAt = nil
+		agreed := request.MeetupAgreedAt == nil
+		if agreed {
+			request.RequestedByMeetupAgreed = true
+			request.RequestedToMeetupAgreed = true
+			request.MeetupAgreedAt = &now
+		}
+		if err := tx.exchangeRepo.Update(request); err != nil {
+			return response.NewInternalServerError()
+		}
+
+		recipientID, name := exchangeCounterpart(request, userID)
+		msg := name + " accepted " + describeMeetup(meetup) + " for book " + request.RequestedBook.Title + "."
+		if agreed {
+			msg += " Exact pickup points are now visible."
+		}
+		notifyAfterCommit(tx.Tx, u.notificationService, recipientID, exchangeNotification, msg)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return meetup, nil
+}
+
+func (u *exchangeUsecase) GetExchangeMeetups(request *entity.ExchangeRequest, userID uuid.UUID) ([]entity.ExchangeMeetup, error) {
+	if request.RequestedByID != userID && request.RequestedToID != userID {
+		return nil, response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+	}
+
+	meetups, err := u.exchangeMeetupRepo.FindByRequestID(request.ID)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	return meetups, nil
+}
+
+// GetMeetupCalendar renders the agreed slot as an iCalendar file.
+func (u *exchangeUsecase) GetMeetupCalendar(request *entity.ExchangeRequest, userID uuid.UUID) ([]byte, error) {
+	if request.RequestedByID != userID && request.RequestedToID != userID {
+		return nil, response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+	}
+
+	meetup, err := u.exchangeMeetupRepo.FindAccepted(request.ID)
+	if err != nil && err == gorm.ErrRecordNotFound {
+		return nil, response.NewNotFoundError("agreed meetup", fmt.Sprintf("%v", request.ID))
+	} else if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+
+	name := request.RequestedTo.FirstName
+	if request.RequestedToID == userID {
+		name = request.RequestedBy.FirstName
+	}
+	description := "Handing over " + bundleTitles(sideItems(request, entity.ItemRequested))
+	if offered := sideItems(request, entity.ItemOffered); len(offered) > 0 {
+		description += " for " + bundleTitles(offered)
+	}
+	if meetup.Note != "" {
+		description += ".\n\n" + meetup.Note
+	}
+
+	return calendar.Encode(calendar.Event{
+		UID:         meetup.ID.String() + "@gyanpass",
+		Start:       meetup.StartsAt,
+		End:         meetup.StartsAt.Add(meetupDuration),
+		Summary:     "Book exchange with " + name,
+		Description: description,
+		Location:    meetup.Place,
+		Latitude:    meetup.Latitude,
+		Longitude:   meetup.Longitude,
+		Stamp:       *meetup.RespondedAt,
+	}), nil
+}
+
+// RunMeetupReminders reminds both sides of an accepted exchange once,
+// a day before their meetup. Slots accepted within that day need no
+// reminder, as accepting them was already notified.
+func (u *exchangeUsecase) RunMeetupReminders(ctx context.Context) {
+	now := time.Now()
+
+	meetups, err := u.exchangeMeetupRepo.FindUnremindedStartingBefore(now.Add(meetupReminderLead), meetupReminderBatch)
+	if err != nil {
+		log.Printf("Failed Finding Meetups To Remind: %v\n", err)
+		return
+	}
+	for _, meetup := range meetups {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := u.exchangeMeetupRepo.MarkReminded(meetup.ID, now); err != nil {
+			log.Printf("Failed Updating Meetup %v: %v\n", meetup.ID, err)
+			continue
+		}
+		if meetup.RespondedAt != nil && meetup.StartsAt.Sub(*meetup.RespondedAt) < meetupReminderLead {
+			continue
+		}
+
+		request, err := u.exchangeRepo.FindByID(meetup.ExchangeRequestID)
+		if err != nil {
+			log.Printf("Failed Finding Exchange Request %v: %v\n", meetup.ExchangeRequestID, err)
+			continue
+		}
+		msg := "Reminder: the meetup for book " + request.RequestedBook.Title + " is " + describeMeetup(&meetup) + "."
+		u.notify(request.RequestedByID, msg)
+		u.notify(request.RequestedToID, msg)
+	}
+}
+
+// findMeetup loads a slot of the request inside tx.
+func findMeetup(tx *exchangeTx, request *entity.ExchangeRequest, meetupID uuid.UUID) (*entity.ExchangeMeetup, error) {
+	meetup, err := tx.exchangeMeetupRepo.FindByID(meetupID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, response.NewInternalServerError()
+	}
+	if err != nil || meetup.ExchangeRequestID != request.ID {
+		return nil, response.NewNotFoundError("meetup", fmt.Sprintf("%v", meetupID))
+	}
+	return meetup, nil
+}
+
+func validateMeetupSlot(slot *entity.ExchangeMeetup) error {
+	now := time.Now()
+	if !slot.StartsAt.After(now) {
+		return response.NewBadRequestError("starts_at must be in the future")
+	}
+	if slot.StartsAt.After(now.Add(meetupMaxLead)) {
+		return response.NewBadRequestError(fmt.Sprintf("starts_at must be within %d days", int(meetupMaxLead.Hours()/24)))
+	}
+	if (slot.Latitude == nil) != (slot.Longitude == nil) {
+		return response.NewBadRequestError("latitude and longitude go together")
+	}
+	return nil
+}
+
+// describeMeetup names the meetup's time in UTC, zone included, as the
+// server knows neither side's time zone.
+func describeMeetup(meetup *entity.ExchangeMeetup) string {
+	return "on " + meetup.StartsAt.UTC().Format(meetupTimeLayout) + " at " + meetup.Place
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/notification"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const maxMessageAttachments = 4
+
+// openThreadStatuses are those of exchanges still under way, whose threads
+// take new messages. Threads of every other exchange are archived.
+var openThreadStatuses = []entity.ExchangeStatus{entity.ExchangePending, entity.ExchangeCountered, entity.ExchangeAccepted, entity.ExchangeDisputed}
+
+type ExchangeMessageUsecase interface {
+	SendMessage(request *entity.ExchangeRequest, senderID uuid.UUID, body string, imageIDs []uuid.UUID) (*entity.ExchangeMessage, error)
+	GetThread(request *entity.ExchangeRequest, userID uuid.UUID) (*entity.ExchangeThread, error)
+	GetUnreadCounts(userID uuid.UUID) ([]entity.ExchangeThreadUnread, error)
+}
+
+type exchangeMessageUsecase struct {
+	exchangeMessageRepo repository.ExchangeMessageRepository
+	exchangeRepo        repository.ExchangeRepository
+	imageRepo           repository.ImageRepository
+	transactor          repository.Transactor
+	notificationService notification.Service
+}
+
+func NewExchangeMessageUsecase(exchangeMessageRepo repository.ExchangeMessageRepository, exchangeRepo repository.ExchangeRepository, imageRepo repository.ImageRepository, transactor repository.Transactor, notificationService notification.Service) ExchangeMessageUsecase {
+	return &exchangeMessageUsecase{exchangeMessageRepo, exchangeRepo, imageRepo, transactor, notificationService}
+}
+
+// SendMessage adds a message to the request's thread. The recipient is
+// notified of the first message they have not read yet, not of every one.
+func (u *exchangeMessageUsecase) SendMessage(request *entity.ExchangeRequest, senderID uuid.UUID, body string, imageIDs []uuid.UUID) (*entity.ExchangeMessage, error) {
+	if request.RequestedByID != senderID && request.RequestedToID != senderID {
+		return nil, response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+	}
+	if body == "" && len(imageIDs) == 0 {
+		return nil, response.NewBadRequestError("a message needs a body or an attachment")
+	}
+	attachments, err := u.findAttachableImages(senderID, imageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	recipientID, name := request.RequestedToID, request.RequestedBy.FirstName
+	if request.RequestedToID == senderID {
+		recipientID, name = request.RequestedByID, request.RequestedTo.FirstName
+	}
+	message := &entity.ExchangeMessage{
+		ExchangeRequestID: request.ID,
+		SenderID:          senderID,
+		Body:              body,
+	}
+
+	err = u.transactor.Run(func(tx *repository.Tx) error {
+		// Locked so that the exchange cannot end while the message is
+		// being added.
+		current, err := u.exchangeRepo.WithTx(tx).LockByID(request.ID)
+		if err != nil && err == gorm.ErrRecordNotFound {
+			return response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+		} else if err != nil {
+			return response.NewInternalServerError()
+		}
+		if !slices.Contains(openThreadStatuses, current.Status) {
+			return response.NewBadRequestError(fmt.Sprintf("the exchange is %s, its messages are archived", current.Status))
+		}
+
+		messageRepo := u.exchangeMessageRepo.WithTx(tx)
+		unread, err := messageRepo.CountUnread(request.ID, recipientID)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		if err := messageRepo.Create(message); err != nil {
+			return response.NewInternalServerError()
+		}
+		err = u.imageRepo.WithTx(tx).SetMessageImages(message.ID, imageIDs)
+		if err != nil && err == gorm.ErrRecordNotFound {
+			return response.NewConflictError("image", "attached elsewhere meanwhile")
+		} else if err != nil {
+			return response.NewInternalServerError()
+		}
+
+		if unread == 0 {
+			msg := name + " sent you a message about book " + request.RequestedBook.Title + "."
+			notifyAfterCommit(tx, u.notificationService, recipientID, "exchange message", msg)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, transactionError(err)
+	}
+
+	for i := range attachments {
+		attachments[i].MessageID = &message.ID
+		attachments[i].Position = i
+	}
+	message.Attachments = attachments
+	return message, nil
+}
+
+// GetThread returns the request's messages, oldest first, and marks those
+// sent to userID as read.
+func (u *exchangeMessageUsecase) GetThread(request *entity.ExchangeRequest, userID uuid.UUID) (*entity.ExchangeThread, error) {
+	if request.RequestedByID != userID && request.RequestedToID != userID {
+		return nil, response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+	}
+
+	messages, err := u.exchangeMessageRepo.FindByRequestID(request.ID)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	err = u.exchangeMessageRepo.MarkRead(request.ID, userID, time.Now())
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+
+	return &entity.ExchangeThread{
+		ExchangeRequestID: request.ID,
+		Archived:          !slices.Contains(openThreadStatuses, request.Status),
+		Messages:          messages,
+	}, nil
+}
+
+func (u *exchangeMessageUsecase) GetUnreadCounts(userID uuid.UUID) ([]entity.ExchangeThreadUnread, error) {
+	counts, err := u.exchangeMessageRepo.CountUnreadByUserID(userID)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	return counts, nil
+}
+
+// findAttachableImages returns the images in the order of imageIDs after
+// checking that each one was uploaded by the sender and is not used
+// elsewhere.
+func (u *exchangeMessageUsecase) findAttachableImages(senderID uuid.UUID, imageIDs []uuid.UUID) ([]entity.Image, error) {
+	if len(imageIDs) == 0 {
+		return nil, nil
+	}
+	if len(imageIDs) > maxMessageAttachments {
+		return nil, response.NewBadRequestError(fmt.Sprintf("a message can have at most %d attachments", maxMessageAttachments))
+	}
+
+	fetched, err := u.imageRepo.FindByIDs(imageIDs)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	byID := make(map[uuid.UUID]entity.Image, len(fetched))
+	for _, image := range fetched {
+		byID[image.ID] = image
+	}
+
+	images := make([]entity.Image, 0, len(imageIDs))
+	seen := make(map[uuid.UUID]bool, len(imageIDs))
+	for _, id := range imageIDs {
+		image, ok := byID[id]
+		if !ok || image.UserID != senderID {
+			return nil, response.NewNotFoundError("image", id.String())
+		}
+		if seen[id] {
+			return nil, response.NewBadRequestError("duplicate image id " + id.String())
+		}
+		if image.BookID != nil || image.MessageID != nil {
+			return nil, response.NewConflictError("image", id.String())
+		}
+		seen[id] = true
+		images = append(images, image)
+	}
+	return images, nil
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+)
+
+func TestCounterExchangeRevisions(t *testing.T) {
+	s := newExchangeStore()
+	requester, owner := uuid.New(), uuid.New()
+	s.addBook(1, owner, true)
+	for _, id := range []uint{10, 11, 12} {
+		s.addBook(id, requester, true)
+	}
+	books := func(ids ...uint) []*entity.Book {
+		var list []*entity.Book
+		for _, id := range ids {
+			book := s.books[id]
+			list = append(list, &book)
+		}
+		return list
+	}
+	u := s.usecase()
+	requestID := s.addRequest(t, requester, owner, entity.ExchangePending, []uint{1}, []uint{10}).ID
+
+	steps := []struct {
+		name       string
+		userID     uuid.UUID
+		offered    []uint
+		wantStatus int // 0 when the counter goes through
+		want       entity.ExchangeStatus
+	}{
+		{"owner counters", owner, []uint{11}, 0, entity.ExchangeCountered},
+		{"owner cannot counter twice in a row", owner, []uint{12}, http.StatusBadRequest, entity.ExchangeCountered},
+		{"requester counters back with a bundle", requester, []uint{12, 11}, 0, entity.ExchangePending},
+		{"the same books again", owner, []uint{11, 12}, http.StatusBadRequest, entity.ExchangePending},
+		{"a book of the owner's own", owner, []uint{1}, http.StatusBadRequest, entity.ExchangePending},
+	}
+	for _, step := range steps {
+		// Loaded afresh each time, as every call comes with its own request.
+		request := s.load(requestID)
+		err := u.CounterExchange(request, books(step.offered...), step.userID, step.name)
+		if step.wantStatus == 0 && err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if step.wantStatus != 0 && response.Status(err) != step.wantStatus {
+			t.Fatalf("%s: got %v, want status %d", step.name, err, step.wantStatus)
+		}
+		if got := s.requests[requestID].Status; got != step.want {
+			t.Fatalf("%s: request is %q, want %q", step.name, got, step.want)
+		}
+	}
+
+	// The thread starts with what was offered first and gains one revision
+	// per counter that went through.
+	wantOffers := []struct {
+		by    uuid.UUID
+		books []uint
+	}{
+		{requester, []uint{10}},
+		{owner, []uint{11}},
+		{requester, []uint{11, 12}},
+	}
+	if len(s.offers) != len(wantOffers) {
+		t.Fatalf("got %d offers, want %d", len(s.offers), len(wantOffers))
+	}
+	for i, want := range wantOffers {
+		offer := s.offers[i]
+		if offer.Revision != i+1 {
+			t.Errorf("offer %d has revision %d", i, offer.Revision)
+		}
+		if offer.ProposedByID != want.by {
+			t.Errorf("revision %d proposed by the wrong side", offer.Revision)
+		}
+		ids := slices.Clone(offer.OfferedBookIDs)
+		slices.Sort(ids)
+		if !slices.Equal(ids, want.books) {
+			t.Errorf("revision %d offers %v, want %v", offer.Revision, ids, want.books)
+		}
+	}
+
+	stored := s.requests[requestID]
+	if got := exchangeBookIDs(&stored); !slices.Equal(got, []uint{1, 11, 12}) {
+		t.Errorf("request holds books %v, want [1 11 12]", got)
+	}
+}
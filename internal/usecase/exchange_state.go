@@ -0,0 +1,352 @@
+package usecase
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+)
+
+type exchangeAction string
+
+const (
+	actionRequest          exchangeAction = "request"
+	actionPropose          exchangeAction = "propose"
+	actionAccept           exchangeAction = "accept"
+	actionAutoAccept       exchangeAction = "auto_accept"
+	actionCounter          exchangeAction = "counter"
+	actionCounterBack      exchangeAction = "counter_back"
+	actionAcceptOffer      exchangeAction = "accept_offer"
+	actionDecline          exchangeAction = "decline"
+	actionAutoDecline      exchangeAction = "auto_decline"
+	actionWithdraw         exchangeAction = "withdraw"
+	actionConfirm          exchangeAction = "confirm"
+	actionComplete         exchangeAction = "complete"
+	actionAgreeMeetup      exchangeAction = "agree_meetup"
+	actionProposeMeetup    exchangeAction = "propose_meetup"
+	actionRescheduleMeetup exchangeAction = "reschedule_meetup"
+	actionAcceptMeetup     exchangeAction = "accept_meetup"
+	actionDelete           exchangeAction = "delete"
+	actionExpire           exchangeAction = "expire"
+	actionLapse            exchangeAction = "lapse"
+	actionEscalate         exchangeAction = "escalate"
+	actionRequestCancel    exchangeAction = "request_cancel"
+	actionCancel           exchangeAction = "cancel"
+	actionDispute          exchangeAction = "dispute"
+	actionResolveExchanged exchangeAction = "resolve_exchanged"
+	actionResolveCancelled exchangeAction = "resolve_cancelled"
+)
+
+// exchangeActor is the role someone acts in on a request.
+type exchangeActor string
+
+const (
+	actorRequester exchangeActor = "requester"
+	actorOwner     exchangeActor = "owner"
+	actorSystem    exchangeActor = "system"
+)
+
+type exchangeTransition struct {
+	from   []entity.ExchangeStatus
+	to     entity.ExchangeStatus // empty when the request is removed
+	actors []exchangeActor
+	guard  func(request *entity.ExchangeRequest) error
+	onLegs bool // people may take it on legs of a trade cycle too
+}
+
+// exchangeTransitions is every change an exchange request can go through.
+// Anything not listed here is refused.
+var exchangeTransitions = map[exchangeAction]exchangeTransition{
+	actionRequest: {
+		from:   []entity.ExchangeStatus{""},
+		to:     entity.ExchangePending,
+		actors: []exchangeActor{actorRequester},
+		guard:  booksAvailable,
+	},
+	actionPropose: {
+		from:   []entity.ExchangeStatus{""},
+		to:     entity.ExchangePending,
+		actors: []exchangeActor{actorSystem},
+		guard:  booksAvailable,
+	},
+	actionAccept: {
+		from:   []entity.ExchangeStatus{entity.ExchangePending},
+		to:     entity.ExchangeAccepted,
+		actors: []exchangeActor{actorOwner},
+		guard:  booksAvailable,
+	},
+	actionAutoAccept: {
+		from:   []entity.ExchangeStatus{entity.ExchangePending},
+		to:     entity.ExchangeAccepted,
+		actors: []exchangeActor{actorSystem},
+		guard:  booksAvailable,
+	},
+	actionCounter: {
+		from:   []entity.ExchangeStatus{entity.ExchangePending},
+		to:     entity.ExchangeCountered,
+		actors: []exchangeActor{actorOwner},
+		guard:  booksAvailable,
+	},
+	actionCounterBack: {
+		from:   []entity.ExchangeStatus{entity.ExchangeCountered},
+		to:     entity.ExchangePending,
+		actors: []exchangeActor{actorRequester},
+		guard:  booksAvailable,
+	},
+	actionAcceptOffer: {
+		from:   []entity.ExchangeStatus{entity.ExchangeCountered},
+		to:     entity.ExchangeAccepted,
+		actors: []exchangeActor{actorRequester},
+		guard:  booksAvailable,
+	},
+	// Either side may walk away while the request is still negotiated.
+	actionDecline: {
+		from:   []entity.ExchangeStatus{entity.ExchangePending, entity.ExchangeCountered},
+		to:     entity.ExchangeDeclined,
+		actors: []exchangeActor{actorOwner, actorRequester},
+	},
+	actionAutoDecline: {
+		from:   []entity.ExchangeStatus{entity.ExchangePending, entity.ExchangeCountered},
+		to:     entity.ExchangeDeclined,
+		actors: []exchangeActor{actorSystem},
+	},
+	actionWithdraw: {
+		from:   []entity.ExchangeStatus{entity.ExchangePending, entity.ExchangeCountered},
+		to:     entity.ExchangeWithdrawn,
+		actors: []exchangeActor{actorRequester, actorOwner},
+	},
+	actionConfirm: {
+		from:   []entity.ExchangeStatus{entity.ExchangeAccepted},
+		to:     entity.ExchangeAccepted,
+		actors: []exchangeActor{actorRequester, actorOwner},
+		onLegs: true,
+	},
+	actionComplete: {
+		from:   []entity.ExchangeStatus{entity.ExchangeAccepted},
+		to:     entity.ExchangeExchanged,
+		actors: []exchangeActor{actorSystem},
+		guard:  bothConfirmed,
+	},
+	actionAgreeMeetup: {
+		from:   []entity.ExchangeStatus{entity.ExchangeAccepted},
+		to:     entity.ExchangeAccepted,
+		actors: []exchangeActor{actorRequester, actorOwner},
+		guard:  meetupNotAgreed,
+		onLegs: true,
+	},
+	// Slots for the handover leave the status as it is and are only
+	// recorded in the timeline.
+	actionProposeMeetup: {
+		from:   []entity.ExchangeStatus{entity.ExchangeAccepted},
+		to:     entity.ExchangeAccepted,
+		actors: []exchangeActor{actorRequester, actorOwner},
+		onLegs: true,
+	},
+	actionRescheduleMeetup: {
+		from:   []entity.ExchangeStatus{entity.ExchangeAccepted},
+		to:     entity.ExchangeAccepted,
+		actors: []exchangeActor{actorRequester, actorOwner},
+		onLegs: true,
+	},
+	actionAcceptMeetup: {
+		from:   []entity.ExchangeStatus{entity.ExchangeAccepted},
+		to:     entity.ExchangeAccepted,
+		actors: []exchangeActor{actorRequester, actorOwner},
+		onLegs: true,
+	},
+	actionExpire: {
+		from:   []entity.ExchangeStatus{entity.ExchangePending, entity.ExchangeCountered},
+		to:     entity.ExchangeExpired,
+		actors: []exchangeActor{actorSystem},
+	},
+	actionLapse: {
+		from:   []entity.ExchangeStatus{entity.ExchangeAccepted},
+		to:     entity.ExchangeExpired,
+		actors: []exchangeActor{actorSystem},
+		guard:  noneConfirmed,
+	},
+	// Once one side confirmed a handover, an exchange not confirmed in
+	// time goes to the admins instead of lapsing. A trade cycle goes as a
+	// whole, so its unconfirmed legs go along with one that was.
+	actionEscalate: {
+		from:   []entity.ExchangeStatus{entity.ExchangeAccepted},
+		to:     entity.ExchangeDisputed,
+		actors: []exchangeActor{actorSystem},
+		guard:  escalatable,
+	},
+	// Either side may call off an accepted exchange, or ask an admin to
+	// decide how it ended. Admins act as the system. Legs of a trade cycle
+	// are left out: ending one alone would leave the others handing over
+	// books for nothing in return.
+	actionRequestCancel: {
+		from:   []entity.ExchangeStatus{entity.ExchangeAccepted},
+		to:     entity.ExchangeAccepted,
+		actors: []exchangeActor{actorRequester, actorOwner},
+	},
+	actionCancel: {
+		from:   []entity.ExchangeStatus{entity.ExchangeAccepted},
+		to:     entity.ExchangeCancelled,
+		actors: []exchangeActor{actorRequester, actorOwner},
+	},
+	actionDispute: {
+		from:   []entity.ExchangeStatus{entity.ExchangeAccepted},
+		to:     entity.ExchangeDisputed,
+		actors: []exchangeActor{actorRequester, actorOwner},
+	},
+	actionResolveExchanged: {
+		from:   []entity.ExchangeStatus{entity.ExchangeDisputed},
+		to:     entity.ExchangeExchanged,
+		actors: []exchangeActor{actorSystem},
+	},
+	actionResolveCancelled: {
+		from:   []entity.ExchangeStatus{entity.ExchangeDisputed},
+		to:     entity.ExchangeCancelled,
+		actors: []exchangeActor{actorSystem},
+	},
+	actionDelete: {
+		from:   []entity.ExchangeStatus{entity.ExchangePending, entity.ExchangeCountered, entity.ExchangeDeclined, entity.ExchangeWithdrawn, entity.ExchangeExpired, entity.ExchangeCancelled},
+		actors: []exchangeActor{actorRequester},
+	},
+}
+
+func booksAvailable(request *entity.ExchangeRequest) error {
+	for _, side := range []string{entity.ItemRequested, entity.ItemOffered} {
+		for _, item := range sideItems(request, side) {
+			if !item.Book.IsActive {
+				return response.NewConflictError("book", side+" book already in exchanging process")
+			}
+		}
+	}
+	return nil
+}
+
+func bothConfirmed(request *entity.ExchangeRequest) error {
+	if !request.RequestedByConfirmed || !request.RequestedToConfirmed {
+		return response.NewBadRequestError("both sides have to confirm the exchange")
+	}
+	return nil
+}
+
+func noneConfirmed(request *entity.ExchangeRequest) error {
+	if request.RequestedByConfirmed || request.RequestedToConfirmed {
+		return response.NewBadRequestError("a handover was already confirmed")
+	}
+	return nil
+}
+
+func partlyConfirmed(request *entity.ExchangeRequest) error {
+	if request.RequestedByConfirmed == request.RequestedToConfirmed {
+		return response.NewBadRequestError("exactly one side has to have confirmed the exchange")
+	}
+	return nil
+}
+
+func escalatable(request *entity.ExchangeRequest) error {
+	if request.TradeCycleID != nil {
+		return nil
+	}
+	return partlyConfirmed(request)
+}
+
+func meetupNotAgreed(request *entity.ExchangeRequest) error {
+	if request.MeetupAgreedAt != nil {
+		return response.NewBadRequestError("meetup is already agreed")
+	}
+	return nil
+}
+
+// exchangeActorOf tells in which role actorID acts on the request. A nil
+// actorID is the system.
+func exchangeActorOf(request *entity.ExchangeRequest, actorID *uuid.UUID) exchangeActor {
+	switch {
+	case actorID == nil:
+		return actorSystem
+	case *actorID == request.RequestedByID:
+		return actorRequester
+	case *actorID == request.RequestedToID:
+		return actorOwner
+	}
+	return ""
+}
+
+// checkExchangeTransition reports whether actorID may perform action on
+// the request in its current state.
+func checkExchangeTransition(request *entity.ExchangeRequest, action exchangeAction, actorID *uuid.UUID) (exchangeTransition, error) {
+	transition, ok := exchangeTransitions[action]
+	if !ok {
+		return transition, response.NewInternalServerError()
+	}
+	verb := strings.ReplaceAll(string(action), "_", " ")
+
+	actor := exchangeActorOf(request, actorID)
+	if actor == "" {
+		return transition, response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+	}
+	if request.TradeCycleID != nil && actor != actorSystem && !transition.onLegs {
+		return transition, response.NewBadRequestError(fmt.Sprintf("cannot %s a leg of a trade cycle, answer the cycle instead", verb))
+	}
+	if !slices.Contains(transition.actors, actor) {
+		return transition, response.NewBadRequestError(fmt.Sprintf("you cannot %s this request as its %s", verb, actor))
+	}
+	if !slices.Contains(transition.from, request.Status) {
+		return transition, response.NewBadRequestError(fmt.Sprintf("cannot %s a request that is %s", verb, request.Status))
+	}
+	if transition.guard != nil {
+		if err := transition.guard(request); err != nil {
+			return transition, err
+		}
+	}
+	return transition, nil
+}
+
+// applyExchangeTransition performs action on the request, saves it and
+// records the change in its timeline. Removing a request leaves no event,
+// as its timeline goes with it.
+func applyExchangeTransition(exchangeRepo repository.ExchangeRepository, eventRepo repository.ExchangeEventRepository, request *entity.ExchangeRequest, action exchangeAction, actorID *uuid.UUID, reason string) error {
+	return recordExchangeTransition(exchangeRepo, eventRepo, request, action, actorID, actorID, reason)
+}
+
+// recordExchangeTransition is applyExchangeTransition that checks the
+// action as taken by actorID but records authorID as its author, for an
+// admin acting as the system.
+func recordExchangeTransition(exchangeRepo repository.ExchangeRepository, eventRepo repository.ExchangeEventRepository, request *entity.ExchangeRequest, action exchangeAction, actorID *uuid.UUID, authorID *uuid.UUID, reason string) error {
+	transition, err := checkExchangeTransition(request, action, actorID)
+	if err != nil {
+		return err
+	}
+
+	if transition.to == "" {
+		if err := exchangeRepo.Delete(request); err != nil {
+			return response.NewInternalServerError()
+		}
+		return nil
+	}
+
+	from := request.Status
+	request.Status = transition.to
+	if from == "" {
+		err = exchangeRepo.Create(request)
+	} else {
+		err = exchangeRepo.Update(request)
+	}
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+
+	err = eventRepo.Create(&entity.ExchangeEvent{
+		ExchangeRequestID: request.ID,
+		Action:            string(action),
+		FromStatus:        from,
+		ToStatus:          transition.to,
+		ActorID:           authorID,
+		Reason:            reason,
+	})
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+)
+
+func TestCheckExchangeTransition(t *testing.T) {
+	requester, owner, stranger := uuid.New(), uuid.New(), uuid.New()
+	cycleID := uuid.New()
+
+	request := func(status entity.ExchangeStatus, change func(r *entity.ExchangeRequest)) *entity.ExchangeRequest {
+		r := &entity.ExchangeRequest{
+			ID:            uuid.New(),
+			RequestedByID: requester,
+			RequestedToID: owner,
+			RequestedBook: entity.Book{ID: 1, IsActive: true},
+			Status:        status,
+		}
+		if change != nil {
+			change(r)
+		}
+		return r
+	}
+	agreedAt := time.Now()
+	meetupAgreed := func(r *entity.ExchangeRequest) { r.MeetupAgreedAt = &agreedAt }
+	confirmedBy := func(requesterSide, ownerSide bool) func(r *entity.ExchangeRequest) {
+		return func(r *entity.ExchangeRequest) {
+			r.RequestedByConfirmed = requesterSide
+			r.RequestedToConfirmed = ownerSide
+		}
+	}
+	cycleLeg := func(r *entity.ExchangeRequest) { r.TradeCycleID = &cycleID }
+
+	tests := []struct {
+		name       string
+		request    *entity.ExchangeRequest
+		action     exchangeAction
+		actorID    *uuid.UUID
+		wantStatus int // 0 when allowed
+		wantTo     entity.ExchangeStatus
+	}{
+		{"requester requests", request("", nil), actionRequest, &requester, 0, entity.ExchangePending},
+		{"request with inactive book", request("", func(r *entity.ExchangeRequest) { r.RequestedBook.IsActive = false }), actionRequest, &requester, http.StatusConflict, ""},
+		{"owner accepts", request(entity.ExchangePending, nil), actionAccept, &owner, 0, entity.ExchangeAccepted},
+		{"requester cannot accept", request(entity.ExchangePending, nil), actionAccept, &requester, http.StatusBadRequest, ""},
+		{"stranger cannot act", request(entity.ExchangePending, nil), actionAccept, &stranger, http.StatusNotFound, ""},
+		{"accept twice", request(entity.ExchangeAccepted, nil), actionAccept, &owner, http.StatusBadRequest, ""},
+		{"requester counters back", request(entity.ExchangeCountered, nil), actionCounterBack, &requester, 0, entity.ExchangePending},
+		{"either side declines", request(entity.ExchangeCountered, nil), actionDecline, &requester, 0, entity.ExchangeDeclined},
+		{"system cannot decline for a side", request(entity.ExchangePending, nil), actionDecline, nil, http.StatusBadRequest, ""},
+		{"confirm accepted", request(entity.ExchangeAccepted, nil), actionConfirm, &requester, 0, entity.ExchangeAccepted},
+		{"complete needs both", request(entity.ExchangeAccepted, confirmedBy(true, false)), actionComplete, nil, http.StatusBadRequest, ""},
+		{"complete when both confirmed", request(entity.ExchangeAccepted, confirmedBy(true, true)), actionComplete, nil, 0, entity.ExchangeExchanged},
+		{"agree meetup twice", request(entity.ExchangeAccepted, meetupAgreed), actionAgreeMeetup, &owner, http.StatusBadRequest, ""},
+		{"expire pending", request(entity.ExchangePending, nil), actionExpire, nil, 0, entity.ExchangeExpired},
+		{"expire accepted", request(entity.ExchangeAccepted, nil), actionExpire, nil, http.StatusBadRequest, ""},
+		{"lapse unconfirmed", request(entity.ExchangeAccepted, nil), actionLapse, nil, 0, entity.ExchangeExpired},
+		{"lapse partly confirmed", request(entity.ExchangeAccepted, confirmedBy(false, true)), actionLapse, nil, http.StatusBadRequest, ""},
+		{"escalate partly confirmed", request(entity.ExchangeAccepted, confirmedBy(false, true)), actionEscalate, nil, 0, entity.ExchangeDisputed},
+		{"escalate unconfirmed", request(entity.ExchangeAccepted, nil), actionEscalate, nil, http.StatusBadRequest, ""},
+		{"side cannot lapse", request(entity.ExchangeAccepted, nil), actionLapse, &owner, http.StatusBadRequest, ""},
+		{"cancel accepted", request(entity.ExchangeAccepted, nil), actionCancel, &owner, 0, entity.ExchangeCancelled},
+		{"cancel pending", request(entity.ExchangePending, nil), actionCancel, &owner, http.StatusBadRequest, ""},
+		{"dispute accepted", request(entity.ExchangeAccepted, nil), actionDispute, &requester, 0, entity.ExchangeDisputed},
+		{"resolve as side", request(entity.ExchangeDisputed, nil), actionResolveCancelled, &requester, http.StatusBadRequest, ""},
+		{"resolve as system", request(entity.ExchangeDisputed, nil), actionResolveExchanged, nil, 0, entity.ExchangeExchanged},
+		{"delete declined", request(entity.ExchangeDeclined, nil), actionDelete, &requester, 0, ""},
+		{"delete cancelled", request(entity.ExchangeCancelled, nil), actionDelete, &requester, 0, ""},
+		{"delete accepted", request(entity.ExchangeAccepted, nil), actionDelete, &requester, http.StatusBadRequest, ""},
+		{"confirm on cycle leg", request(entity.ExchangeAccepted, cycleLeg), actionConfirm, &requester, 0, entity.ExchangeAccepted},
+		{"accept cycle leg alone", request(entity.ExchangePending, cycleLeg), actionAccept, &owner, http.StatusBadRequest, ""},
+		{"cancel cycle leg alone", request(entity.ExchangeAccepted, cycleLeg), actionCancel, &owner, http.StatusBadRequest, ""},
+		{"dispute cycle leg alone", request(entity.ExchangeAccepted, cycleLeg), actionDispute, &requester, http.StatusBadRequest, ""},
+		{"lapse cycle leg", request(entity.ExchangeAccepted, cycleLeg), actionLapse, nil, 0, entity.ExchangeExpired},
+		{"escalate unconfirmed cycle leg", request(entity.ExchangeAccepted, cycleLeg), actionEscalate, nil, 0, entity.ExchangeDisputed},
+		{"resolve cycle leg", request(entity.ExchangeDisputed, cycleLeg), actionResolveCancelled, nil, 0, entity.ExchangeCancelled},
+		{"unknown action", request(entity.ExchangePending, nil), exchangeAction("steal"), &requester, http.StatusInternalServerError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transition, err := checkExchangeTransition(tt.request, tt.action, tt.actorID)
+			if tt.wantStatus != 0 {
+				if err == nil {
+					t.Fatalf("got no error, want status %d", tt.wantStatus)
+				}
+				if got := response.Status(err); got != tt.wantStatus {
+					t.Fatalf("got status %d (%v), want %d", got, err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v, want none", err)
+			}
+			if transition.to != tt.wantTo {
+				t.Errorf("got to %q, want %q", transition.to, tt.wantTo)
+			}
+		})
+	}
+}
+
+// Every transition has to be reachable: it starts from a status something
+// leads to and someone may take it.
+func TestExchangeTransitionsReachable(t *testing.T) {
+	reached := map[entity.ExchangeStatus]bool{"": true}
+	for _, transition := range exchangeTransitions {
+		reached[transition.to] = true
+	}
+
+	for action, transition := range exchangeTransitions {
+		if len(transition.actors) == 0 {
+			t.Errorf("%s: no actors", action)
+		}
+		if len(transition.from) == 0 {
+			t.Errorf("%s: no from statuses", action)
+		}
+		for _, from := range transition.from {
+			if !reached[from] {
+				t.Errorf("%s: from status %q is never reached", action, from)
+			}
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package usecase
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/google/uuid"
+)
+
+// exchangeStore keeps books and exchange requests in memory for usecase
+// tests. Each repository fake embeds its interface, so a method the code
+// under test starts calling panics until the fake learns it.
+type exchangeStore struct {
+	books        map[uint]entity.Book
+	requests     map[uuid.UUID]entity.ExchangeRequest
+	offers       []entity.ExchangeOffer
+	events       []entity.ExchangeEvent
+	cancellation map[uuid.UUID]int
+}
+
+func newExchangeStore() *exchangeStore {
+	return &exchangeStore{
+		books:        map[uint]entity.Book{},
+		requests:     map[uuid.UUID]entity.ExchangeRequest{},
+		cancellation: map[uuid.UUID]int{},
+	}
+}
+
+// usecase returns an exchange usecase working on the store.
+func (s *exchangeStore) usecase() *exchangeUsecase {
+	return &exchangeUsecase{
+		exchangeRepo:        &fakeExchangeRepo{s: s},
+		exchangeEventRepo:   &fakeEventRepo{s: s},
+		exchangeOfferRepo:   &fakeOfferRepo{s: s},
+		exchangeMeetupRepo:  &fakeMeetupRepo{},
+		exchangeHandoffRepo: &fakeHandoffRepo{},
+		userRepo:            &fakeUserRepo{s: s},
+		bookRepo:            &fakeBookRepo{s: s},
+		transactor:          &fakeTransactor{s: s},
+	}
+}
+
+func (s *exchangeStore) addBook(id uint, owner uuid.UUID, active bool) entity.Book {
+	book := entity.Book{ID: id, Title: "book", UserID: owner, IsActive: active, ListingType: "swap", Version: 1}
+	s.books[id] = book
+	return book
+}
+
+// addRequest stores a request swapping the given books of owner for those
+// of requester, and returns a copy the way the repository loads it.
+func (s *exchangeStore) addRequest(t *testing.T, requester, owner uuid.UUID, status entity.ExchangeStatus, requested, offered []uint) *entity.ExchangeRequest {
+	t.Helper()
+	request := &entity.ExchangeRequest{ID: uuid.New(), RequestedByID: requester, RequestedToID: owner, Type: "swap", Status: status}
+	for _, id := range requested {
+		request.Items = append(request.Items, entity.ExchangeItem{BookID: id, Book: s.books[id], Side: entity.ItemRequested})
+	}
+	for _, id := range offered {
+		request.Items = append(request.Items, entity.ExchangeItem{BookID: id, Book: s.books[id], Side: entity.ItemOffered})
+	}
+	if err := buildBundle(request); err != nil {
+		t.Fatalf("building request: %v", err)
+	}
+	for i := range request.Items {
+		request.Items[i].ExchangeRequestID = request.ID
+	}
+	s.requests[request.ID] = copyRequest(request)
+	return s.load(request.ID)
+}
+
+// load returns a copy of the stored request with its items in the order
+// the repository preloads them.
+func (s *exchangeStore) load(id uuid.UUID) *entity.ExchangeRequest {
+	stored := s.requests[id]
+	request := copyRequest(&stored)
+	request.Items = orderedItems(request.Items)
+	return &request
+}
+
+// orderedItems sorts items requested first, then by book.
+func orderedItems(items []entity.ExchangeItem) []entity.ExchangeItem {
+	if len(items) == 0 {
+		return nil
+	}
+	requested := sideItems(&entity.ExchangeRequest{Items: items}, entity.ItemRequested)
+	offered := sideItems(&entity.ExchangeRequest{Items: items}, entity.ItemOffered)
+	byBook := func(a, b entity.ExchangeItem) int { return int(a.BookID) - int(b.BookID) }
+	slices.SortFunc(requested, byBook)
+	slices.SortFunc(offered, byBook)
+	return append(requested, offered...)
+}
+
+func copyRequest(request *entity.ExchangeRequest) entity.ExchangeRequest {
+	c := *request
+	c.Items = slices.Clone(request.Items)
+	if request.OfferedBook != nil {
+		book := *request.OfferedBook
+		c.OfferedBook = &book
+	}
+	return c
+}
+
+// withBook reports whether the request holds the book on either side.
+func withBook(request entity.ExchangeRequest, bookID uint) bool {
+	return slices.Contains(exchangeBookIDs(&request), bookID)
+}
+
+type fakeTransactor struct {
+	s *exchangeStore
+}
+
+// Run rolls the store back when fn fails, as the database would.
+func (f *fakeTransactor) Run(fn func(tx *repository.Tx) error) error {
+	books := maps.Clone(f.s.books)
+	requests := maps.Clone(f.s.requests)
+	offers := slices.Clone(f.s.offers)
+	events := slices.Clone(f.s.events)
+	cancellation := maps.Clone(f.s.cancellation)
+	err := fn(&repository.Tx{})
+	if err != nil {
+		f.s.books, f.s.requests, f.s.offers, f.s.events, f.s.cancellation = books, requests, offers, events, cancellation
+	}
+	return err
+}
+
+type fakeBookRepo struct {
+	repository.BookRepository
+	s *exchangeStore
+}
+
+func (r *fakeBookRepo) LockByIDs(ids []uint) ([]entity.Book, error) {
+	var books []entity.Book
+	for _, id := range ids {
+		if book, ok := r.s.books[id]; ok && !slices.ContainsFunc(books, func(b entity.Book) bool { return b.ID == id }) {
+			books = append(books, book)
+		}
+	}
+	return books, nil
+}
+
+func (r *fakeBookRepo) Update(book *entity.Book, updates map[string]interface{}) error {
+	stored := r.s.books[book.ID]
+	if active, ok := updates["is_active"].(bool); ok {
+		stored.IsActive = active
+	}
+	r.s.books[book.ID] = stored
+	return nil
+}
+
+func (r *fakeBookRepo) WithTx(tx *repository.Tx) repository.BookRepository { return r }
+
+type fakeExchangeRepo struct {
+	repository.ExchangeRepository
+	s *exchangeStore
+}
+
+func (r *fakeExchangeRepo) Create(request *entity.ExchangeRequest) error {
+	if request.ID == uuid.Nil {
+		request.ID = uuid.New()
+	}
+	r.s.requests[request.ID] = copyRequest(request)
+	return nil
+}
+
+func (r *fakeExchangeRepo) FindByID(id uuid.UUID) (*entity.ExchangeRequest, error) {
+	return r.s.load(id), nil
+}
+
+func (r *fakeExchangeRepo) LockByID(id uuid.UUID) (*entity.ExchangeRequest, error) {
+	return r.s.load(id), nil
+}
+
+// Update saves the request's own columns; its items only change through
+// ReplaceItems.
+func (r *fakeExchangeRepo) Update(request *entity.ExchangeRequest) error {
+	items := r.s.requests[request.ID].Items
+	stored := copyRequest(request)
+	stored.Items = items
+	r.s.requests[request.ID] = stored
+	return nil
+}
+
+func (r *fakeExchangeRepo) FindItems(requestID uuid.UUID) ([]entity.ExchangeItem, error) {
+	return orderedItems(r.s.requests[requestID].Items), nil
+}
+
+func (r *fakeExchangeRepo) ReplaceItems(requestID uuid.UUID, side string, items []entity.ExchangeItem) error {
+	request := r.s.requests[requestID]
+	kept := slices.DeleteFunc(slices.Clone(request.Items), func(item entity.ExchangeItem) bool { return item.Side == side })
+	request.Items = append(kept, items...)
+	r.s.requests[requestID] = request
+	return nil
+}
+
+func (r *fakeExchangeRepo) FindPendingRequestsByBookID(bookID uint) ([]entity.ExchangeRequest, error) {
+	var requests []entity.ExchangeRequest
+	for _, request := range r.s.requests {
+		if withBook(request, bookID) && (request.Status == entity.ExchangePending || request.Status == entity.ExchangeCountered) {
+			requests = append(requests, copyRequest(&request))
+		}
+	}
+	return requests, nil
+}
+
+func (r *fakeExchangeRepo) HasAcceptedRequestForBook(bookID uint) (bool, error) {
+	for _, request := range r.s.requests {
+		if withBook(request, bookID) && (request.Status == entity.ExchangeAccepted || request.Status == entity.ExchangeDisputed) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeExchangeRepo) FindByTradeCycleID(cycleID uuid.UUID) ([]entity.ExchangeRequest, error) {
+	var requests []entity.ExchangeRequest
+	for _, request := range r.s.requests {
+		if request.TradeCycleID != nil && *request.TradeCycleID == cycleID {
+			requests = append(requests, copyRequest(&request))
+		}
+	}
+	return requests, nil
+}
+
+func (r *fakeExchangeRepo) WithTx(tx *repository.Tx) repository.ExchangeRepository { return r }
+
+type fakeEventRepo struct {
+	repository.ExchangeEventRepository
+	s *exchangeStore
+}
+
+func (r *fakeEventRepo) Create(event *entity.ExchangeEvent) error {
+	r.s.events = append(r.s.events, *event)
+	return nil
+}
+
+func (r *fakeEventRepo) WithTx(tx *repository.Tx) repository.ExchangeEventRepository { return r }
+
+type fakeOfferRepo struct {
+	repository.ExchangeOfferRepository
+	s *exchangeStore
+}
+
+func (r *fakeOfferRepo) Create(offer *entity.ExchangeOffer) error {
+	r.s.offers = append(r.s.offers, *offer)
+	return nil
+}
+
+func (r *fakeOfferRepo) LatestRevision(requestID uuid.UUID) (int, error) {
+	revision := 0
+	for _, offer := range r.s.offers {
+		if offer.ExchangeRequestID == requestID {
+			revision = max(revision, offer.Revision)
+		}
+	}
+	return revision, nil
+}
+
+func (r *fakeOfferRepo) WithTx(tx *repository.Tx) repository.ExchangeOfferRepository { return r }
+
+type fakeMeetupRepo struct {
+	repository.ExchangeMeetupRepository
+}
+
+func (r *fakeMeetupRepo) WithTx(tx *repository.Tx) repository.ExchangeMeetupRepository { return r }
+
+type fakeHandoffRepo struct {
+	repository.ExchangeHandoffRepository
+}
+
+func (r *fakeHandoffRepo) WithTx(tx *repository.Tx) repository.ExchangeHandoffRepository { return r }
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	s *exchangeStore
+}
+
+func (r *fakeUserRepo) IncrementCancellationCount(id uuid.UUID) error {
+	r.s.cancellation[id]++
+	return nil
+}
+
+func (r *fakeUserRepo) WithTx(tx *repository.Tx) repository.UserRepository { return r }
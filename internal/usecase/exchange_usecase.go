@@ -1,8 +1,10 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"slices"
+	"time"
 
 	"github.com/arjnep/gyanpass/internal/entity"
 	"github.com/arjnep/gyanpass/internal/repository"
@@ -14,63 +16,318 @@ import (
 
 type ExchangeUsecase interface {
 	RequestExchange(request *entity.ExchangeRequest) (*entity.ExchangeRequest, error)
+	GiveBook(request *entity.ExchangeRequest) (*entity.ExchangeRequest, error)
 	GetExchangeRequestByID(id uuid.UUID, userID uuid.UUID) (*entity.ExchangeRequest, error)
 	GetExchangeRequestsByRequestedByID(userID uuid.UUID) ([]entity.ExchangeRequest, error)
 	GetExchangeRequestsByRequestedToID(userID uuid.UUID) ([]entity.ExchangeRequest, error)
 	AcceptExchange(request *entity.ExchangeRequest, userID uuid.UUID) error
+	CounterExchange(request *entity.ExchangeRequest, offeredBooks []*entity.Book, userID uuid.UUID, message string) error
+	GetExchangeOffers(request *entity.ExchangeRequest, userID uuid.UUID) ([]entity.ExchangeOffer, error)
 	DeclineExchange(request *entity.ExchangeRequest, userID uuid.UUID) error
 	DeleteExchangeRequest(request *entity.ExchangeRequest, userID uuid.UUID) error
+	WithdrawExchange(request *entity.ExchangeRequest, userID uuid.UUID) error
 	ConfirmExchange(request *entity.ExchangeRequest, userID uuid.UUID) error
+	AgreeMeetup(request *entity.ExchangeRequest, userID uuid.UUID) error
+	ProposeMeetup(request *entity.ExchangeRequest, slot *entity.ExchangeMeetup, userID uuid.UUID) error
+	RescheduleMeetup(request *entity.ExchangeRequest, meetupID uuid.UUID, slot *entity.ExchangeMeetup, userID uuid.UUID) error
+	AcceptMeetup(request *entity.ExchangeRequest, meetupID uuid.UUID, userID uuid.UUID) (*entity.ExchangeMeetup, error)
+	GetExchangeMeetups(request *entity.ExchangeRequest, userID uuid.UUID) ([]entity.ExchangeMeetup, error)
+	GetMeetupCalendar(request *entity.ExchangeRequest, userID uuid.UUID) ([]byte, error)
+	IssueHandoffCode(request *entity.ExchangeRequest, userID uuid.UUID) (*entity.HandoffCode, error)
+	VerifyHandoff(request *entity.ExchangeRequest, userID uuid.UUID, code string, qrPayload string) error
+	GetExchangeHistory(request *entity.ExchangeRequest, userID uuid.UUID) ([]entity.ExchangeEvent, error)
 	GetExchangeRequestsByBookIDAndUserID(bookID uint, userID uuid.UUID) ([]entity.ExchangeRequest, error)
 	GetExchangeRequestsByUserID(userID uuid.UUID) ([]entity.ExchangeRequest, error)
+	RunExpiry(ctx context.Context)
+	RunMeetupReminders(ctx context.Context)
+	CancelExchange(request *entity.ExchangeRequest, userID uuid.UUID, reason string, oneSided bool) error
+	DisputeExchange(request *entity.ExchangeRequest, userID uuid.UUID, reason string) error
+	GetDisputedExchanges() ([]entity.ExchangeRequest, error)
+	ResolveDispute(id uuid.UUID, adminID uuid.UUID, outcome entity.ExchangeStatus, atFault string, note string) (*entity.ExchangeRequest, error)
+	BackfillItems() error
+}
+
+// ExchangeTimeouts is how long a request may wait on each side before the
+// scheduler ends it.
+type ExchangeTimeouts struct {
+	Pending  time.Duration // for the owner to answer
+	Accepted time.Duration // for both sides to confirm the handover
 }
 
 type exchangeUsecase struct {
 	exchangeRepo        repository.ExchangeRepository
+	exchangeEventRepo   repository.ExchangeEventRepository
+	exchangeOfferRepo   repository.ExchangeOfferRepository
+	exchangeMeetupRepo  repository.ExchangeMeetupRepository
+	exchangeHandoffRepo repository.ExchangeHandoffRepository
+	userRepo            repository.UserRepository
 	bookRepo            repository.BookRepository
+	bookVersionRepo     repository.BookVersionRepository
+	transactor          repository.Transactor
 	notificationService notification.Service
+	timeouts            ExchangeTimeouts
+	handoffKey          []byte // signs handoff QR payloads and keys code hashes
 }
 
-func NewExchangeUsecase(exchangeRepo repository.ExchangeRepository, bookRepo repository.BookRepository, notificationService notification.Service) ExchangeUsecase {
-	return &exchangeUsecase{exchangeRepo, bookRepo, notificationService}
+func NewExchangeUsecase(exchangeRepo repository.ExchangeRepository, exchangeEventRepo repository.ExchangeEventRepository, exchangeOfferRepo repository.ExchangeOfferRepository, exchangeMeetupRepo repository.ExchangeMeetupRepository, exchangeHandoffRepo repository.ExchangeHandoffRepository, userRepo repository.UserRepository, bookRepo repository.BookRepository, bookVersionRepo repository.BookVersionRepository, transactor repository.Transactor, notificationService notification.Service, timeouts ExchangeTimeouts, handoffKey []byte) ExchangeUsecase {
+	return &exchangeUsecase{exchangeRepo, exchangeEventRepo, exchangeOfferRepo, exchangeMeetupRepo, exchangeHandoffRepo, userRepo, bookRepo, bookVersionRepo, transactor, notificationService, timeouts, handoffKey}
 }
 
-func (u *exchangeUsecase) RequestExchange(request *entity.ExchangeRequest) (*entity.ExchangeRequest, error) {
-	if u.exchangeRepo.IsSelfRequest(request.RequestedByID, request.RequestedToID) {
-		return nil, response.NewBadRequestError("Cannot Request To Yourself")
+// exchangeTx is a state change of one exchange request in progress: the
+// repositories bound to its transaction, with the books involved locked.
+type exchangeTx struct {
+	*repository.Tx
+	exchangeRepo        repository.ExchangeRepository
+	exchangeEventRepo   repository.ExchangeEventRepository
+	exchangeOfferRepo   repository.ExchangeOfferRepository
+	exchangeMeetupRepo  repository.ExchangeMeetupRepository
+	exchangeHandoffRepo repository.ExchangeHandoffRepository
+	userRepo            repository.UserRepository
+	bookRepo            repository.BookRepository
+}
+
+// apply performs a transition from the exchangeTransitions table inside
+// the transaction.
+func (tx *exchangeTx) apply(request *entity.ExchangeRequest, action exchangeAction, actorID *uuid.UUID, reason string) error {
+	return applyExchangeTransition(tx.exchangeRepo, tx.exchangeEventRepo, request, action, actorID, reason)
+}
+
+// applyAsAdmin performs a system transition on behalf of adminID, who is
+// recorded as its author.
+func (tx *exchangeTx) applyAsAdmin(request *entity.ExchangeRequest, action exchangeAction, adminID uuid.UUID, reason string) error {
+	return recordExchangeTransition(tx.exchangeRepo, tx.exchangeEventRepo, request, action, nil, &adminID, reason)
+}
+
+func (u *exchangeUsecase) bindTx(tx *repository.Tx) *exchangeTx {
+	return &exchangeTx{
+		Tx:                  tx,
+		exchangeRepo:        u.exchangeRepo.WithTx(tx),
+		exchangeEventRepo:   u.exchangeEventRepo.WithTx(tx),
+		exchangeOfferRepo:   u.exchangeOfferRepo.WithTx(tx),
+		exchangeMeetupRepo:  u.exchangeMeetupRepo.WithTx(tx),
+		exchangeHandoffRepo: u.exchangeHandoffRepo.WithTx(tx),
+		userRepo:            u.userRepo.WithTx(tx),
+		bookRepo:            u.bookRepo.WithTx(tx),
 	}
-	canRequest, err := u.exchangeRepo.CanRequest(request.RequestedByID, request.RequestedToID)
+}
+
+// inTransaction runs fn as one transaction. The request's books are locked
+// first and the request row after them, always in that order, and the
+// in-memory request is refreshed from the locked rows so that fn checks
+// the current state. Notifications queued with AfterCommit are sent only
+// once everything is committed.
+func (u *exchangeUsecase) inTransaction(request *entity.ExchangeRequest, fn func(tx *exchangeTx) error) error {
+	return u.inTransactionWith(request, nil, fn)
+}
+
+// inTransactionWith is inTransaction that also locks and refreshes extra
+// books fn is about to bring into the request.
+func (u *exchangeUsecase) inTransactionWith(request *entity.ExchangeRequest, extra []*entity.Book, fn func(tx *exchangeTx) error) error {
+	return u.lockRequests([]*entity.ExchangeRequest{request}, extra, fn)
+}
+
+// inCycleTransaction is inTransaction for every leg of a trade cycle at
+// once, so that the cycle ends as a whole or not at all.
+func (u *exchangeUsecase) inCycleTransaction(legs []*entity.ExchangeRequest, fn func(tx *exchangeTx) error) error {
+	return u.lockRequests(legs, nil, fn)
+}
+
+// lockRequests locks the books of every request and the extra ones, then
+// each request row in turn, refreshing them before running fn.
+func (u *exchangeUsecase) lockRequests(requests []*entity.ExchangeRequest, extra []*entity.Book, fn func(tx *exchangeTx) error) error {
+	err := u.transactor.Run(func(tx *repository.Tx) error {
+		etx := u.bindTx(tx)
+
+		books := extra
+		for _, request := range requests {
+			books = append(bookCopies(request), books...)
+		}
+		bookIDs := make([]uint, len(books))
+		for i, book := range books {
+			bookIDs[i] = book.ID
+		}
+		locked, err := etx.bookRepo.LockByIDs(bookIDs)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		active := make(map[uint]bool, len(locked))
+		for _, book := range locked {
+			active[book.ID] = book.IsActive
+		}
+		for _, book := range books {
+			book.IsActive = active[book.ID]
+		}
+
+		for _, request := range requests {
+			if request.ID == uuid.Nil {
+				continue
+			}
+			if err := refreshRequest(etx, request); err != nil {
+				return err
+			}
+		}
+
+		return fn(etx)
+	})
+	return transactionError(err)
+}
+
+// refreshRequest locks the request row and copies its current state onto
+// the in-memory request.
+func refreshRequest(tx *exchangeTx, request *entity.ExchangeRequest) error {
+	current, err := tx.exchangeRepo.LockByID(request.ID)
+	if err != nil && err == gorm.ErrRecordNotFound {
+		return response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+	} else if err != nil {
+		return response.NewInternalServerError()
+	}
+	// The books locked before are only those of the request as it was
+	// read; a counter-offer since may have changed them.
+	items, err := tx.exchangeRepo.FindItems(request.ID)
 	if err != nil {
-		return nil, response.NewInternalServerError()
+		return response.NewInternalServerError()
 	}
-	if !canRequest {
-		return nil, response.NewConflictError("exchange request", "one request already exists with this user")
+	if !slices.Equal(itemBookIDs(items), exchangeBookIDs(request)) {
+		return response.NewConflictError("exchange request", "request changed meanwhile, reload it and try again")
+	}
+	request.Status = current.Status
+	request.RequestedByConfirmed = current.RequestedByConfirmed
+	request.RequestedToConfirmed = current.RequestedToConfirmed
+	request.RequestedByHandoffVerified = current.RequestedByHandoffVerified
+	request.RequestedToHandoffVerified = current.RequestedToHandoffVerified
+	request.RequestedByMeetupAgreed = current.RequestedByMeetupAgreed
+	request.RequestedToMeetupAgreed = current.RequestedToMeetupAgreed
+	request.MeetupAgreedAt = current.MeetupAgreedAt
+	request.AcceptedAt = current.AcceptedAt
+	request.LastReminderAt = current.LastReminderAt
+	request.CancelRequestedByID = current.CancelRequestedByID
+	request.CancelRequestedAt = current.CancelRequestedAt
+	request.CancelReason = current.CancelReason
+	return nil
+}
+
+// exchangeCounterpart returns the other side of the request than userID and
+// the first name of userID.
+func exchangeCounterpart(request *entity.ExchangeRequest, userID uuid.UUID) (uuid.UUID, string) {
+	if request.RequestedByID == userID {
+		return request.RequestedToID, request.RequestedBy.FirstName
 	}
-	if !request.RequestedBook.IsActive {
-		return nil, response.NewConflictError("book", "requested book already in exchanging process")
+	return request.RequestedByID, request.RequestedTo.FirstName
+}
+
+// prepareRequest checks a new request against the listings of the books it
+// asks for and resets what the client may not set. It reports whether every
+// requested book is given away first come, first served.
+func (u *exchangeUsecase) prepareRequest(request *entity.ExchangeRequest) (bool, error) {
+	if u.exchangeRepo.IsSelfRequest(request.RequestedByID, request.RequestedToID) {
+		return false, response.NewBadRequestError("Cannot Request To Yourself")
+	}
+
+	if err := buildBundle(request); err != nil {
+		return false, err
+	}
+
+	request.Type = "swap"
+	if request.OfferedBook == nil {
+		request.Type = "claim"
 	}
-	if !request.OfferedBook.IsActive {
-		return nil, response.NewConflictError("book", "offered book already in exchanging process")
+	firstComeOnly := true
+	for _, item := range sideItems(request, entity.ItemRequested) {
+		if request.Type == "claim" && item.Book.ListingType != "giveaway" && item.Book.ListingType != "either" {
+			return false, response.NewBadRequestError("offered_book_id is required for books listed for swap")
+		}
+		if request.Type == "swap" && item.Book.ListingType == "giveaway" {
+			return false, response.NewBadRequestError("giveaway books are claimed without offering a book")
+		}
+		firstComeOnly = firstComeOnly && item.Book.ClaimMode == "first"
 	}
 
-	request.Status = "pending"
+	request.Status = ""
 	request.RequestedByConfirmed = false
 	request.RequestedToConfirmed = false
+	return firstComeOnly, nil
+}
 
-	err = u.exchangeRepo.Create(request)
+func (u *exchangeUsecase) RequestExchange(request *entity.ExchangeRequest) (*entity.ExchangeRequest, error) {
+	firstComeOnly, err := u.prepareRequest(request)
 	if err != nil {
-		return nil, response.NewInternalServerError()
+		return nil, err
+	}
+
+	err = u.inTransaction(request, func(tx *exchangeTx) error {
+		canRequest, err := tx.exchangeRepo.CanRequest(request.RequestedByID, request.RequestedToID)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		if !canRequest {
+			return response.NewConflictError("exchange request", "one request already exists with this user")
+		}
+
+		err = tx.apply(request, actionRequest, &request.RequestedByID, "")
+		if err != nil {
+			return err
+		}
+		if request.Type == "swap" {
+			err = u.recordOffer(tx, request, 1, request.RequestedByID, "")
+			if err != nil {
+				return err
+			}
+		}
+
+		msg := "You have new exchange request for your book " + request.RequestedBook.Title + "."
+		if request.Type == "claim" {
+			msg = "You have a new claim for your giveaway book " + request.RequestedBook.Title + "."
+		}
+		notifyAfterCommit(tx.Tx, u.notificationService, request.RequestedToID, exchangeNotification, msg)
+
+		// First come, first served giveaways skip the owner's choice and go
+		// through the regular accept path.
+		if request.Type == "claim" && firstComeOnly {
+			return u.acceptExchangeRequest(tx, request, actionAutoAccept, nil, "first come, first served giveaway")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	u.sanitizeExchangeRequest(request, request.RequestedByID)
 
-	msg := "You have new exchange request for your book " + request.RequestedBook.Title + "."
-	err = u.notificationService.SendNotification(request.RequestedToID, "exchange request", msg)
+	return request, nil
+}
+
+// GiveBook records the owner handing a book to a user who asked for it
+// elsewhere, such as on a wanted post. The owner's offer is their answer, so
+// the claim is created already accepted and only the receiver is told.
+func (u *exchangeUsecase) GiveBook(request *entity.ExchangeRequest) (*entity.ExchangeRequest, error) {
+	if request.OfferedBook != nil {
+		return nil, response.NewBadRequestError("a given book takes nothing in return")
+	}
+	if _, err := u.prepareRequest(request); err != nil {
+		return nil, err
+	}
+
+	err := u.inTransaction(request, func(tx *exchangeTx) error {
+		canRequest, err := tx.exchangeRepo.CanRequest(request.RequestedByID, request.RequestedToID)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		if !canRequest {
+			return response.NewConflictError("exchange request", "one request already exists with this user")
+		}
+
+		err = tx.apply(request, actionRequest, &request.RequestedByID, "offered by the owner")
+		if err != nil {
+			return err
+		}
+		return u.acceptExchangeRequest(tx, request, actionAccept, &request.RequestedToID, "")
+	})
 	if err != nil {
-		log.Println("Failed Sending Notification of new request:", err)
-		return nil, response.NewInternalServerError()
+		return nil, err
 	}
 
+	u.sanitizeExchangeRequest(request, request.RequestedToID)
+
 	return request, nil
 }
 
@@ -87,9 +344,7 @@ func (u *exchangeUsecase) GetExchangeRequestByID(id uuid.UUID, userID uuid.UUID)
 		return nil, response.NewNotFoundError("exchange request", fmt.Sprintf("%v", id))
 	}
 
-	if request.Status != "accepted" && request.Status != "exchanged" {
-		u.sanitizeExchangeRequest(request, userID)
-	}
+	u.sanitizeExchangeRequest(request, userID)
 
 	return request, nil
 
@@ -102,9 +357,7 @@ func (u *exchangeUsecase) GetExchangeRequestsByBookIDAndUserID(bookID uint, user
 	}
 
 	for i := range requests {
-		if requests[i].Status != "accepted" && requests[i].Status != "exchanged" {
-			u.sanitizeExchangeRequest(&requests[i], userID)
-		}
+		u.sanitizeExchangeRequest(&requests[i], userID)
 	}
 
 	return requests, nil
@@ -117,9 +370,7 @@ func (u *exchangeUsecase) GetExchangeRequestsByUserID(userID uuid.UUID) ([]entit
 	}
 
 	for i := range requests {
-		if requests[i].Status != "accepted" && requests[i].Status != "exchanged" {
-			u.sanitizeExchangeRequest(&requests[i], userID)
-		}
+		u.sanitizeExchangeRequest(&requests[i], userID)
 	}
 
 	return requests, nil
@@ -132,9 +383,7 @@ func (u *exchangeUsecase) GetExchangeRequestsByRequestedByID(userID uuid.UUID) (
 	}
 
 	for i := range requests {
-		if requests[i].Status != "accepted" && requests[i].Status != "exchanged" {
-			u.sanitizeExchangeRequest(&requests[i], userID)
-		}
+		u.sanitizeExchangeRequest(&requests[i], userID)
 	}
 
 	return requests, nil
@@ -147,209 +396,391 @@ func (u *exchangeUsecase) GetExchangeRequestsByRequestedToID(userID uuid.UUID) (
 	}
 
 	for i := range requests {
-		if requests[i].Status != "accepted" && requests[i].Status != "exchanged" {
-			u.sanitizeExchangeRequest(&requests[i], userID)
-		}
+		u.sanitizeExchangeRequest(&requests[i], userID)
 	}
 
 	return requests, nil
 }
 
+// AcceptExchange accepts the latest offer on the request: the owner
+// accepts the requester's, the requester a counter-offer of the owner.
 func (u *exchangeUsecase) AcceptExchange(request *entity.ExchangeRequest, userID uuid.UUID) error {
-	if request.RequestedToID != userID {
-		// return response.NewAuthorizationError("you do not have permission")
-		return response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+	action := actionAccept
+	if request.RequestedByID == userID {
+		action = actionAcceptOffer
 	}
-	if request.Status != "pending" {
-		return response.NewBadRequestError("request already accepted or declined")
+	return u.inTransaction(request, func(tx *exchangeTx) error {
+		return u.acceptExchangeRequest(tx, request, action, &userID, "")
+	})
+}
+
+func (u *exchangeUsecase) DeclineExchange(request *entity.ExchangeRequest, userID uuid.UUID) error {
+	return u.inTransaction(request, func(tx *exchangeTx) error {
+		err := tx.apply(request, actionDecline, &userID, "")
+		if err != nil {
+			return err
+		}
+		if request.RequestedByID == userID {
+			msg := request.RequestedBy.FirstName + " walked away from the exchange for your book " + request.RequestedBook.Title + "."
+			notifyAfterCommit(tx.Tx, u.notificationService, request.RequestedToID, exchangeNotification, msg)
+			return nil
+		}
+		msg := "Your Exchange Request For Book " + request.RequestedBook.Title + " is declined."
+		notifyAfterCommit(tx.Tx, u.notificationService, request.RequestedByID, exchangeNotification, msg)
+		return nil
+	})
+}
+
+// CounterExchange proposes offeredBooks, from the requester's shelf, in
+// place of the books the request offers now. The owner counters a pending
+// request and the requester counters back, each revision being kept.
+func (u *exchangeUsecase) CounterExchange(request *entity.ExchangeRequest, offeredBooks []*entity.Book, userID uuid.UUID, message string) error {
+	if request.Type != "swap" || request.OfferedBookID == nil {
+		return response.NewBadRequestError("claims have no offered book to counter")
+	}
+	if len(offeredBooks) == 0 || len(offeredBooks) > maxBundleSize {
+		return response.NewBadRequestError(fmt.Sprintf("a counter-offer proposes between 1 and %d books", maxBundleSize))
+	}
+	offeredIDs := make([]uint, len(offeredBooks))
+	for i, book := range offeredBooks {
+		if book.UserID != request.RequestedByID {
+			return response.NewBadRequestError("counter-offers propose books from the requester's shelf")
+		}
+		offeredIDs[i] = book.ID
+	}
+	slices.Sort(offeredIDs)
+	if len(slices.Compact(slices.Clone(offeredIDs))) != len(offeredIDs) {
+		return response.NewBadRequestError("a book can only be in a request once")
+	}
+	if slices.Equal(offeredIDs, itemBookIDs(sideItems(request, entity.ItemOffered))) {
+		return response.NewBadRequestError("those books are already offered")
 	}
 
-	err := u.resolveExchangeRequest(request, "accepted")
-	if err != nil {
-		return err
+	action, recipientID, name := actionCounter, request.RequestedByID, request.RequestedTo.FirstName
+	if request.RequestedByID == userID {
+		action, recipientID, name = actionCounterBack, request.RequestedToID, request.RequestedBy.FirstName
 	}
 
-	return nil
+	return u.inTransactionWith(request, offeredBooks, func(tx *exchangeTx) error {
+		revision, err := tx.exchangeOfferRepo.LatestRevision(request.ID)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		if revision == 0 {
+			// Requests made before counter-offers existed start their
+			// thread with what they offered.
+			revision = 1
+			err = u.recordOffer(tx, request, revision, request.RequestedByID, "")
+			if err != nil {
+				return err
+			}
+		}
 
+		// Copied only now, with availability refreshed from the locked rows.
+		offered := make([]entity.ExchangeItem, len(offeredBooks))
+		for i, book := range offeredBooks {
+			offered[i] = entity.ExchangeItem{ExchangeRequestID: request.ID, BookID: book.ID, Book: *book, Side: entity.ItemOffered, BookVersion: book.Version}
+		}
+		err = tx.exchangeRepo.ReplaceItems(request.ID, entity.ItemOffered, offered)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		request.Items = append(sideItems(request, entity.ItemRequested), offered...)
+		first := offered[0].Book
+		request.OfferedBookID = &first.ID
+		request.OfferedBook = &first
+		request.OfferedBookVersion = first.Version
+
+		err = tx.apply(request, action, &userID, message)
+		if err != nil {
+			return err
+		}
+		err = u.recordOffer(tx, request, revision+1, userID, message)
+		if err != nil {
+			return err
+		}
+
+		msg := name + " countered the exchange for book " + request.RequestedBook.Title + " with " + bundleTitles(offered) + "."
+		notifyAfterCommit(tx.Tx, u.notificationService, recipientID, exchangeNotification, msg)
+		return nil
+	})
 }
 
-func (u *exchangeUsecase) DeclineExchange(request *entity.ExchangeRequest, userID uuid.UUID) error {
-	if request.RequestedToID != userID {
-		// return response.NewAuthorizationError("you do not have permission")
-		return response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+// GetExchangeOffers returns every revision of what the request offered,
+// oldest first.
+func (u *exchangeUsecase) GetExchangeOffers(request *entity.ExchangeRequest, userID uuid.UUID) ([]entity.ExchangeOffer, error) {
+	if request.RequestedByID != userID && request.RequestedToID != userID {
+		return nil, response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+	}
 
+	offers, err := u.exchangeOfferRepo.FindByRequestID(request.ID)
+	if err != nil {
+		return nil, response.NewInternalServerError()
 	}
-	if request.Status != "pending" {
-		return response.NewBadRequestError("request already accepted or declined")
+	if request.RequestedToID == userID && request.MeetupAgreedAt == nil {
+		for i := range offers {
+			if offers[i].OfferedBook != nil {
+				hidePickupPoint(offers[i].OfferedBook)
+			}
+		}
 	}
-	err := u.resolveExchangeRequest(request, "declined")
+	return offers, nil
+}
+
+// recordOffer keeps the books the request offers now as the given
+// revision.
+func (u *exchangeUsecase) recordOffer(tx *exchangeTx, request *entity.ExchangeRequest, revision int, proposedByID uuid.UUID, message string) error {
+	err := tx.exchangeOfferRepo.Create(&entity.ExchangeOffer{
+		ExchangeRequestID:  request.ID,
+		Revision:           revision,
+		ProposedByID:       proposedByID,
+		OfferedBookID:      *request.OfferedBookID,
+		OfferedBookIDs:     itemBookIDs(sideItems(request, entity.ItemOffered)),
+		OfferedBookVersion: request.OfferedBookVersion,
+		Message:            message,
+	})
 	if err != nil {
-		return err
+		return response.NewInternalServerError()
 	}
-
 	return nil
 }
 
 func (u *exchangeUsecase) ConfirmExchange(request *entity.ExchangeRequest, userID uuid.UUID) error {
-	if request.RequestedByID != userID && request.RequestedToID != userID {
-		// return response.NewAuthorizationError("you do not have permission")
-		return response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
-	}
-	if request.Status == "exchanged" {
-		return response.NewBadRequestError("request is already confirmed")
-	} else if request.Status == "declined" {
-		return response.NewBadRequestError("request is already declined")
-	} else if request.Status == "pending" {
-		return response.NewBadRequestError("request is not accepted")
-	}
+	return u.inTransaction(request, func(tx *exchangeTx) error {
+		var recipientID uuid.UUID
+		var msg string
+
+		if request.RequestedByID == userID {
+			recipientID = request.RequestedToID
+			msg = request.RequestedBy.FirstName + " confirmed the exchange request."
+		} else if request.RequestedToID == userID {
+			recipientID = request.RequestedByID
+			msg = request.RequestedTo.FirstName + " confirmed the exchange request."
+		}
+		err := confirmExchange(tx, request, userID, "")
+		if err != nil {
+			return err
+		}
 
-	var recipientID uuid.UUID
-	var msg string
+		notifyAfterCommit(tx.Tx, u.notificationService, recipientID, exchangeNotification, msg)
+		return nil
+	})
+}
 
+// confirmExchange records inside tx that userID received their books and
+// completes the exchange once both sides have.
+func confirmExchange(tx *exchangeTx, request *entity.ExchangeRequest, userID uuid.UUID, reason string) error {
 	if request.RequestedByID == userID {
 		request.RequestedByConfirmed = true
-		recipientID = request.RequestedToID
-		msg = request.RequestedBy.FirstName + " confirmed the exchange request."
 	} else if request.RequestedToID == userID {
 		request.RequestedToConfirmed = true
-		recipientID = request.RequestedByID
-		msg = request.RequestedTo.FirstName + " confirmed the exchange request."
 	}
-	err := u.resolveExchangeRequest(request, "exchanged")
+	err := tx.apply(request, actionConfirm, &userID, reason)
 	if err != nil {
 		return err
 	}
-
-	err = u.notificationService.SendNotification(recipientID, "exchange request", msg)
-	if err != nil {
-		log.Println("Failed Sending Notification confirm:", err)
-		return response.NewInternalServerError()
+	if request.RequestedByConfirmed && request.RequestedToConfirmed {
+		return tx.apply(request, actionComplete, nil, "both sides confirmed")
 	}
-
 	return nil
 }
 
-func (u *exchangeUsecase) DeleteExchangeRequest(request *entity.ExchangeRequest, userID uuid.UUID) error {
-	if request.RequestedByID != userID {
-		// return response.NewAuthorizationError("you do not have permission")
-		return response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
-	}
-	if request.Status != "pending" && request.Status != "declined" {
-		return response.NewBadRequestError("only pending requests can be deleted")
-	}
-	err := u.exchangeRepo.Delete(request)
-	if err != nil {
-		return response.NewInternalServerError()
-	}
-	if !request.RequestedBook.IsActive {
-		request.RequestedBook.IsActive = true
-		bookUpdates := map[string]interface{}{
-			"is_active": true,
+// AgreeMeetup records that userID agreed on where to meet. Once both sides
+// have, each can see the other's exact pickup point.
+func (u *exchangeUsecase) AgreeMeetup(request *entity.ExchangeRequest, userID uuid.UUID) error {
+	return u.inTransaction(request, func(tx *exchangeTx) error {
+		var recipientID uuid.UUID
+		var name string
+		if request.RequestedByID == userID {
+			request.RequestedByMeetupAgreed = true
+			recipientID, name = request.RequestedToID, request.RequestedBy.FirstName
+		} else {
+			request.RequestedToMeetupAgreed = true
+			recipientID, name = request.RequestedByID, request.RequestedTo.FirstName
+		}
+
+		var agreedAt *time.Time
+		if request.RequestedByMeetupAgreed && request.RequestedToMeetupAgreed {
+			now := time.Now()
+			agreedAt = &now
 		}
-		err := u.bookRepo.Update(&request.RequestedBook, bookUpdates)
+
+		err := tx.apply(request, actionAgreeMeetup, &userID, "")
 		if err != nil {
-			return response.NewInternalServerError()
+			return err
 		}
-	}
-	if !request.OfferedBook.IsActive {
-		request.OfferedBook.IsActive = true
-		bookUpdates := map[string]interface{}{
-			"is_active": true,
+		if agreedAt != nil {
+			// Set only now, as the transition's guard refuses requests
+			// whose meetup is already agreed.
+			request.MeetupAgreedAt = agreedAt
+			if err := tx.exchangeRepo.Update(request); err != nil {
+				return response.NewInternalServerError()
+			}
+			msg := "Meetup for book " + request.RequestedBook.Title + " is agreed. Exact pickup points are now visible."
+			notifyAfterCommit(tx.Tx, u.notificationService, request.RequestedByID, exchangeNotification, msg)
+			notifyAfterCommit(tx.Tx, u.notificationService, request.RequestedToID, exchangeNotification, msg)
+		} else {
+			msg := name + " agreed on the meetup for book " + request.RequestedBook.Title + ". Agree too to see each other's exact pickup point."
+			notifyAfterCommit(tx.Tx, u.notificationService, recipientID, exchangeNotification, msg)
 		}
-		err := u.bookRepo.Update(&request.OfferedBook, bookUpdates)
+		return nil
+	})
+}
+
+func (u *exchangeUsecase) DeleteExchangeRequest(request *entity.ExchangeRequest, userID uuid.UUID) error {
+	return u.inTransaction(request, func(tx *exchangeTx) error {
+		err := tx.apply(request, actionDelete, &userID, "")
 		if err != nil {
-			return response.NewInternalServerError()
+			return err
 		}
-	}
-
-	return nil
+		return reactivateBooks(tx, request)
+	})
+}
 
+// reactivateBooks puts the request's books back on the shelf, except those
+// another accepted exchange holds.
+func reactivateBooks(tx *exchangeTx, request *entity.ExchangeRequest) error {
+	var bookIDs []uint
+	for _, side := range []string{entity.ItemRequested, entity.ItemOffered} {
+		for _, item := range sideItems(request, side) {
+			if item.Book.IsActive {
+				continue
+			}
+			// A book is inactive because another exchange took it; that
+			// one keeps it.
+			accepted, err := tx.exchangeRepo.HasAcceptedRequestForBook(item.BookID)
+			if err != nil {
+				return response.NewInternalServerError()
+			}
+			if !accepted {
+				bookIDs = append(bookIDs, item.BookID)
+			}
+		}
+	}
+	return setBooksActive(tx, request, bookIDs, true)
 }
 
-func (u *exchangeUsecase) resolveExchangeRequest(request *entity.ExchangeRequest, status string) error {
-	switch status {
-	case "accepted":
-		request.Status = "accepted"
-		request.RequestedBook.IsActive = false
-		request.RequestedBook.IsActive = false
+// WithdrawExchange lets either side back out of a pending request when one
+// of the other side's books was materially changed after the request
+// pinned it.
+func (u *exchangeUsecase) WithdrawExchange(request *entity.ExchangeRequest, userID uuid.UUID) error {
+	if request.RequestedByID != userID && request.RequestedToID != userID {
+		return response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+	}
 
-		err := u.exchangeRepo.Update(request)
-		if err != nil {
-			return response.NewInternalServerError()
+	items := sideItems(request, entity.ItemRequested)
+	recipientID := request.RequestedToID
+	if request.RequestedToID == userID {
+		items = sideItems(request, entity.ItemOffered)
+		if len(items) == 0 {
+			return response.NewBadRequestError("claims have no offered book that could have changed")
 		}
-		bookUpdates := map[string]interface{}{
-			"is_active": false,
-		}
-		err = u.bookRepo.Update(&request.RequestedBook, bookUpdates)
+		recipientID = request.RequestedByID
+	}
+
+	changed := false
+	for _, item := range items {
+		itemChanged, err := u.bookVersionRepo.HasMaterialChangeSince(item.BookID, item.BookVersion)
 		if err != nil {
 			return response.NewInternalServerError()
 		}
-		err = u.bookRepo.Update(&request.OfferedBook, bookUpdates)
+		changed = changed || itemChanged
+	}
+	if !changed {
+		return response.NewBadRequestError("book has not changed since the request was made")
+	}
+
+	return u.inTransaction(request, func(tx *exchangeTx) error {
+		err := tx.apply(request, actionWithdraw, &userID, "book changed after the request was made")
 		if err != nil {
-			return response.NewInternalServerError()
+			return err
 		}
-		pendingRequests, err := u.exchangeRepo.FindPendingRequestsByBookID(request.RequestedBookID)
+
+		msg := "Exchange Request For Book " + request.RequestedBook.Title + " was withdrawn after a book in it changed."
+		notifyAfterCommit(tx.Tx, u.notificationService, recipientID, exchangeNotification, msg)
+		return nil
+	})
+}
+
+// BackfillItems gives requests made before bundles existed their items. It
+// runs once at startup and is a no-op afterwards.
+func (u *exchangeUsecase) BackfillItems() error {
+	return u.exchangeRepo.BackfillItems()
+}
+
+func (u *exchangeUsecase) GetExchangeHistory(request *entity.ExchangeRequest, userID uuid.UUID) ([]entity.ExchangeEvent, error) {
+	if request.RequestedByID != userID && request.RequestedToID != userID {
+		return nil, response.NewNotFoundError("exchange request", fmt.Sprintf("%v", request.ID))
+	}
+
+	events, err := u.exchangeEventRepo.FindByRequestID(request.ID)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	return events, nil
+}
+
+// acceptExchangeRequest accepts the request inside tx, takes all of its
+// books off the shelf and declines every other pending request for any
+// one of them.
+func (u *exchangeUsecase) acceptExchangeRequest(tx *exchangeTx, request *entity.ExchangeRequest, action exchangeAction, actorID *uuid.UUID, reason string) error {
+	now := time.Now()
+	request.AcceptedAt = &now
+	err := tx.apply(request, action, actorID, reason)
+	if err != nil {
+		return err
+	}
+
+	bookIDs := exchangeBookIDs(request)
+	err = setBooksActive(tx, request, bookIDs, false)
+	if err != nil {
+		return err
+	}
+
+	declined := map[uuid.UUID]bool{request.ID: true}
+	for _, bookID := range bookIDs {
+		pendingRequests, err := tx.exchangeRepo.FindPendingRequestsByBookID(bookID)
 		if err != nil {
 			return response.NewInternalServerError()
 		}
 		for _, pendingRequest := range pendingRequests {
-			if pendingRequest.ID != request.ID {
-				pendingRequest.Status = "declined"
-				err := u.exchangeRepo.Update(&pendingRequest)
-				if err != nil {
-					return response.NewInternalServerError()
-				} else {
-					msg := "Your Exchange Request For Book " + pendingRequest.RequestedBook.Title + " is declined."
-					err = u.notificationService.SendNotification(pendingRequest.RequestedByID, "exchange request", msg)
-					if err != nil {
-						log.Println("Failed Sending Notification for decline:", err)
-						return response.NewInternalServerError()
-					}
-				}
+			if declined[pendingRequest.ID] {
+				continue
 			}
+			declined[pendingRequest.ID] = true
+			err := tx.apply(&pendingRequest, actionAutoDecline, nil, "another request for the book was accepted")
+			if err != nil {
+				return err
+			}
+			msg := "Your Exchange Request For Book " + pendingRequest.RequestedBook.Title + " is declined."
+			notifyAfterCommit(tx.Tx, u.notificationService, pendingRequest.RequestedByID, exchangeNotification, msg)
 		}
-		msg := "Your Exchange Request For Book " + request.RequestedBook.Title + " is accepted."
-		err = u.notificationService.SendNotification(request.RequestedByID, "exchange request", msg)
-		if err != nil {
-			log.Println("Failed Sending Notification for accept:", err)
-			return response.NewInternalServerError()
-		}
-	case "declined":
-		request.Status = "declined"
-		err := u.exchangeRepo.Update(request)
-		if err != nil {
-			return response.NewInternalServerError()
-		}
-		msg := "Your Exchange Request For Book " + request.RequestedBook.Title + " is declined."
-		err = u.notificationService.SendNotification(request.RequestedByID, "exchange request", msg)
-		if err != nil {
-			log.Println("Failed Sending Notification for decline:", err)
-			return response.NewInternalServerError()
-		}
-	case "exchanged":
-		if request.RequestedByConfirmed && request.RequestedToConfirmed {
-			request.Status = "exchanged"
-		}
-		err := u.exchangeRepo.Update(request)
-		if err != nil {
-			return response.NewInternalServerError()
-		}
 	}
+	if action == actionAcceptOffer {
+		msg := request.RequestedBy.FirstName + " accepted your counter-offer for book " + request.RequestedBook.Title + "."
+		notifyAfterCommit(tx.Tx, u.notificationService, request.RequestedToID, exchangeNotification, msg)
+		return nil
+	}
+	msg := "Your Exchange Request For Book " + request.RequestedBook.Title + " is accepted."
+	notifyAfterCommit(tx.Tx, u.notificationService, request.RequestedByID, exchangeNotification, msg)
 	return nil
 }
 
+// sanitizeExchangeRequest hides what userID may not see of the other
+// side's books yet: contact details until the request is accepted, and
+// the exact pickup points until both sides agreed on a meetup.
 func (u *exchangeUsecase) sanitizeExchangeRequest(request *entity.ExchangeRequest, userID uuid.UUID) {
-	request.RequestedBook.Owner.Role = ""
-	request.OfferedBook.Owner.Role = ""
-	if request.RequestedByID == userID {
-		request.RequestedBook.PickupLocation.Latitude = 0
-		request.RequestedBook.PickupLocation.Longitude = 0
-		request.RequestedBook.Owner.Email = ""
-		request.RequestedBook.Owner.Phone = ""
-	} else if request.RequestedToID == userID {
-		request.OfferedBook.PickupLocation.Latitude = 0
-		request.OfferedBook.PickupLocation.Longitude = 0
-		request.OfferedBook.Owner.Email = ""
-		request.OfferedBook.Owner.Phone = ""
+	for _, book := range bookCopies(request) {
+		book.Owner.Role = ""
+		if book.UserID == userID {
+			continue
+		}
+		if request.MeetupAgreedAt == nil {
+			hidePickupPoint(book)
+		}
+		if request.Status != "accepted" && request.Status != "disputed" && request.Status != "exchanged" {
+			book.Owner.Email = ""
+			book.Owner.Phone = ""
+		}
 	}
 }
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/notification"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const favouriteNotification = "favourite"
+
+type FavouriteUsecase interface {
+	AddFavourite(userID uuid.UUID, bookID uint) (*entity.Favourite, error)
+	RemoveFavourite(userID uuid.UUID, bookID uint) error
+	GetFavourites(userID uuid.UUID) ([]entity.Favourite, error)
+}
+
+type favouriteUsecase struct {
+	favouriteRepo       repository.FavouriteRepository
+	bookRepo            repository.BookRepository
+	notificationService notification.Service
+}
+
+// NewFavouriteUsecase subscribes to availability changes on bookRepo, so
+// every path that flips a book's is_active alerts the users watching it.
+func NewFavouriteUsecase(favouriteRepo repository.FavouriteRepository, bookRepo repository.BookRepository, notificationService notification.Service) FavouriteUsecase {
+	u := &favouriteUsecase{
+		favouriteRepo:       favouriteRepo,
+		bookRepo:            bookRepo,
+		notificationService: notificationService,
+	}
+	bookRepo.OnAvailabilityChange(func(bookID uint, active bool) {
+		go u.notifyAvailabilityChange(bookID, active)
+	})
+	return u
+}
+
+func (u *favouriteUsecase) AddFavourite(userID uuid.UUID, bookID uint) (*entity.Favourite, error) {
+	book, err := u.bookRepo.FindByID(bookID)
+	if err != nil && err == gorm.ErrRecordNotFound {
+		return nil, response.NewNotFoundError("book", fmt.Sprintf("%v", bookID))
+	} else if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	if book.UserID == userID {
+		return nil, response.NewBadRequestError("Cannot favourite your own book")
+	}
+
+	favourite := &entity.Favourite{
+		UserID: userID,
+		BookID: book.ID,
+	}
+	err = u.favouriteRepo.Add(favourite)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	favourite.Book = *book
+	favourite.Book.Owner.Email = ""
+	favourite.Book.Owner.Phone = ""
+	favourite.Book.Owner.Role = ""
+	hidePickupPoint(&favourite.Book)
+	return favourite, nil
+}
+
+func (u *favouriteUsecase) RemoveFavourite(userID uuid.UUID, bookID uint) error {
+	removed, err := u.favouriteRepo.Remove(userID, bookID)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	if !removed {
+		return response.NewNotFoundError("favourite", fmt.Sprintf("%v", bookID))
+	}
+	return nil
+}
+
+func (u *favouriteUsecase) GetFavourites(userID uuid.UUID) ([]entity.Favourite, error) {
+	favourites, err := u.favouriteRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	for i := range favourites {
+		hidePickupPoint(&favourites[i].Book)
+	}
+	return favourites, nil
+}
+
+func (u *favouriteUsecase) notifyAvailabilityChange(bookID uint, active bool) {
+	book, err := u.bookRepo.FindByID(bookID)
+	if err != nil {
+		log.Printf("Failed Loading Book %d For Favourite Alerts: %v\n", bookID, err)
+		return
+	}
+
+	userIDs, err := u.favouriteRepo.FindUserIDsByBookID(bookID)
+	if err != nil {
+		log.Printf("Failed Finding Favourites For Book %d: %v\n", bookID, err)
+		return
+	}
+
+	msg := fmt.Sprintf("'%s' by %s from your favourites is no longer available.", book.Title, book.Author)
+	if active {
+		msg = fmt.Sprintf("'%s' by %s from your favourites is available again.", book.Title, book.Author)
+	}
+	for _, userID := range userIDs {
+		if userID == book.UserID {
+			continue
+		}
+		err := u.notificationService.SendNotification(userID, favouriteNotification, msg)
+		if err != nil {
+			log.Printf("Failed Sending Favourite Notification For Book %d: %v\n", bookID, err)
+		}
+	}
+}
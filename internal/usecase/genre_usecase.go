@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/taxonomy"
+	"gorm.io/gorm"
+)
+
+type GenreUsecase interface {
+	GetGenres() ([]entity.Genre, error)
+	GetGenreByID(id uint) (*entity.Genre, error)
+	CreateGenre(genre *entity.Genre, aliases []string) error
+	AddAlias(genre *entity.Genre, alias string) error
+	MergeGenres(target *entity.Genre, source *entity.Genre) error
+}
+
+type genreUsecase struct {
+	genreRepo repository.GenreRepository
+}
+
+func NewGenreUsecase(genreRepo repository.GenreRepository) GenreUsecase {
+	return &genreUsecase{genreRepo}
+}
+
+func (u *genreUsecase) GetGenres() ([]entity.Genre, error) {
+	genres, err := u.genreRepo.FindAll()
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	return genres, nil
+}
+
+func (u *genreUsecase) GetGenreByID(id uint) (*entity.Genre, error) {
+	genre, err := u.genreRepo.FindByID(id)
+	if err != nil && err == gorm.ErrRecordNotFound {
+		return nil, response.NewNotFoundError("genre", fmt.Sprintf("%d", id))
+	} else if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, response.NewInternalServerError()
+	}
+	return genre, nil
+}
+
+func (u *genreUsecase) CreateGenre(genre *entity.Genre, aliases []string) error {
+	genre.Name = strings.TrimSpace(genre.Name)
+	if genre.ParentID != nil {
+		if _, err := u.GetGenreByID(*genre.ParentID); err != nil {
+			return err
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, alias := range append([]string{genre.Name}, aliases...) {
+		key := taxonomy.Normalize(alias)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if _, err := u.genreRepo.FindByAlias(key); err == nil {
+			return response.NewConflictError("genre alias", key)
+		} else if err != gorm.ErrRecordNotFound {
+			return response.NewInternalServerError()
+		}
+		genre.Aliases = append(genre.Aliases, entity.GenreAlias{Alias: key})
+	}
+	if len(genre.Aliases) == 0 {
+		return response.NewBadRequestError("genre name is required")
+	}
+
+	err := u.genreRepo.Create(genre)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	return nil
+}
+
+func (u *genreUsecase) AddAlias(genre *entity.Genre, alias string) error {
+	key := taxonomy.Normalize(alias)
+	if key == "" {
+		return response.NewBadRequestError("alias is required")
+	}
+
+	existing, err := u.genreRepo.FindByAlias(key)
+	if err == nil {
+		if existing.ID == genre.ID {
+			return nil
+		}
+		return response.NewConflictError("genre alias", key)
+	} else if err != gorm.ErrRecordNotFound {
+		return response.NewInternalServerError()
+	}
+
+	newAlias := entity.GenreAlias{Alias: key, GenreID: genre.ID}
+	err = u.genreRepo.AddAlias(&newAlias)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	genre.Aliases = append(genre.Aliases, newAlias)
+	return nil
+}
+
+// MergeGenres folds a duplicate genre into target. The duplicate's name
+// keeps resolving because its aliases move over with its books.
+func (u *genreUsecase) MergeGenres(target *entity.Genre, source *entity.Genre) error {
+	if target.ID == source.ID {
+		return response.NewBadRequestError("cannot merge a genre into itself")
+	}
+	below, err := u.genreRepo.IsDescendant(target.ID, source.ID)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	if below {
+		return response.NewBadRequestError("cannot merge a genre into one of its sub-genres")
+	}
+
+	err = u.genreRepo.Merge(target, source)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+
+	merged, err := u.genreRepo.FindByID(target.ID)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	*target = *merged
+	return nil
+}
+
+// resolveGenre links the book to the genre its free-text genre names and
+// replaces the text with the canonical name. Unknown genres stay as typed.
+func resolveGenre(genreRepo repository.GenreRepository, book *entity.Book) error {
+	key := taxonomy.Normalize(book.Genre)
+	if key == "" {
+		book.GenreID = nil
+		return nil
+	}
+
+	genre, err := genreRepo.FindByAlias(key)
+	if err == gorm.ErrRecordNotFound {
+		book.GenreID = nil
+		return nil
+	} else if err != nil {
+		return err
+	}
+	book.GenreID = &genre.ID
+	book.Genre = genre.Name
+	return nil
+}
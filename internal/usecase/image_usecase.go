@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/imaging"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/storage"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const defaultThumbnailSize = 320
+
+type ImageUsecase interface {
+	UploadImage(userID uuid.UUID, data []byte) (*entity.Image, error)
+	GetImageByID(id uuid.UUID) (*entity.Image, error)
+	OpenImage(image *entity.Image, thumbnail bool) (io.ReadCloser, error)
+	DeleteImage(image *entity.Image, userID uuid.UUID) error
+}
+
+type imageUsecase struct {
+	imageRepo     repository.ImageRepository
+	storage       storage.Storage
+	thumbnailSize int
+}
+
+func NewImageUsecase(imageRepo repository.ImageRepository, storage storage.Storage, thumbnailSize int) ImageUsecase {
+	if thumbnailSize <= 0 {
+		thumbnailSize = defaultThumbnailSize
+	}
+	return &imageUsecase{imageRepo, storage, thumbnailSize}
+}
+
+func (u *imageUsecase) UploadImage(userID uuid.UUID, data []byte) (*entity.Image, error) {
+	processed, err := imaging.Process(data, u.thumbnailSize)
+	if err != nil {
+		switch err {
+		case imaging.ErrUnsupportedFormat:
+			return nil, response.NewUnsupportedMediaTypeError("only jpeg, png and gif images are accepted")
+		case imaging.ErrInvalidImage:
+			return nil, response.NewBadRequestError("image could not be decoded")
+		case imaging.ErrImageTooLarge:
+			return nil, response.NewBadRequestError("image dimensions are too large")
+		}
+		log.Println("Failed Processing Image:", err)
+		return nil, response.NewInternalServerError()
+	}
+
+	id := uuid.New()
+	ext := ".jpg"
+	if processed.ContentType == "image/png" {
+		ext = ".png"
+	}
+
+	image := &entity.Image{
+		ID:           id,
+		UserID:       userID,
+		StorageKey:   "images/" + id.String() + ext,
+		ThumbnailKey: "thumbnails/" + id.String() + ".jpg",
+		URL:          "/api/images/" + id.String() + "/file",
+		ThumbnailURL: "/api/images/" + id.String() + "/thumbnail",
+		ContentType:  processed.ContentType,
+		Size:         int64(len(processed.Data)),
+		Width:        processed.Width,
+		Height:       processed.Height,
+	}
+
+	ctx := context.Background()
+	err = u.storage.Put(ctx, image.StorageKey, processed.Data, processed.ContentType)
+	if err != nil {
+		log.Println("Failed Storing Image:", err)
+		return nil, response.NewInternalServerError()
+	}
+	err = u.storage.Put(ctx, image.ThumbnailKey, processed.Thumbnail, processed.ThumbnailType)
+	if err != nil {
+		log.Println("Failed Storing Thumbnail:", err)
+		u.removeObjects(image)
+		return nil, response.NewInternalServerError()
+	}
+
+	err = u.imageRepo.Create(image)
+	if err != nil {
+		u.removeObjects(image)
+		return nil, response.NewInternalServerError()
+	}
+
+	return image, nil
+}
+
+func (u *imageUsecase) GetImageByID(id uuid.UUID) (*entity.Image, error) {
+	image, err := u.imageRepo.FindByID(id)
+	if err != nil && err == gorm.ErrRecordNotFound {
+		return nil, response.NewNotFoundError("image", fmt.Sprintf("%v", id))
+	} else if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, response.NewInternalServerError()
+	}
+	return image, nil
+}
+
+func (u *imageUsecase) OpenImage(image *entity.Image, thumbnail bool) (io.ReadCloser, error) {
+	key := image.StorageKey
+	if thumbnail {
+		key = image.ThumbnailKey
+	}
+	reader, err := u.storage.Get(context.Background(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, response.NewNotFoundError("image", fmt.Sprintf("%v", image.ID))
+	} else if err != nil {
+		log.Println("Failed Reading Image:", err)
+		return nil, response.NewInternalServerError()
+	}
+	return reader, nil
+}
+
+func (u *imageUsecase) DeleteImage(image *entity.Image, userID uuid.UUID) error {
+	if image.UserID != userID {
+		return response.NewNotFoundError("image", fmt.Sprintf("%v", image.ID))
+	}
+	if image.BookID != nil {
+		return response.NewBadRequestError("image is attached to a book")
+	}
+	if image.MessageID != nil {
+		return response.NewBadRequestError("image is attached to a message")
+	}
+
+	err := u.imageRepo.Delete(image)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	u.removeObjects(image)
+	return nil
+}
+
+func (u *imageUsecase) removeObjects(image *entity.Image) {
+	ctx := context.Background()
+	if err := u.storage.Delete(ctx, image.StorageKey); err != nil {
+		log.Println("Failed Removing Image:", err)
+	}
+	if err := u.storage.Delete(ctx, image.ThumbnailKey); err != nil {
+		log.Println("Failed Removing Thumbnail:", err)
+	}
+}
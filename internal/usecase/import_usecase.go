@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxImportRows       = 1000
+	maxConcurrentImport = 2
+	importProgressEvery = 25
+)
+
+// ImportRow is one parsed line of an import file. Err holds the validation
+// message when the line was rejected before reaching the job.
+type ImportRow struct {
+	Row      int
+	Book     *entity.Book
+	ImageIDs []uuid.UUID
+	Err      string
+}
+
+type ImportUsecase interface {
+	StartImport(userID uuid.UUID, format string, dryRun bool, rows []ImportRow) (*entity.ImportJob, error)
+	GetImportJob(id uuid.UUID, userID uuid.UUID) (*entity.ImportJob, error)
+	GetImportJobs(userID uuid.UUID) ([]entity.ImportJob, error)
+	FailInterruptedJobs() error
+}
+
+type importUsecase struct {
+	importJobRepo repository.ImportJobRepository
+	bookUsecase   BookUsecase
+	slots         chan struct{}
+}
+
+func NewImportUsecase(importJobRepo repository.ImportJobRepository, bookUsecase BookUsecase) ImportUsecase {
+	return &importUsecase{
+		importJobRepo: importJobRepo,
+		bookUsecase:   bookUsecase,
+		slots:         make(chan struct{}, maxConcurrentImport),
+	}
+}
+
+func (u *importUsecase) StartImport(userID uuid.UUID, format string, dryRun bool, rows []ImportRow) (*entity.ImportJob, error) {
+	if len(rows) == 0 {
+		return nil, response.NewBadRequestError("import file has no rows")
+	}
+	if len(rows) > MaxImportRows {
+		return nil, response.NewBadRequestError(fmt.Sprintf("import is limited to %d rows", MaxImportRows))
+	}
+
+	job := &entity.ImportJob{
+		UserID:    userID,
+		Format:    format,
+		DryRun:    dryRun,
+		Status:    "queued",
+		TotalRows: len(rows),
+		Errors:    []entity.ImportRowError{},
+	}
+	err := u.importJobRepo.Create(job)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+
+	jobCopy := *job
+	go u.runImport(&jobCopy, rows)
+
+	return job, nil
+}
+
+func (u *importUsecase) runImport(job *entity.ImportJob, rows []ImportRow) {
+	u.slots <- struct{}{}
+	defer func() { <-u.slots }()
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Import Job %v Panicked: %v\n", job.ID, r)
+			u.finishImport(job, "failed", "import stopped unexpectedly")
+		}
+	}()
+
+	job.Status = "running"
+	if err := u.importJobRepo.Update(job); err != nil {
+		log.Printf("Failed Updating Import Job %v: %v\n", job.ID, err)
+	}
+
+	for i, row := range rows {
+		if row.Err == "" {
+			var err error
+			if job.DryRun {
+				err = u.bookUsecase.ValidateBook(row.Book, row.ImageIDs)
+			} else {
+				err = u.bookUsecase.AddBook(row.Book, row.ImageIDs)
+			}
+			if err != nil {
+				row.Err = err.Error()
+			}
+		}
+
+		if row.Err != "" {
+			job.FailedRows++
+			job.Errors = append(job.Errors, entity.ImportRowError{Row: row.Row, Message: row.Err})
+		} else {
+			job.ImportedRows++
+		}
+		job.ProcessedRows++
+
+		if (i+1)%importProgressEvery == 0 {
+			if err := u.importJobRepo.Update(job); err != nil {
+				log.Printf("Failed Updating Import Job %v: %v\n", job.ID, err)
+			}
+		}
+	}
+
+	u.finishImport(job, "completed", "")
+}
+
+func (u *importUsecase) finishImport(job *entity.ImportJob, status string, message string) {
+	now := time.Now()
+	job.Status = status
+	job.Message = message
+	job.FinishedAt = &now
+	if err := u.importJobRepo.Update(job); err != nil {
+		log.Printf("Failed Finishing Import Job %v: %v\n", job.ID, err)
+	}
+}
+
+func (u *importUsecase) GetImportJob(id uuid.UUID, userID uuid.UUID) (*entity.ImportJob, error) {
+	job, err := u.importJobRepo.FindByID(id)
+	if err != nil && err == gorm.ErrRecordNotFound {
+		return nil, response.NewNotFoundError("import job", fmt.Sprintf("%v", id))
+	} else if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, response.NewInternalServerError()
+	}
+	if job.UserID != userID {
+		return nil, response.NewNotFoundError("import job", fmt.Sprintf("%v", id))
+	}
+	return job, nil
+}
+
+func (u *importUsecase) GetImportJobs(userID uuid.UUID) ([]entity.ImportJob, error) {
+	jobs, err := u.importJobRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	return jobs, nil
+}
+
+// FailInterruptedJobs marks jobs left queued or running by a previous process
+// as failed, since their rows only lived in that process's memory.
+func (u *importUsecase) FailInterruptedJobs() error {
+	count, err := u.importJobRepo.FailUnfinished("import interrupted by server restart")
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Printf("Marked %d interrupted import jobs as failed\n", count)
+	}
+	return nil
+}
@@ -0,0 +1,448 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/notification"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	loanReminderWindow   = 48 * time.Hour
+	loanOverdueReminders = 24 * time.Hour
+	loanNotification     = "loan"
+)
+
+type LoanUsecase interface {
+	RequestLoan(loan *entity.Loan) error
+	GetLoanByID(id uuid.UUID, userID uuid.UUID) (*entity.Loan, error)
+	GetLoansByUserID(userID uuid.UUID) ([]entity.Loan, error)
+	ApproveLoan(loan *entity.Loan, userID uuid.UUID) error
+	DeclineLoan(loan *entity.Loan, userID uuid.UUID) error
+	CancelLoan(loan *entity.Loan, userID uuid.UUID) error
+	HandOverLoan(loan *entity.Loan, userID uuid.UUID) error
+	ConfirmReturn(loan *entity.Loan, userID uuid.UUID) error
+	RunReminders(ctx context.Context)
+}
+
+// Loan statuses of a book in the borrower's hands.
+var handedOverLoanStatuses = []string{"handed_over", "due", "overdue"}
+
+type loanUsecase struct {
+	loanRepo            repository.LoanRepository
+	bookRepo            repository.BookRepository
+	exchangeRepo        repository.ExchangeRepository
+	exchangeEventRepo   repository.ExchangeEventRepository
+	transactor          repository.Transactor
+	notificationService notification.Service
+}
+
+func NewLoanUsecase(loanRepo repository.LoanRepository, bookRepo repository.BookRepository, exchangeRepo repository.ExchangeRepository, exchangeEventRepo repository.ExchangeEventRepository, transactor repository.Transactor, notificationService notification.Service) LoanUsecase {
+	return &loanUsecase{loanRepo, bookRepo, exchangeRepo, exchangeEventRepo, transactor, notificationService}
+}
+
+// loanTx is a state change of one loan in progress: the repositories bound
+// to its transaction, with the loan's book locked.
+type loanTx struct {
+	*repository.Tx
+	loanRepo          repository.LoanRepository
+	bookRepo          repository.BookRepository
+	exchangeRepo      repository.ExchangeRepository
+	exchangeEventRepo repository.ExchangeEventRepository
+}
+
+// inTransaction runs fn as one transaction. The loan's book is locked
+// first and the loan row after it, the same order exchanges lock theirs
+// in, and the in-memory loan is refreshed from the locked rows so that fn
+// checks the current state.
+func (u *loanUsecase) inTransaction(loan *entity.Loan, fn func(tx *loanTx) error) error {
+	err := u.transactor.Run(func(tx *repository.Tx) error {
+		ltx := &loanTx{
+			Tx:                tx,
+			loanRepo:          u.loanRepo.WithTx(tx),
+			bookRepo:          u.bookRepo.WithTx(tx),
+			exchangeRepo:      u.exchangeRepo.WithTx(tx),
+			exchangeEventRepo: u.exchangeEventRepo.WithTx(tx),
+		}
+
+		books, err := ltx.bookRepo.LockByIDs([]uint{loan.BookID})
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		if len(books) == 0 {
+			return response.NewConflictError("book", "book was removed")
+		}
+		current, err := ltx.loanRepo.LockByID(loan.ID)
+		if err != nil && err == gorm.ErrRecordNotFound {
+			return response.NewNotFoundError("loan", fmt.Sprintf("%v", loan.ID))
+		} else if err != nil {
+			return response.NewInternalServerError()
+		}
+		loan.Book.IsActive = books[0].IsActive
+		loan.Status = current.Status
+		loan.HandedOverAt = current.HandedOverAt
+		loan.DueDate = current.DueDate
+		loan.LenderReturnConfirmed = current.LenderReturnConfirmed
+		loan.BorrowerReturnConfirmed = current.BorrowerReturnConfirmed
+		loan.ReturnedAt = current.ReturnedAt
+		loan.LastReminderAt = current.LastReminderAt
+
+		return fn(ltx)
+	})
+	return transactionError(err)
+}
+
+// update writes the given columns of a loan locked by tx.
+func (tx *loanTx) update(loan *entity.Loan, from []string, columns ...string) error {
+	if err := tx.loanRepo.Update(loan, from, columns...); err != nil {
+		return response.NewInternalServerError()
+	}
+	return nil
+}
+
+func (u *loanUsecase) RequestLoan(loan *entity.Loan) error {
+	if loan.Book.UserID == loan.BorrowerID {
+		return response.NewBadRequestError("Cannot Borrow Your Own Book")
+	}
+	if !loan.Book.IsActive {
+		return response.NewConflictError("book", "book is not available")
+	}
+
+	exists, err := u.loanRepo.HasRequest(loan.BorrowerID, loan.BookID)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	if exists {
+		return response.NewConflictError("loan", "you already requested this book")
+	}
+
+	loan.LenderID = loan.Book.UserID
+	loan.Status = "requested"
+	err = u.loanRepo.Create(loan)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+
+	u.sanitizeLoan(loan, loan.BorrowerID)
+
+	msg := fmt.Sprintf("You have a new request to borrow your book %s for %d days.", loan.Book.Title, loan.DurationDays)
+	u.notify(loan.LenderID, msg)
+	return nil
+}
+
+func (u *loanUsecase) GetLoanByID(id uuid.UUID, userID uuid.UUID) (*entity.Loan, error) {
+	loan, err := u.loanRepo.FindByID(id)
+	if err != nil && err == gorm.ErrRecordNotFound {
+		return nil, response.NewNotFoundError("loan", fmt.Sprintf("%v", id))
+	} else if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, response.NewInternalServerError()
+	}
+
+	if loan.LenderID != userID && loan.BorrowerID != userID {
+		return nil, response.NewNotFoundError("loan", fmt.Sprintf("%v", id))
+	}
+
+	u.sanitizeLoan(loan, userID)
+	return loan, nil
+}
+
+func (u *loanUsecase) GetLoansByUserID(userID uuid.UUID) ([]entity.Loan, error) {
+	loans, err := u.loanRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+
+	for i := range loans {
+		u.sanitizeLoan(&loans[i], userID)
+	}
+	return loans, nil
+}
+
+// ApproveLoan reserves the book for the borrower and declines every other
+// request to borrow or swap it.
+func (u *loanUsecase) ApproveLoan(loan *entity.Loan, userID uuid.UUID) error {
+	if loan.LenderID != userID {
+		return response.NewNotFoundError("loan", fmt.Sprintf("%v", loan.ID))
+	}
+
+	return u.inTransaction(loan, func(tx *loanTx) error {
+		if loan.Status != "requested" {
+			return response.NewBadRequestError("loan is not awaiting approval")
+		}
+		if !loan.Book.IsActive {
+			return response.NewConflictError("book", "book is not available")
+		}
+
+		loan.Status = "approved"
+		if err := tx.update(loan, []string{"requested"}, "status"); err != nil {
+			return err
+		}
+
+		loan.Book.IsActive = false
+		err := tx.bookRepo.Update(&loan.Book, map[string]interface{}{"is_active": false})
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+
+		otherLoans, err := tx.loanRepo.FindRequestedByBookID(loan.BookID)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		for _, other := range otherLoans {
+			if other.ID == loan.ID {
+				continue
+			}
+			other.Status = "declined"
+			err := tx.loanRepo.Update(&other, []string{"requested"}, "status")
+			if err != nil && err == gorm.ErrRecordNotFound {
+				// Cancelled by its borrower meanwhile.
+				continue
+			} else if err != nil {
+				return response.NewInternalServerError()
+			}
+			notifyAfterCommit(tx.Tx, u.notificationService, other.BorrowerID, loanNotification, "Your request to borrow "+other.Book.Title+" is declined.")
+		}
+
+		pendingRequests, err := tx.exchangeRepo.FindPendingRequestsByBookID(loan.BookID)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		for _, pendingRequest := range pendingRequests {
+			err := applyExchangeTransition(tx.exchangeRepo, tx.exchangeEventRepo, &pendingRequest, actionAutoDecline, nil, "book was lent out")
+			if err != nil {
+				return err
+			}
+
+			recipientID := pendingRequest.RequestedByID
+			if recipientID == loan.LenderID {
+				recipientID = pendingRequest.RequestedToID
+			}
+			msg := "Exchange Request involving book " + loan.Book.Title + " is declined because the book was lent out."
+			notifyAfterCommit(tx.Tx, u.notificationService, recipientID, exchangeNotification, msg)
+		}
+
+		notifyAfterCommit(tx.Tx, u.notificationService, loan.BorrowerID, loanNotification, "Your request to borrow "+loan.Book.Title+" is approved.")
+		return nil
+	})
+}
+
+func (u *loanUsecase) DeclineLoan(loan *entity.Loan, userID uuid.UUID) error {
+	if loan.LenderID != userID {
+		return response.NewNotFoundError("loan", fmt.Sprintf("%v", loan.ID))
+	}
+
+	return u.inTransaction(loan, func(tx *loanTx) error {
+		if loan.Status != "requested" {
+			return response.NewBadRequestError("loan is not awaiting approval")
+		}
+
+		loan.Status = "declined"
+		if err := tx.update(loan, []string{"requested"}, "status"); err != nil {
+			return err
+		}
+
+		notifyAfterCommit(tx.Tx, u.notificationService, loan.BorrowerID, loanNotification, "Your request to borrow "+loan.Book.Title+" is declined.")
+		return nil
+	})
+}
+
+// CancelLoan lets the borrower back out before the book is handed over.
+func (u *loanUsecase) CancelLoan(loan *entity.Loan, userID uuid.UUID) error {
+	if loan.BorrowerID != userID {
+		return response.NewNotFoundError("loan", fmt.Sprintf("%v", loan.ID))
+	}
+
+	return u.inTransaction(loan, func(tx *loanTx) error {
+		if loan.Status != "requested" && loan.Status != "approved" {
+			return response.NewBadRequestError("only loans that are not handed over can be cancelled")
+		}
+
+		approved := loan.Status == "approved"
+		loan.Status = "cancelled"
+		if err := tx.update(loan, []string{"requested", "approved"}, "status"); err != nil {
+			return err
+		}
+
+		if approved {
+			if err := reactivateLoanBook(tx, loan); err != nil {
+				return err
+			}
+		}
+
+		notifyAfterCommit(tx.Tx, u.notificationService, loan.LenderID, loanNotification, "The request to borrow "+loan.Book.Title+" was cancelled.")
+		return nil
+	})
+}
+
+// HandOverLoan is called by the lender once the borrower has the book and
+// starts the loan period.
+func (u *loanUsecase) HandOverLoan(loan *entity.Loan, userID uuid.UUID) error {
+	if loan.LenderID != userID {
+		return response.NewNotFoundError("loan", fmt.Sprintf("%v", loan.ID))
+	}
+
+	return u.inTransaction(loan, func(tx *loanTx) error {
+		if loan.Status != "approved" {
+			return response.NewBadRequestError("loan is not approved")
+		}
+
+		now := time.Now()
+		dueDate := now.AddDate(0, 0, loan.DurationDays)
+		loan.Status = "handed_over"
+		loan.HandedOverAt = &now
+		loan.DueDate = &dueDate
+		if err := tx.update(loan, []string{"approved"}, "status", "handed_over_at", "due_date"); err != nil {
+			return err
+		}
+
+		msg := fmt.Sprintf("You borrowed %s. Please return it by %s.", loan.Book.Title, dueDate.Format("2 Jan 2006"))
+		notifyAfterCommit(tx.Tx, u.notificationService, loan.BorrowerID, loanNotification, msg)
+		return nil
+	})
+}
+
+// ConfirmReturn records one side's confirmation. The loan is returned and
+// the book listed again only once both lender and borrower confirmed.
+func (u *loanUsecase) ConfirmReturn(loan *entity.Loan, userID uuid.UUID) error {
+	if loan.LenderID != userID && loan.BorrowerID != userID {
+		return response.NewNotFoundError("loan", fmt.Sprintf("%v", loan.ID))
+	}
+
+	return u.inTransaction(loan, func(tx *loanTx) error {
+		if !slices.Contains(handedOverLoanStatuses, loan.Status) {
+			return response.NewBadRequestError("loan is not handed over")
+		}
+
+		var recipientID uuid.UUID
+		var msg string
+		columns := []string{"status", "returned_at"}
+		if loan.LenderID == userID {
+			loan.LenderReturnConfirmed = true
+			recipientID = loan.BorrowerID
+			msg = loan.Lender.FirstName + " confirmed the return of " + loan.Book.Title + "."
+			columns = append(columns, "lender_return_confirmed")
+		} else {
+			loan.BorrowerReturnConfirmed = true
+			recipientID = loan.LenderID
+			msg = loan.Borrower.FirstName + " confirmed the return of " + loan.Book.Title + "."
+			columns = append(columns, "borrower_return_confirmed")
+		}
+
+		if loan.LenderReturnConfirmed && loan.BorrowerReturnConfirmed {
+			now := time.Now()
+			loan.Status = "returned"
+			loan.ReturnedAt = &now
+		}
+		if err := tx.update(loan, handedOverLoanStatuses, columns...); err != nil {
+			return err
+		}
+
+		if loan.Status == "returned" {
+			if err := reactivateLoanBook(tx, loan); err != nil {
+				return err
+			}
+		}
+
+		notifyAfterCommit(tx.Tx, u.notificationService, recipientID, loanNotification, msg)
+		return nil
+	})
+}
+
+// RunReminders moves loans nearing their due date to "due" and past it to
+// "overdue", reminding the borrower at each step and daily while overdue.
+func (u *loanUsecase) RunReminders(ctx context.Context) {
+	now := time.Now()
+
+	dueLoans, err := u.loanRepo.FindDueBefore(now.Add(loanReminderWindow))
+	if err != nil {
+		log.Printf("Failed Finding Due Loans: %v\n", err)
+		return
+	}
+	for _, loan := range dueLoans {
+		if ctx.Err() != nil {
+			return
+		}
+		if loan.DueDate.Before(now) {
+			// Picked up below as overdue.
+			continue
+		}
+		loan.Status = "due"
+		loan.LastReminderAt = &now
+		err := u.loanRepo.Update(&loan, []string{"handed_over"}, "status", "last_reminder_at")
+		if err != nil && err == gorm.ErrRecordNotFound {
+			// Returned meanwhile.
+			continue
+		} else if err != nil {
+			log.Printf("Failed Updating Loan %v: %v\n", loan.ID, err)
+			continue
+		}
+		msg := fmt.Sprintf("%s is due back on %s.", loan.Book.Title, loan.DueDate.Format("2 Jan 2006"))
+		u.notify(loan.BorrowerID, msg)
+	}
+
+	overdueLoans, err := u.loanRepo.FindOverdue(now, now.Add(-loanOverdueReminders))
+	if err != nil {
+		log.Printf("Failed Finding Overdue Loans: %v\n", err)
+		return
+	}
+	for _, loan := range overdueLoans {
+		if ctx.Err() != nil {
+			return
+		}
+		firstNotice := loan.Status != "overdue"
+		loan.Status = "overdue"
+		loan.LastReminderAt = &now
+		err := u.loanRepo.Update(&loan, handedOverLoanStatuses, "status", "last_reminder_at")
+		if err != nil && err == gorm.ErrRecordNotFound {
+			continue
+		} else if err != nil {
+			log.Printf("Failed Updating Loan %v: %v\n", loan.ID, err)
+			continue
+		}
+		msg := fmt.Sprintf("%s was due back on %s. Please return it.", loan.Book.Title, loan.DueDate.Format("2 Jan 2006"))
+		u.notify(loan.BorrowerID, msg)
+		if firstNotice {
+			u.notify(loan.LenderID, loan.Borrower.FirstName+" has not returned "+loan.Book.Title+" yet.")
+		}
+	}
+}
+
+func reactivateLoanBook(tx *loanTx, loan *entity.Loan) error {
+	loan.Book.IsActive = true
+	err := tx.bookRepo.Update(&loan.Book, map[string]interface{}{"is_active": true})
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	return nil
+}
+
+func (u *loanUsecase) notify(userID uuid.UUID, msg string) {
+	err := u.notificationService.SendNotification(userID, loanNotification, msg)
+	if err != nil {
+		log.Println("Failed Sending Loan Notification:", err)
+	}
+}
+
+// sanitizeLoan hides the lender's contact and exact pickup point from the
+// borrower until the loan is approved. Approving a request is the lender's
+// agreement to meet, so loans need no separate meetup step.
+func (u *loanUsecase) sanitizeLoan(loan *entity.Loan, userID uuid.UUID) {
+	loan.Book.Owner.Role = ""
+	if loan.BorrowerID != userID {
+		return
+	}
+	switch loan.Status {
+	case "requested", "declined", "cancelled":
+		hidePickupPoint(&loan.Book)
+		loan.Book.Owner.Email = ""
+		loan.Book.Owner.Phone = ""
+	}
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/pkg/geo"
+)
+
+// setPublicLocation derives the coordinates other users see from the exact
+// pickup point and the precision the owner chose for the book.
+func setPublicLocation(book *entity.Book) {
+	book.PublicLatitude, book.PublicLongitude = geo.Fuzz(book.PickupLocation.Latitude, book.PickupLocation.Longitude,
+		book.LocationPrecision, book.UserID.String())
+}
+
+// hidePickupPoint replaces the exact pickup coordinates of a book shown to
+// someone other than its owner with its public ones.
+func hidePickupPoint(book *entity.Book) {
+	book.PickupLocation.Latitude = book.PublicLatitude
+	book.PickupLocation.Longitude = book.PublicLongitude
+}
@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/geo"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/taxonomy"
+	"github.com/google/uuid"
+)
+
+const (
+	recommendationUserBatch  = 100
+	recommendationCandidates = 500
+	maxRecommendations       = 50
+
+	// Weights of each signal when building a user's taste profile.
+	favouriteSignalWeight = 3
+	exchangeSignalWeight  = 2
+	ownedSignalWeight     = 1
+)
+
+type RecommendationUsecase interface {
+	GetRecommendations(userID uuid.UUID, page, size int) ([]entity.Recommendation, int, error)
+	RunRecommendations(ctx context.Context)
+}
+
+type recommendationUsecase struct {
+	recommendationRepo repository.RecommendationRepository
+	bookRepo           repository.BookRepository
+	exchangeRepo       repository.ExchangeRepository
+	wishlistRepo       repository.WishlistRepository
+	favouriteRepo      repository.FavouriteRepository
+}
+
+func NewRecommendationUsecase(recommendationRepo repository.RecommendationRepository, bookRepo repository.BookRepository, exchangeRepo repository.ExchangeRepository, wishlistRepo repository.WishlistRepository, favouriteRepo repository.FavouriteRepository) RecommendationUsecase {
+	return &recommendationUsecase{
+		recommendationRepo: recommendationRepo,
+		bookRepo:           bookRepo,
+		exchangeRepo:       exchangeRepo,
+		wishlistRepo:       wishlistRepo,
+		favouriteRepo:      favouriteRepo,
+	}
+}
+
+func (u *recommendationUsecase) GetRecommendations(userID uuid.UUID, page, size int) ([]entity.Recommendation, int, error) {
+	recommendations, total, err := u.recommendationRepo.FindByUserID(userID, page, size)
+	if err != nil {
+		return nil, 0, response.NewInternalServerError()
+	}
+	return recommendations, total, nil
+}
+
+// RunRecommendations rebuilds the stored recommendations of every user.
+// It is meant to run from the scheduler, not from a request.
+func (u *recommendationUsecase) RunRecommendations(ctx context.Context) {
+	after := uuid.Nil
+	for ctx.Err() == nil {
+		userIDs, err := u.recommendationRepo.FindUserIDs(after, recommendationUserBatch)
+		if err != nil {
+			log.Printf("Failed Finding Users For Recommendations: %v\n", err)
+			return
+		}
+
+		for _, userID := range userIDs {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := u.recommendFor(userID); err != nil {
+				log.Printf("Failed Building Recommendations For User %v: %v\n", userID, err)
+			}
+		}
+		if len(userIDs) < recommendationUserBatch {
+			return
+		}
+		after = userIDs[len(userIDs)-1]
+	}
+}
+
+// tasteProfile summarises what a user has shown interest in.
+type tasteProfile struct {
+	genres      map[string]float64
+	genreTotal  float64
+	authors     map[string]bool
+	wishlist    []entity.WishlistItem
+	lat, lng    *float64
+	hasInterest bool
+}
+
+func (p *tasteProfile) addBook(book *entity.Book, weight float64, withAuthor bool) {
+	if key := taxonomy.Normalize(book.Genre); key != "" {
+		p.genres[key] += weight
+		p.genreTotal += weight
+		p.hasInterest = true
+	}
+	if key := taxonomy.Normalize(book.Author); withAuthor && key != "" {
+		p.authors[key] = true
+		p.hasInterest = true
+	}
+}
+
+func (u *recommendationUsecase) buildProfile(userID uuid.UUID) (*tasteProfile, error) {
+	profile := &tasteProfile{
+		genres:  make(map[string]float64),
+		authors: make(map[string]bool),
+	}
+
+	requests, err := u.exchangeRepo.FindRequestsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, request := range requests {
+		if request.RequestedByID == userID {
+			profile.addBook(&request.RequestedBook, exchangeSignalWeight, true)
+		} else if request.OfferedBook != nil && (request.Status == "accepted" || request.Status == "exchanged") {
+			profile.addBook(request.OfferedBook, exchangeSignalWeight, true)
+		}
+	}
+
+	favourites, err := u.favouriteRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, favourite := range favourites {
+		profile.addBook(&favourite.Book, favouriteSignalWeight, true)
+	}
+
+	profile.wishlist, err = u.wishlistRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(profile.wishlist) > 0 {
+		profile.hasInterest = true
+	}
+
+	// Owned books hint at taste and give the user's area, but on their own
+	// are not enough to recommend anything.
+	owned, err := u.bookRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	hasInterest := profile.hasInterest
+	var latSum, lngSum float64
+	for _, book := range owned {
+		profile.addBook(&book, ownedSignalWeight, false)
+		latSum += book.PickupLocation.Latitude
+		lngSum += book.PickupLocation.Longitude
+	}
+	profile.hasInterest = hasInterest
+
+	if len(owned) > 0 {
+		lat, lng := latSum/float64(len(owned)), lngSum/float64(len(owned))
+		profile.lat, profile.lng = &lat, &lng
+	} else {
+		for _, item := range profile.wishlist {
+			if item.Latitude != nil && item.Longitude != nil {
+				profile.lat, profile.lng = item.Latitude, item.Longitude
+				break
+			}
+		}
+	}
+	return profile, nil
+}
+
+func (u *recommendationUsecase) recommendFor(userID uuid.UUID) error {
+	profile, err := u.buildProfile(userID)
+	if err != nil {
+		return err
+	}
+	if !profile.hasInterest {
+		return u.recommendationRepo.Replace(userID, nil)
+	}
+
+	candidates, err := u.recommendationRepo.FindCandidates(userID, profile.lat, profile.lng, recommendationCandidates)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var recommendations []entity.Recommendation
+	for i := range candidates {
+		score, reason := scoreBook(profile, &candidates[i])
+		if score <= 0 {
+			continue
+		}
+		recommendations = append(recommendations, entity.Recommendation{
+			UserID:    userID,
+			BookID:    candidates[i].ID,
+			Score:     score,
+			Reason:    reason,
+			CreatedAt: now,
+		})
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Score > recommendations[j].Score
+	})
+	if len(recommendations) > maxRecommendations {
+		recommendations = recommendations[:maxRecommendations]
+	}
+	return u.recommendationRepo.Replace(userID, recommendations)
+}
+
+type scoreReason struct {
+	weight float64
+	text   string
+}
+
+// scoreBook rates a candidate against the profile and explains the
+// strongest reasons. Distance only boosts books that match some interest.
+func scoreBook(profile *tasteProfile, book *entity.Book) (float64, string) {
+	var reasons []scoreReason
+
+	for i := range profile.wishlist {
+		item := &profile.wishlist[i]
+		if wishlistItemMatches(item, book) {
+			reasons = append(reasons, scoreReason{5, fmt.Sprintf("Matches your wishlist item '%s'", describeWishlistItem(item))})
+			break
+		}
+	}
+	if key := taxonomy.Normalize(book.Genre); key != "" && profile.genreTotal > 0 {
+		if weight := profile.genres[key]; weight > 0 {
+			reasons = append(reasons, scoreReason{3 * weight / profile.genreTotal, fmt.Sprintf("Because you like %s books", book.Genre)})
+		}
+	}
+	if profile.authors[taxonomy.Normalize(book.Author)] {
+		reasons = append(reasons, scoreReason{2, fmt.Sprintf("By %s, an author you've shown interest in", book.Author)})
+	}
+	if len(reasons) == 0 {
+		return 0, ""
+	}
+
+	sort.SliceStable(reasons, func(i, j int) bool {
+		return reasons[i].weight > reasons[j].weight
+	})
+	var score float64
+	var texts []string
+	for i, reason := range reasons {
+		score += reason.weight
+		if i < 2 {
+			texts = append(texts, reason.text)
+		}
+	}
+
+	if profile.lat != nil && profile.lng != nil {
+		distance := geo.Distance(*profile.lat, *profile.lng, book.PublicLatitude, book.PublicLongitude)
+		score += 2 / (1 + distance/5)
+		texts = append(texts, fmt.Sprintf("%.1f km away", distance))
+	}
+	return score, strings.Join(texts, "; ")
+}
+
+func describeWishlistItem(item *entity.WishlistItem) string {
+	switch {
+	case item.Title != "":
+		return item.Title
+	case item.Author != "":
+		return item.Author
+	default:
+		return item.ISBN
+	}
+}
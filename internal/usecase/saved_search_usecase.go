@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/notification"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxSavedSearches   = 20
+	digestBatchSize    = 100
+	digestBookLimit    = 10
+	digestNotification = "digest"
+)
+
+var digestPeriods = map[string]time.Duration{
+	"daily":  24 * time.Hour,
+	"weekly": 7 * 24 * time.Hour,
+}
+
+type SavedSearchUsecase interface {
+	CreateSearch(search *entity.SavedSearch) error
+	GetSearchByID(id uuid.UUID) (*entity.SavedSearch, error)
+	GetSearchesByUserID(userID uuid.UUID) ([]entity.SavedSearch, error)
+	DeleteSearch(search *entity.SavedSearch, userID uuid.UUID) error
+	RunDueDigests(ctx context.Context)
+}
+
+type savedSearchUsecase struct {
+	savedSearchRepo     repository.SavedSearchRepository
+	notificationService notification.Service
+}
+
+func NewSavedSearchUsecase(savedSearchRepo repository.SavedSearchRepository, notificationService notification.Service) SavedSearchUsecase {
+	return &savedSearchUsecase{savedSearchRepo, notificationService}
+}
+
+func (u *savedSearchUsecase) CreateSearch(search *entity.SavedSearch) error {
+	period, ok := digestPeriods[search.Frequency]
+	if !ok {
+		return response.NewBadRequestError("frequency must be daily or weekly")
+	}
+	if search.RadiusKm > 0 && (search.Latitude == nil || search.Longitude == nil) {
+		return response.NewBadRequestError("latitude and longitude are required with radius_km")
+	}
+
+	count, err := u.savedSearchRepo.CountByUserID(search.UserID)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	if count >= maxSavedSearches {
+		return response.NewBadRequestError(fmt.Sprintf("saved searches are limited to %d", maxSavedSearches))
+	}
+
+	// Only books listed after the search is saved go into its digests.
+	now := time.Now()
+	search.CoveredUntil = &now
+	search.NextRunAt = now.Add(period)
+
+	err = u.savedSearchRepo.Create(search)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	return nil
+}
+
+func (u *savedSearchUsecase) GetSearchByID(id uuid.UUID) (*entity.SavedSearch, error) {
+	search, err := u.savedSearchRepo.FindByID(id)
+	if err != nil && err == gorm.ErrRecordNotFound {
+		return nil, response.NewNotFoundError("saved search", fmt.Sprintf("%v", id))
+	} else if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, response.NewInternalServerError()
+	}
+	return search, nil
+}
+
+func (u *savedSearchUsecase) GetSearchesByUserID(userID uuid.UUID) ([]entity.SavedSearch, error) {
+	searches, err := u.savedSearchRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	return searches, nil
+}
+
+func (u *savedSearchUsecase) DeleteSearch(search *entity.SavedSearch, userID uuid.UUID) error {
+	if search.UserID != userID {
+		return response.NewNotFoundError("saved search", fmt.Sprintf("%v", search.ID))
+	}
+
+	err := u.savedSearchRepo.Delete(search)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	return nil
+}
+
+// RunDueDigests sends a digest for every saved search whose next run has
+// passed and moves its watermark up to the start of this run. Each search
+// is tried once per run; one whose claim fails waits for the next run.
+func (u *savedSearchUsecase) RunDueDigests(ctx context.Context) {
+	now := time.Now()
+
+	var after *entity.SavedSearch
+	for ctx.Err() == nil {
+		searches, err := u.savedSearchRepo.FindDue(now, after, digestBatchSize)
+		if err != nil {
+			log.Printf("Failed Finding Due Saved Searches: %v\n", err)
+			return
+		}
+
+		for _, search := range searches {
+			if ctx.Err() != nil {
+				return
+			}
+			u.runDigest(&search, now)
+		}
+		if len(searches) < digestBatchSize {
+			return
+		}
+		after = &searches[len(searches)-1]
+	}
+}
+
+func (u *savedSearchUsecase) runDigest(search *entity.SavedSearch, now time.Time) {
+	period := digestPeriods[search.Frequency]
+	nextRunAt := search.NextRunAt
+	for !nextRunAt.After(now) {
+		nextRunAt = nextRunAt.Add(period)
+	}
+
+	claimed, err := u.savedSearchRepo.Claim(search, nextRunAt)
+	if err != nil {
+		log.Printf("Failed Claiming Saved Search %v: %v\n", search.ID, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	// Searches saved before activation times were kept start from their
+	// last run.
+	since := search.CreatedAt
+	if search.CoveredUntil != nil {
+		since = *search.CoveredUntil
+	} else if search.LastRunAt != nil {
+		since = *search.LastRunAt
+	}
+	books, total, err := u.savedSearchRepo.FindNewBooks(search, since, now, digestBookLimit)
+	if err != nil {
+		log.Printf("Failed Finding New Books For Saved Search %v: %v\n", search.ID, err)
+		return
+	}
+
+	if total > 0 {
+		u.sendDigest(search, books, total)
+	}
+
+	err = u.savedSearchRepo.Update(search, map[string]interface{}{
+		"covered_until": now,
+		"last_run_at":   now,
+	})
+	if err != nil {
+		log.Printf("Failed Updating Saved Search %v Watermark: %v\n", search.ID, err)
+	}
+}
+
+func (u *savedSearchUsecase) sendDigest(search *entity.SavedSearch, books []entity.Book, total int) {
+	var titles []string
+	for _, book := range books {
+		titles = append(titles, fmt.Sprintf("'%s' by %s", book.Title, book.Author))
+	}
+	if total > len(books) {
+		titles = append(titles, fmt.Sprintf("and %d more", total-len(books)))
+	}
+
+	msg := fmt.Sprintf("Your saved search '%s' has %d new %s: %s.", search.Name, total, pluralBooks(total), strings.Join(titles, ", "))
+	err := u.notificationService.SendNotification(search.UserID, digestNotification, msg)
+	if err != nil {
+		log.Printf("Failed Sending Digest For Saved Search %v: %v\n", search.ID, err)
+	}
+
+	if !search.Email {
+		return
+	}
+	subject := fmt.Sprintf("GyanPass %s digest: %s", search.Frequency, search.Name)
+	body := fmt.Sprintf("Your saved search '%s' has %d new %s:\n\n", search.Name, total, pluralBooks(total))
+	for _, title := range titles {
+		body += "- " + title + "\n"
+	}
+	err = u.notificationService.SendEmail(search.UserID, subject, body)
+	if err != nil && !errors.Is(err, notification.ErrEmailDisabled) {
+		log.Printf("Failed Emailing Digest For Saved Search %v: %v\n", search.ID, err)
+	}
+}
+
+func pluralBooks(n int) string {
+	if n == 1 {
+		return "book"
+	}
+	return "books"
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/arjnep/gyanpass/pkg/taxonomy"
+	"gorm.io/gorm"
+)
+
+const (
+	maxBookTags   = 10
+	maxTagLength  = 30
+	maxTagResults = 50
+)
+
+type TagUsecase interface {
+	SearchTags(prefix string) ([]entity.TagCount, error)
+	GetTagByID(id uint) (*entity.Tag, error)
+	MergeTags(target *entity.Tag, source *entity.Tag) error
+}
+
+type tagUsecase struct {
+	tagRepo repository.TagRepository
+}
+
+func NewTagUsecase(tagRepo repository.TagRepository) TagUsecase {
+	return &tagUsecase{tagRepo}
+}
+
+func (u *tagUsecase) SearchTags(prefix string) ([]entity.TagCount, error) {
+	tags, err := u.tagRepo.Search(taxonomy.Normalize(prefix), maxTagResults)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	return tags, nil
+}
+
+func (u *tagUsecase) GetTagByID(id uint) (*entity.Tag, error) {
+	tag, err := u.tagRepo.FindByID(id)
+	if err != nil && err == gorm.ErrRecordNotFound {
+		return nil, response.NewNotFoundError("tag", fmt.Sprintf("%d", id))
+	} else if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, response.NewInternalServerError()
+	}
+	return tag, nil
+}
+
+func (u *tagUsecase) MergeTags(target *entity.Tag, source *entity.Tag) error {
+	if target.ID == source.ID {
+		return response.NewBadRequestError("cannot merge a tag into itself")
+	}
+
+	err := u.tagRepo.Merge(target, source)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	return nil
+}
+
+// normalizeTags folds tag names to their lookup form, dropping blanks and
+// duplicates, and enforces the per-book limits.
+func normalizeTags(names []string) ([]string, error) {
+	var tags []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		tag := taxonomy.Normalize(name)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > maxTagLength {
+			return nil, response.NewBadRequestError(fmt.Sprintf("tags are limited to %d characters", maxTagLength))
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxBookTags {
+		return nil, response.NewBadRequestError(fmt.Sprintf("a book can have at most %d tags", maxBookTags))
+	}
+	return tags, nil
+}
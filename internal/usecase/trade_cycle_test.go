@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/google/uuid"
+)
+
+func TestFindTradeCycles(t *testing.T) {
+	users := make([]uuid.UUID, 8)
+	for i := range users {
+		users[i] = uuid.New()
+	}
+	// want builds the wants where each pair is {wanter, owner}, as indexes
+	// into users.
+	want := func(pairs ...[2]int) tradeWants {
+		wants := tradeWants{}
+		for i, pair := range pairs {
+			a, b := users[pair[0]], users[pair[1]]
+			if wants[a] == nil {
+				wants[a] = map[uuid.UUID]entity.Book{}
+			}
+			wants[a][b] = entity.Book{ID: uint(i + 1)}
+		}
+		return wants
+	}
+
+	tests := []struct {
+		name       string
+		wants      tradeWants
+		wantLength []int // lengths of the cycles found, in order
+	}{
+		{"nothing wanted", tradeWants{}, nil},
+		{"direct swap is no cycle", want([2]int{0, 1}, [2]int{1, 0}), nil},
+		{"three people", want([2]int{0, 1}, [2]int{1, 2}, [2]int{2, 0}), []int{3}},
+		{"four people", want([2]int{0, 1}, [2]int{1, 2}, [2]int{2, 3}, [2]int{3, 0}), []int{4}},
+		{"five people is too long", want([2]int{0, 1}, [2]int{1, 2}, [2]int{2, 3}, [2]int{3, 4}, [2]int{4, 0}), nil},
+		{"open chain", want([2]int{0, 1}, [2]int{1, 2}, [2]int{2, 3}), nil},
+		{
+			"shorter cycle wins a shared user",
+			want([2]int{0, 1}, [2]int{1, 2}, [2]int{2, 0}, [2]int{0, 3}, [2]int{3, 4}, [2]int{4, 5}, [2]int{5, 0}),
+			[]int{3},
+		},
+		{
+			"disjoint cycles",
+			want([2]int{0, 1}, [2]int{1, 2}, [2]int{2, 0}, [2]int{3, 4}, [2]int{4, 5}, [2]int{5, 6}, [2]int{6, 3}),
+			[]int{3, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cycles := findTradeCycles(tt.wants)
+
+			var lengths []int
+			seen := map[uuid.UUID]bool{}
+			for _, cycle := range cycles {
+				lengths = append(lengths, len(cycle))
+				for i, user := range cycle {
+					if seen[user] {
+						t.Errorf("user %v in more than one cycle", user)
+					}
+					seen[user] = true
+					next := cycle[(i+1)%len(cycle)]
+					if _, ok := tt.wants[user][next]; !ok {
+						t.Errorf("cycle %v: %v wants nothing from %v", cycle, user, next)
+					}
+				}
+			}
+			if !slices.Equal(lengths, tt.wantLength) {
+				t.Errorf("got cycles of lengths %v, want %v", lengths, tt.wantLength)
+			}
+		})
+	}
+}
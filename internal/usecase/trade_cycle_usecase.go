@@ -0,0 +1,504 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	cycleWishlistBatch  = 500
+	cycleMatchesPerItem = 10
+	cycleExpiryBatch    = 100
+	minCycleLength      = 3 // two people can swap directly
+	maxCycleLength      = 4
+)
+
+type TradeCycleUsecase interface {
+	GetCycleByID(id uuid.UUID, userID uuid.UUID) (*entity.TradeCycle, error)
+	GetCyclesByUserID(userID uuid.UUID) ([]entity.TradeCycle, error)
+	AcceptCycle(cycle *entity.TradeCycle, userID uuid.UUID) error
+	DeclineCycle(cycle *entity.TradeCycle, userID uuid.UUID) error
+	RunCycles(ctx context.Context)
+}
+
+// tradeCycleUsecase drives the legs of a cycle through the same
+// transactions and transitions as any other exchange request.
+type tradeCycleUsecase struct {
+	*exchangeUsecase
+	tradeCycleRepo repository.TradeCycleRepository
+	wishlistRepo   repository.WishlistRepository
+}
+
+// NewTradeCycleUsecase drives cycles through the given exchange usecase,
+// which must be the one made by NewExchangeUsecase.
+func NewTradeCycleUsecase(tradeCycleRepo repository.TradeCycleRepository, wishlistRepo repository.WishlistRepository, exchange ExchangeUsecase) TradeCycleUsecase {
+	return &tradeCycleUsecase{
+		exchangeUsecase: exchange.(*exchangeUsecase),
+		tradeCycleRepo:  tradeCycleRepo,
+		wishlistRepo:    wishlistRepo,
+	}
+}
+
+func (u *tradeCycleUsecase) GetCycleByID(id uuid.UUID, userID uuid.UUID) (*entity.TradeCycle, error) {
+	cycle, err := u.tradeCycleRepo.FindByID(id)
+	if err != nil && err == gorm.ErrRecordNotFound {
+		return nil, response.NewNotFoundError("trade cycle", fmt.Sprintf("%v", id))
+	} else if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	if cycleParticipant(cycle, userID) == nil {
+		return nil, response.NewNotFoundError("trade cycle", fmt.Sprintf("%v", id))
+	}
+
+	u.sanitizeCycle(cycle, userID)
+	return cycle, nil
+}
+
+func (u *tradeCycleUsecase) GetCyclesByUserID(userID uuid.UUID) ([]entity.TradeCycle, error) {
+	cycles, err := u.tradeCycleRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	for i := range cycles {
+		u.sanitizeCycle(&cycles[i], userID)
+	}
+	return cycles, nil
+}
+
+// AcceptCycle records that userID accepts the cycle. The last one to accept
+// accepts every leg at once, or ends the cycle if one of its books was
+// taken meanwhile.
+func (u *tradeCycleUsecase) AcceptCycle(cycle *entity.TradeCycle, userID uuid.UUID) error {
+	participant := cycleParticipant(cycle, userID)
+	if participant == nil {
+		return response.NewNotFoundError("trade cycle", fmt.Sprintf("%v", cycle.ID))
+	}
+
+	var bookIDs []uint
+	for i := range cycle.Legs {
+		bookIDs = append(bookIDs, exchangeBookIDs(&cycle.Legs[i])...)
+	}
+
+	err := u.transactor.Run(func(tx *repository.Tx) error {
+		etx := u.bindTx(tx)
+		cycleRepo := u.tradeCycleRepo.WithTx(tx)
+
+		locked, err := etx.bookRepo.LockByIDs(bookIDs)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		active := make(map[uint]bool, len(locked))
+		for _, book := range locked {
+			active[book.ID] = book.IsActive
+		}
+		if err := u.lockCycle(cycleRepo, cycle); err != nil {
+			return err
+		}
+
+		if participant.Accepted {
+			return response.NewBadRequestError("you already accepted this trade cycle")
+		}
+		now := time.Now()
+		if err := cycleRepo.AcceptParticipant(cycle.ID, userID, now); err != nil {
+			return response.NewInternalServerError()
+		}
+		participant.Accepted = true
+		participant.RespondedAt = &now
+
+		allAccepted := true
+		for _, other := range cycle.Participants {
+			allAccepted = allAccepted && other.Accepted
+		}
+		if !allAccepted {
+			for _, other := range cycle.Participants {
+				if other.UserID != userID {
+					notifyAfterCommit(etx.Tx, u.notificationService, other.UserID, exchangeNotification, participant.User.FirstName+" accepted the trade cycle. It goes ahead once everyone accepts.")
+				}
+			}
+			return nil
+		}
+
+		available := true
+		for i := range cycle.Legs {
+			leg := &cycle.Legs[i]
+			current, err := etx.exchangeRepo.LockByID(leg.ID)
+			if err != nil {
+				return response.NewInternalServerError()
+			}
+			leg.Status = current.Status
+			for _, book := range bookCopies(leg) {
+				book.IsActive = active[book.ID]
+			}
+			available = available && leg.Status == entity.ExchangePending && booksAvailable(leg) == nil
+		}
+		if !available {
+			return u.endCycle(etx, cycleRepo, cycle, entity.CycleDeclined, actionAutoDecline, "a book in the cycle is no longer available")
+		}
+
+		for i := range cycle.Legs {
+			err := u.acceptExchangeRequest(etx, &cycle.Legs[i], actionAutoAccept, nil, "every participant accepted the trade cycle")
+			if err != nil {
+				return err
+			}
+		}
+		if err := cycleRepo.UpdateStatus(cycle, entity.CycleAccepted); err != nil {
+			return response.NewInternalServerError()
+		}
+		for _, other := range cycle.Participants {
+			notifyAfterCommit(etx.Tx, u.notificationService, other.UserID, exchangeNotification, "Everyone accepted the trade cycle. Arrange each handover in its exchange request.")
+		}
+		return nil
+	})
+	return transactionError(err)
+}
+
+// DeclineCycle lets any participant call the whole cycle off.
+func (u *tradeCycleUsecase) DeclineCycle(cycle *entity.TradeCycle, userID uuid.UUID) error {
+	participant := cycleParticipant(cycle, userID)
+	if participant == nil {
+		return response.NewNotFoundError("trade cycle", fmt.Sprintf("%v", cycle.ID))
+	}
+
+	err := u.transactor.Run(func(tx *repository.Tx) error {
+		etx := u.bindTx(tx)
+		cycleRepo := u.tradeCycleRepo.WithTx(tx)
+		if err := u.lockCycle(cycleRepo, cycle); err != nil {
+			return err
+		}
+		return u.endCycle(etx, cycleRepo, cycle, entity.CycleDeclined, actionAutoDecline, participant.User.FirstName+" declined the trade cycle")
+	})
+	return transactionError(err)
+}
+
+// RunCycles ends proposed cycles not everyone accepted in time and proposes
+// new ones. It is meant to run from the scheduler, not from a request.
+func (u *tradeCycleUsecase) RunCycles(ctx context.Context) {
+	if u.timeouts.Pending > 0 {
+		u.expireCycles(ctx, time.Now().Add(-u.timeouts.Pending))
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	wants, err := u.findWants(ctx)
+	if err != nil {
+		log.Printf("Failed Building Trade Wants: %v\n", err)
+		return
+	}
+	for _, users := range findTradeCycles(wants) {
+		if ctx.Err() != nil {
+			return
+		}
+		err := u.proposeCycle(users, wants)
+		if err != nil && response.Status(err) != http.StatusConflict {
+			log.Printf("Failed Proposing Trade Cycle: %v\n", err)
+		}
+	}
+}
+
+func (u *tradeCycleUsecase) expireCycles(ctx context.Context, before time.Time) {
+	cycles, err := u.tradeCycleRepo.FindProposedBefore(before, cycleExpiryBatch)
+	if err != nil {
+		log.Printf("Failed Finding Stale Trade Cycles: %v\n", err)
+		return
+	}
+	for i := range cycles {
+		if ctx.Err() != nil {
+			return
+		}
+		cycle := &cycles[i]
+		err := u.transactor.Run(func(tx *repository.Tx) error {
+			etx := u.bindTx(tx)
+			cycleRepo := u.tradeCycleRepo.WithTx(tx)
+			if err := u.lockCycle(cycleRepo, cycle); err != nil {
+				return err
+			}
+			return u.endCycle(etx, cycleRepo, cycle, entity.CycleExpired, actionExpire, "not everyone accepted in time")
+		})
+		if err != nil && response.Status(transactionError(err)) != http.StatusBadRequest {
+			log.Printf("Failed Expiring Trade Cycle %v: %v\n", cycle.ID, err)
+		}
+	}
+}
+
+// lockCycle locks the cycle row, refreshes who accepted it and refuses
+// cycles no longer proposed.
+func (u *tradeCycleUsecase) lockCycle(cycleRepo repository.TradeCycleRepository, cycle *entity.TradeCycle) error {
+	current, err := cycleRepo.LockByID(cycle.ID)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	cycle.Status = current.Status
+	for i := range cycle.Participants {
+		if refreshed := cycleParticipant(current, cycle.Participants[i].UserID); refreshed != nil {
+			cycle.Participants[i].Accepted = refreshed.Accepted
+			cycle.Participants[i].RespondedAt = refreshed.RespondedAt
+		}
+	}
+	if cycle.Status != entity.CycleProposed {
+		return response.NewBadRequestError("trade cycle is already " + cycle.Status)
+	}
+	return nil
+}
+
+// endCycle moves the cycle to status and its still pending legs along with
+// it, telling every participant why.
+func (u *tradeCycleUsecase) endCycle(tx *exchangeTx, cycleRepo repository.TradeCycleRepository, cycle *entity.TradeCycle, status string, action exchangeAction, reason string) error {
+	for i := range cycle.Legs {
+		leg := &cycle.Legs[i]
+		current, err := tx.exchangeRepo.LockByID(leg.ID)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		leg.Status = current.Status
+		if leg.Status != entity.ExchangePending {
+			continue
+		}
+		if err := tx.apply(leg, action, nil, reason); err != nil {
+			return err
+		}
+	}
+	if err := cycleRepo.UpdateStatus(cycle, status); err != nil {
+		return response.NewInternalServerError()
+	}
+	for _, participant := range cycle.Participants {
+		notifyAfterCommit(tx.Tx, u.notificationService, participant.UserID, exchangeNotification, "The trade cycle is "+status+": "+reason+".")
+	}
+	return nil
+}
+
+// tradeWants maps who wants a book from whom: wants[a][b] is a book of b's
+// that a is looking for.
+type tradeWants map[uuid.UUID]map[uuid.UUID]entity.Book
+
+// findWants matches every wishlist item against the active books listed
+// for swap that are not part of an open request yet.
+func (u *tradeCycleUsecase) findWants(ctx context.Context) (tradeWants, error) {
+	candidates := map[uuid.UUID]map[uuid.UUID][]entity.Book{}
+	var bookIDs []uint
+	after := uuid.Nil
+	for ctx.Err() == nil {
+		items, err := u.wishlistRepo.FindAfter(after, cycleWishlistBatch)
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			books, err := u.wishlistRepo.FindMatchingBooks(&items[i], cycleMatchesPerItem)
+			if err != nil {
+				return nil, err
+			}
+			for _, book := range books {
+				if book.ListingType == "giveaway" {
+					continue
+				}
+				if candidates[items[i].UserID] == nil {
+					candidates[items[i].UserID] = map[uuid.UUID][]entity.Book{}
+				}
+				candidates[items[i].UserID][book.UserID] = append(candidates[items[i].UserID][book.UserID], book)
+				bookIDs = append(bookIDs, book.ID)
+			}
+		}
+		if len(items) < cycleWishlistBatch {
+			break
+		}
+		after = items[len(items)-1].ID
+	}
+
+	wants := tradeWants{}
+	if len(bookIDs) == 0 {
+		return wants, nil
+	}
+	busy, err := u.exchangeRepo.FindBookIDsInOpenRequests(bookIDs)
+	if err != nil {
+		return nil, err
+	}
+	for wanter, owners := range candidates {
+		for owner, books := range owners {
+			for _, book := range books {
+				if slices.Contains(busy, book.ID) {
+					continue
+				}
+				if wants[wanter] == nil {
+					wants[wanter] = map[uuid.UUID]entity.Book{}
+				}
+				wants[wanter][owner] = book
+				break
+			}
+		}
+	}
+	return wants, nil
+}
+
+// findTradeCycles picks disjoint cycles of who wants from whom, preferring
+// the shortest. Each user is in at most one of them.
+func findTradeCycles(wants tradeWants) [][]uuid.UUID {
+	users := make([]uuid.UUID, 0, len(wants))
+	for user := range wants {
+		users = append(users, user)
+	}
+	sortUUIDs(users)
+
+	used := map[uuid.UUID]bool{}
+	var cycles [][]uuid.UUID
+	for length := minCycleLength; length <= maxCycleLength; length++ {
+		for _, start := range users {
+			if used[start] {
+				continue
+			}
+			cycle := searchTradeCycle(wants, []uuid.UUID{start}, length, used)
+			if cycle == nil {
+				continue
+			}
+			for _, user := range cycle {
+				used[user] = true
+			}
+			cycles = append(cycles, cycle)
+		}
+	}
+	return cycles
+}
+
+func searchTradeCycle(wants tradeWants, path []uuid.UUID, length int, used map[uuid.UUID]bool) []uuid.UUID {
+	last := path[len(path)-1]
+	next := make([]uuid.UUID, 0, len(wants[last]))
+	for owner := range wants[last] {
+		next = append(next, owner)
+	}
+	sortUUIDs(next)
+
+	for _, owner := range next {
+		if owner == path[0] && len(path) == length {
+			return slices.Clone(path)
+		}
+		if len(path) == length || used[owner] || slices.Contains(path, owner) {
+			continue
+		}
+		if cycle := searchTradeCycle(wants, append(path, owner), length, used); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+func sortUUIDs(ids []uuid.UUID) {
+	slices.SortFunc(ids, func(a, b uuid.UUID) int {
+		return strings.Compare(a.String(), b.String())
+	})
+}
+
+// proposeCycle creates the cycle where users[i] gets a book from
+// users[i+1], the last one from the first, with a pending leg for each.
+func (u *tradeCycleUsecase) proposeCycle(users []uuid.UUID, wants tradeWants) error {
+	legs := make([]*entity.ExchangeRequest, len(users))
+	bookIDs := make([]uint, len(users))
+	for i, receiver := range users {
+		giver := users[(i+1)%len(users)]
+		book := wants[receiver][giver]
+		legs[i] = &entity.ExchangeRequest{
+			RequestedByID:   receiver,
+			RequestedToID:   giver,
+			RequestedBookID: book.ID,
+			RequestedBook:   book,
+			Type:            "cycle",
+		}
+		bookIDs[i] = book.ID
+	}
+
+	sorted := slices.Clone(bookIDs)
+	slices.Sort(sorted)
+	signature := make([]string, len(sorted))
+	for i, id := range sorted {
+		signature[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	cycle := &entity.TradeCycle{Signature: strings.Join(signature, ","), Status: entity.CycleProposed}
+	proposed, err := u.tradeCycleRepo.ExistsBySignature(cycle.Signature)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	if proposed {
+		return nil
+	}
+	for _, user := range users {
+		cycle.Participants = append(cycle.Participants, entity.TradeCycleParticipant{UserID: user})
+	}
+
+	err = u.transactor.Run(func(tx *repository.Tx) error {
+		etx := u.bindTx(tx)
+		locked, err := etx.bookRepo.LockByIDs(bookIDs)
+		if err != nil {
+			return response.NewInternalServerError()
+		}
+		if len(locked) != len(bookIDs) {
+			return response.NewConflictError("book", "a book in the cycle was removed")
+		}
+		if err := u.tradeCycleRepo.WithTx(tx).Create(cycle); err != nil {
+			return response.NewInternalServerError()
+		}
+
+		active := make(map[uint]bool, len(locked))
+		for _, book := range locked {
+			active[book.ID] = book.IsActive
+		}
+		for _, leg := range legs {
+			leg.TradeCycleID = &cycle.ID
+			if err := buildBundle(leg); err != nil {
+				return err
+			}
+			for _, book := range bookCopies(leg) {
+				book.IsActive = active[book.ID]
+			}
+			if err := etx.apply(leg, actionPropose, nil, "trade cycle found from wishlists"); err != nil {
+				return err
+			}
+		}
+
+		for i, leg := range legs {
+			given := legs[(i+len(legs)-1)%len(legs)].RequestedBook.Title
+			msg := fmt.Sprintf("A trade between %d people was found: you would get %s and give %s. Accept the trade cycle for it to go ahead.", len(users), leg.RequestedBook.Title, given)
+			notifyAfterCommit(etx.Tx, u.notificationService, leg.RequestedByID, exchangeNotification, msg)
+		}
+		return nil
+	})
+	return transactionError(err)
+}
+
+func cycleParticipant(cycle *entity.TradeCycle, userID uuid.UUID) *entity.TradeCycleParticipant {
+	for i := range cycle.Participants {
+		if cycle.Participants[i].UserID == userID {
+			return &cycle.Participants[i]
+		}
+	}
+	return nil
+}
+
+// sanitizeCycle shows userID only names of the other participants, and
+// of the legs they are not part of, no more than the books themselves.
+func (u *tradeCycleUsecase) sanitizeCycle(cycle *entity.TradeCycle, userID uuid.UUID) {
+	for i := range cycle.Participants {
+		user := &cycle.Participants[i].User
+		user.Email, user.Phone, user.Role = "", "", ""
+	}
+	for i := range cycle.Legs {
+		leg := &cycle.Legs[i]
+		if leg.RequestedByID == userID || leg.RequestedToID == userID {
+			u.sanitizeExchangeRequest(leg, userID)
+			continue
+		}
+		for _, book := range bookCopies(leg) {
+			hidePickupPoint(book)
+			book.Owner.Email, book.Owner.Phone, book.Owner.Role = "", "", ""
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/notification"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+)
+
+const exchangeNotification = "exchange request"
+
+// transactionError hands response errors from a transaction back as they
+// are and anything else, such as a failed commit, as an internal error.
+func transactionError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var responseErr *response.Error
+	if errors.As(err, &responseErr) {
+		return responseErr
+	}
+	log.Println("Failed Committing Change:", err)
+	return response.NewInternalServerError()
+}
+
+// notifyAfterCommit sends a notification once tx has committed. A failed
+// notification is only logged, as the change itself already happened.
+func notifyAfterCommit(tx *repository.Tx, notificationService notification.Service, userID uuid.UUID, notificationType string, msg string) {
+	tx.AfterCommit(func() {
+		err := notificationService.SendNotification(userID, notificationType, msg)
+		if err != nil {
+			log.Printf("Failed Sending %s Notification: %v\n", notificationType, err)
+		}
+	})
+}
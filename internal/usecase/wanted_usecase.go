@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxOpenWantedPosts = 20
+	maxWantedMatches   = 20
+)
+
+type WantedUsecase interface {
+	CreatePost(post *entity.WantedPost) error
+	GetPostByID(id uuid.UUID) (*entity.WantedPost, error)
+	GetPostsByUserID(userID uuid.UUID) ([]entity.WantedPost, error)
+	SearchPosts(queryParams map[string]string, location *entity.Location, radiusKm float64, page, size int) ([]entity.WantedPost, int, error)
+	ClosePost(post *entity.WantedPost, userID uuid.UUID) error
+	GetMatchingPosts(book *entity.Book, userID uuid.UUID) ([]entity.WantedPost, error)
+	OfferBook(post *entity.WantedPost, book *entity.Book, requestedBook *entity.Book, userID uuid.UUID) (*entity.ExchangeRequest, error)
+}
+
+type wantedUsecase struct {
+	wantedRepo      repository.WantedRepository
+	exchangeUsecase ExchangeUsecase
+}
+
+func NewWantedUsecase(wantedRepo repository.WantedRepository, exchangeUsecase ExchangeUsecase) WantedUsecase {
+	return &wantedUsecase{wantedRepo, exchangeUsecase}
+}
+
+func (u *wantedUsecase) CreatePost(post *entity.WantedPost) error {
+	posts, err := u.wantedRepo.FindByUserID(post.UserID)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	open := 0
+	for _, existing := range posts {
+		if existing.Status == "open" {
+			open++
+		}
+	}
+	if open >= maxOpenWantedPosts {
+		return response.NewBadRequestError(fmt.Sprintf("you can have at most %d open wanted posts", maxOpenWantedPosts))
+	}
+
+	post.Status = "open"
+	err = u.wantedRepo.Create(post)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	return nil
+}
+
+func (u *wantedUsecase) GetPostByID(id uuid.UUID) (*entity.WantedPost, error) {
+	post, err := u.wantedRepo.FindByID(id)
+	if err != nil && err == gorm.ErrRecordNotFound {
+		return nil, response.NewNotFoundError("wanted post", fmt.Sprintf("%v", id))
+	} else if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, response.NewInternalServerError()
+	}
+	return post, nil
+}
+
+func (u *wantedUsecase) GetPostsByUserID(userID uuid.UUID) ([]entity.WantedPost, error) {
+	posts, err := u.wantedRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	return posts, nil
+}
+
+func (u *wantedUsecase) SearchPosts(queryParams map[string]string, location *entity.Location, radiusKm float64, page, size int) ([]entity.WantedPost, int, error) {
+	posts, total, err := u.wantedRepo.Search(queryParams, location, radiusKm, page, size)
+	if err != nil {
+		return nil, 0, response.NewInternalServerError()
+	}
+	return posts, total, nil
+}
+
+func (u *wantedUsecase) ClosePost(post *entity.WantedPost, userID uuid.UUID) error {
+	if post.UserID != userID {
+		return response.NewNotFoundError("wanted post", fmt.Sprintf("%v", post.ID))
+	}
+	if post.Status == "closed" {
+		return response.NewBadRequestError("wanted post is already closed")
+	}
+
+	err := u.wantedRepo.Update(post, map[string]interface{}{"status": "closed"})
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	post.Status = "closed"
+	return nil
+}
+
+// GetMatchingPosts lists the open posts the owner's book could satisfy.
+func (u *wantedUsecase) GetMatchingPosts(book *entity.Book, userID uuid.UUID) ([]entity.WantedPost, error) {
+	if book.UserID != userID {
+		return nil, response.NewNotFoundError("book", fmt.Sprintf("%d", book.ID))
+	}
+
+	posts, err := u.wantedRepo.FindMatchingBook(book, maxWantedMatches)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	return posts, nil
+}
+
+// OfferBook answers a wanted post with "I have this". With a requestedBook
+// it starts a regular exchange request that offers the owner's book for one
+// of the poster's; without one the book is given to the poster outright.
+// Either way the exchange usecase tells the poster.
+func (u *wantedUsecase) OfferBook(post *entity.WantedPost, book *entity.Book, requestedBook *entity.Book, userID uuid.UUID) (*entity.ExchangeRequest, error) {
+	if post.Status != "open" {
+		return nil, response.NewBadRequestError("wanted post is closed")
+	}
+	if post.UserID == userID {
+		return nil, response.NewBadRequestError("Cannot Offer To Your Own Wanted Post")
+	}
+	if book.UserID != userID {
+		return nil, response.NewNotFoundError("book", fmt.Sprintf("%d", book.ID))
+	}
+	matches, err := u.wantedRepo.MatchesBook(post.ID, book)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	if !matches {
+		return nil, response.NewBadRequestError("book does not match the wanted post")
+	}
+
+	if requestedBook == nil {
+		return u.exchangeUsecase.GiveBook(&entity.ExchangeRequest{
+			RequestedByID:   post.UserID,
+			RequestedToID:   userID,
+			RequestedBookID: book.ID,
+			RequestedBook:   *book,
+		})
+	}
+
+	if requestedBook.UserID != post.UserID {
+		return nil, response.NewBadRequestError("requested book must belong to the poster")
+	}
+	return u.exchangeUsecase.RequestExchange(&entity.ExchangeRequest{
+		RequestedByID:   userID,
+		RequestedToID:   post.UserID,
+		RequestedBookID: requestedBook.ID,
+		RequestedBook:   *requestedBook,
+		OfferedBookID:   &book.ID,
+		OfferedBook:     book,
+	})
+}
@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/geo"
+	"github.com/arjnep/gyanpass/pkg/isbn"
+	"github.com/arjnep/gyanpass/pkg/notification"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	maxWishlistItems     = 50
+	maxWishlistMatches   = 50
+	wishlistQueueSize    = 4096
+	pendingMatchBatch    = 500
+	wishlistNotification = "wishlist"
+)
+
+type WishlistUsecase interface {
+	AddItem(item *entity.WishlistItem) error
+	GetItemByID(id uuid.UUID) (*entity.WishlistItem, error)
+	GetItemsByUserID(userID uuid.UUID) ([]entity.WishlistItem, error)
+	RemoveItem(item *entity.WishlistItem, userID uuid.UUID) error
+	GetMatches(item *entity.WishlistItem, userID uuid.UUID) ([]entity.Book, error)
+	QueueBookMatch(bookID uint)
+	RunPendingMatches(ctx context.Context)
+}
+
+type wishlistUsecase struct {
+	wishlistRepo        repository.WishlistRepository
+	bookRepo            repository.BookRepository
+	notificationService notification.Service
+	queue               chan uint
+}
+
+// NewWishlistUsecase starts the worker that checks queued books against
+// every wishlist, so callers of QueueBookMatch never wait on it.
+func NewWishlistUsecase(wishlistRepo repository.WishlistRepository, bookRepo repository.BookRepository, notificationService notification.Service) WishlistUsecase {
+	u := &wishlistUsecase{
+		wishlistRepo:        wishlistRepo,
+		bookRepo:            bookRepo,
+		notificationService: notificationService,
+		queue:               make(chan uint, wishlistQueueSize),
+	}
+	go u.runMatcher()
+	return u
+}
+
+func (u *wishlistUsecase) AddItem(item *entity.WishlistItem) error {
+	item.Title = strings.TrimSpace(item.Title)
+	item.Author = strings.TrimSpace(item.Author)
+	if item.ISBN != "" {
+		normalized, err := isbn.Normalize(item.ISBN)
+		if err != nil {
+			return response.NewBadRequestError("isbn is not a valid ISBN-10 or ISBN-13")
+		}
+		item.ISBN = normalized
+	}
+	if item.Title == "" && item.Author == "" && item.ISBN == "" {
+		return response.NewBadRequestError("title, author or isbn is required")
+	}
+	if item.RadiusKm > 0 && (item.Latitude == nil || item.Longitude == nil) {
+		return response.NewBadRequestError("latitude and longitude are required with radius_km")
+	}
+
+	count, err := u.wishlistRepo.CountByUserID(item.UserID)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	if count >= maxWishlistItems {
+		return response.NewBadRequestError(fmt.Sprintf("wishlist is limited to %d items", maxWishlistItems))
+	}
+
+	err = u.wishlistRepo.Create(item)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	return nil
+}
+
+func (u *wishlistUsecase) GetItemByID(id uuid.UUID) (*entity.WishlistItem, error) {
+	item, err := u.wishlistRepo.FindByID(id)
+	if err != nil && err == gorm.ErrRecordNotFound {
+		return nil, response.NewNotFoundError("wishlist item", fmt.Sprintf("%v", id))
+	} else if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, response.NewInternalServerError()
+	}
+	return item, nil
+}
+
+func (u *wishlistUsecase) GetItemsByUserID(userID uuid.UUID) ([]entity.WishlistItem, error) {
+	items, err := u.wishlistRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	return items, nil
+}
+
+func (u *wishlistUsecase) RemoveItem(item *entity.WishlistItem, userID uuid.UUID) error {
+	if item.UserID != userID {
+		return response.NewNotFoundError("wishlist item", fmt.Sprintf("%v", item.ID))
+	}
+
+	err := u.wishlistRepo.Delete(item)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	return nil
+}
+
+func (u *wishlistUsecase) GetMatches(item *entity.WishlistItem, userID uuid.UUID) ([]entity.Book, error) {
+	if item.UserID != userID {
+		return nil, response.NewNotFoundError("wishlist item", fmt.Sprintf("%v", item.ID))
+	}
+
+	books, err := u.wishlistRepo.FindMatchingBooks(item, maxWishlistMatches)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	for i := range books {
+		hidePickupPoint(&books[i])
+	}
+	return books, nil
+}
+
+// QueueBookMatch schedules a wishlist check for the book. When the queue
+// is full the book is stored for RunPendingMatches rather than blocking the
+// request.
+func (u *wishlistUsecase) QueueBookMatch(bookID uint) {
+	select {
+	case u.queue <- bookID:
+	default:
+		err := u.wishlistRepo.AddPendingMatch(bookID)
+		if err != nil {
+			log.Printf("Failed Storing Wishlist Match For Book %d: %v\n", bookID, err)
+		}
+	}
+}
+
+// RunPendingMatches checks the books that did not fit in the queue. A book
+// stays stored until its check ran, so a restart loses none of them.
+func (u *wishlistUsecase) RunPendingMatches(ctx context.Context) {
+	matches, err := u.wishlistRepo.FindPendingMatches(pendingMatchBatch)
+	if err != nil {
+		log.Printf("Failed Finding Pending Wishlist Matches: %v\n", err)
+		return
+	}
+	for _, match := range matches {
+		if ctx.Err() != nil {
+			return
+		}
+		u.matchBook(match.BookID)
+		if err := u.wishlistRepo.DeletePendingMatch(match.BookID); err != nil {
+			log.Printf("Failed Removing Pending Wishlist Match For Book %d: %v\n", match.BookID, err)
+		}
+	}
+}
+
+func (u *wishlistUsecase) runMatcher() {
+	for bookID := range u.queue {
+		u.matchBook(bookID)
+	}
+}
+
+func (u *wishlistUsecase) matchBook(bookID uint) {
+	book, err := u.bookRepo.FindByID(bookID)
+	if err != nil {
+		log.Printf("Failed Loading Book %d For Wishlist Match: %v\n", bookID, err)
+		return
+	}
+	if !book.IsActive {
+		return
+	}
+
+	items, err := u.wishlistRepo.FindCandidatesForBook(book)
+	if err != nil {
+		log.Printf("Failed Finding Wishlist Candidates For Book %d: %v\n", bookID, err)
+		return
+	}
+
+	for _, item := range items {
+		if !wishlistItemMatches(&item, book) {
+			continue
+		}
+
+		// One alert per user and book, however many of their items match
+		// or however often the book is edited.
+		recorded, err := u.wishlistRepo.RecordMatch(&entity.WishlistMatch{
+			UserID:         item.UserID,
+			BookID:         book.ID,
+			WishlistItemID: item.ID,
+		})
+		if err != nil {
+			log.Printf("Failed Recording Wishlist Match For Book %d: %v\n", bookID, err)
+			continue
+		}
+		if !recorded {
+			continue
+		}
+
+		msg := fmt.Sprintf("A book from your wishlist is now available: '%s' by %s.", book.Title, book.Author)
+		err = u.notificationService.SendNotification(item.UserID, wishlistNotification, msg)
+		if err != nil {
+			log.Printf("Failed Sending Wishlist Notification For Book %d: %v\n", bookID, err)
+		}
+	}
+}
+
+// wishlistItemMatches applies the same rules as
+// WishlistRepository.FindMatchingBooks to a single book.
+func wishlistItemMatches(item *entity.WishlistItem, book *entity.Book) bool {
+	if item.UserID == book.UserID {
+		return false
+	}
+	if item.RadiusKm > 0 && item.Latitude != nil && item.Longitude != nil {
+		distance := geo.Distance(*item.Latitude, *item.Longitude, book.PublicLatitude, book.PublicLongitude)
+		if distance > item.RadiusKm {
+			return false
+		}
+	}
+
+	if item.ISBN != "" && item.ISBN == book.ISBN {
+		return true
+	}
+	title := strings.ToLower(book.Title)
+	author := strings.ToLower(book.Author)
+	switch {
+	case item.Title != "":
+		return strings.Contains(title, strings.ToLower(item.Title)) &&
+			(item.Author == "" || strings.Contains(author, strings.ToLower(item.Author)))
+	case item.Author != "" && item.ISBN == "":
+		return strings.Contains(author, strings.ToLower(item.Author))
+	}
+	return false
+}
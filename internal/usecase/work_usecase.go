@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/arjnep/gyanpass/internal/entity"
+	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/response"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultNearbyRadiusKm = 10
+	maxNearbyCopies       = 20
+	rebuildBatchSize      = 200
+	titleSimilarity       = 0.8
+)
+
+var titleStopWords = map[string]bool{"the": true, "a": true, "an": true, "and": true, "of": true}
+
+type WorkUsecase interface {
+	GetWorkByID(id uint) (*entity.Work, error)
+	SearchWorks(title string, page, size int) ([]entity.Work, int, error)
+	GetWorkAvailability(work *entity.Work, location *entity.Location, radiusKm float64) (*entity.WorkAvailability, error)
+	RebuildWorks() (int, error)
+	MergeWorks(target *entity.Work, source *entity.Work) error
+}
+
+type workUsecase struct {
+	workRepo repository.WorkRepository
+}
+
+func NewWorkUsecase(workRepo repository.WorkRepository) WorkUsecase {
+	return &workUsecase{workRepo}
+}
+
+func (u *workUsecase) GetWorkByID(id uint) (*entity.Work, error) {
+	work, err := u.workRepo.FindByID(id)
+	if err != nil && err == gorm.ErrRecordNotFound {
+		return nil, response.NewNotFoundError("work", fmt.Sprintf("%d", id))
+	} else if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, response.NewInternalServerError()
+	}
+	return work, nil
+}
+
+func (u *workUsecase) SearchWorks(title string, page, size int) ([]entity.Work, int, error) {
+	works, total, err := u.workRepo.Search(title, page, size)
+	if err != nil {
+		return nil, 0, response.NewInternalServerError()
+	}
+	return works, total, nil
+}
+
+func (u *workUsecase) GetWorkAvailability(work *entity.Work, location *entity.Location, radiusKm float64) (*entity.WorkAvailability, error) {
+	copies, available, err := u.workRepo.CountCopies(work.ID)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+
+	availability := &entity.WorkAvailability{
+		Copies:    copies,
+		Available: available,
+	}
+	if location == nil {
+		return availability, nil
+	}
+
+	if radiusKm <= 0 {
+		radiusKm = defaultNearbyRadiusKm
+	}
+	nearby, err := u.workRepo.FindNearbyCopies(work.ID, location.Latitude, location.Longitude, radiusKm, maxNearbyCopies)
+	if err != nil {
+		return nil, response.NewInternalServerError()
+	}
+	for i := range nearby {
+		// whole kilometres only, so the distance can't be used to pinpoint the owner
+		nearby[i].DistanceKm = float64(int(nearby[i].DistanceKm + 0.5))
+	}
+
+	availability.RadiusKm = radiusKm
+	availability.AvailableNearby = len(nearby)
+	availability.NearbyCopies = nearby
+	return availability, nil
+}
+
+func (u *workUsecase) RebuildWorks() (int, error) {
+	linked := 0
+	for {
+		books, err := u.workRepo.FindBooksWithoutWork(rebuildBatchSize)
+		if err != nil {
+			return linked, response.NewInternalServerError()
+		}
+		if len(books) == 0 {
+			return linked, nil
+		}
+
+		for i := range books {
+			work, err := resolveWork(u.workRepo, &books[i])
+			if err != nil {
+				log.Printf("Failed Resolving Work For Book %d: %v\n", books[i].ID, err)
+				return linked, response.NewInternalServerError()
+			}
+			if err := u.workRepo.AssignWork(books[i].ID, work.ID); err != nil {
+				return linked, response.NewInternalServerError()
+			}
+			linked++
+		}
+	}
+}
+
+func (u *workUsecase) MergeWorks(target *entity.Work, source *entity.Work) error {
+	if target.ID == source.ID {
+		return response.NewBadRequestError("cannot merge a work into itself")
+	}
+
+	target.Authors = mergeStrings(target.Authors, source.Authors)
+	target.ISBNs = mergeStrings(target.ISBNs, source.ISBNs)
+	target.Genres = mergeStrings(target.Genres, source.Genres)
+
+	err := u.workRepo.Merge(target, source)
+	if err != nil {
+		return response.NewInternalServerError()
+	}
+	return nil
+}
+
+// resolveWork finds the work a copy belongs to, creating it when nothing
+// matches. Matching tries the ISBN first, then the normalized title and
+// author, and finally a fuzzy title match among works by the same author.
+func resolveWork(workRepo repository.WorkRepository, book *entity.Book) (*entity.Work, error) {
+	if book.ISBN != "" {
+		work, err := workRepo.FindByISBN(book.ISBN)
+		if err == nil {
+			return work, addToWork(workRepo, work, book)
+		} else if err != gorm.ErrRecordNotFound {
+			return nil, err
+		}
+	}
+
+	titleKey := normalizeTitle(book.Title)
+	authorKey := normalizeAuthor(book.Author)
+	dedupKey := titleKey + "|" + authorKey
+
+	work, err := workRepo.FindByDedupKey(dedupKey)
+	if err == nil {
+		return work, addToWork(workRepo, work, book)
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	if authorKey != "" {
+		candidates, err := workRepo.FindByAuthorKey(authorKey)
+		if err != nil {
+			return nil, err
+		}
+		for i := range candidates {
+			if titleKeySimilarity(titleKey, strings.SplitN(candidates[i].DedupKey, "|", 2)[0]) >= titleSimilarity {
+				return &candidates[i], addToWork(workRepo, &candidates[i], book)
+			}
+		}
+	}
+
+	work = &entity.Work{
+		Title:     strings.TrimSpace(book.Title),
+		Authors:   splitAuthors(book.Author),
+		DedupKey:  dedupKey,
+		AuthorKey: authorKey,
+	}
+	if book.ISBN != "" {
+		work.ISBNs = []string{book.ISBN}
+	}
+	if book.Genre != "" {
+		work.Genres = []string{book.Genre}
+	}
+	return work, workRepo.Create(work)
+}
+
+func addToWork(workRepo repository.WorkRepository, work *entity.Work, book *entity.Book) error {
+	changed := false
+	if book.ISBN != "" && !slices.Contains(work.ISBNs, book.ISBN) {
+		work.ISBNs = append(work.ISBNs, book.ISBN)
+		changed = true
+	}
+	if book.Genre != "" && !slices.ContainsFunc(work.Genres, func(g string) bool { return strings.EqualFold(g, book.Genre) }) {
+		work.Genres = append(work.Genres, book.Genre)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return workRepo.Update(work)
+}
+
+func normalizeTitle(title string) string {
+	var tokens []string
+	for _, token := range tokenize(title) {
+		if !titleStopWords[token] {
+			tokens = append(tokens, token)
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// normalizeAuthor keys on the first author's surname, so "J.K. Rowling" and
+// "Rowling" end up together.
+func normalizeAuthor(author string) string {
+	authors := splitAuthors(author)
+	if len(authors) == 0 {
+		return ""
+	}
+	tokens := tokenize(authors[0])
+	if len(tokens) == 0 {
+		return ""
+	}
+	return tokens[len(tokens)-1]
+}
+
+func splitAuthors(author string) []string {
+	var authors []string
+	for _, part := range strings.FieldsFunc(author, func(r rune) bool { return r == ',' || r == ';' || r == '&' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			authors = append(authors, part)
+		}
+	}
+	return authors
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+func titleKeySimilarity(a, b string) float64 {
+	setA := make(map[string]bool)
+	for _, token := range strings.Fields(a) {
+		setA[token] = true
+	}
+	setB := make(map[string]bool)
+	for _, token := range strings.Fields(b) {
+		setB[token] = true
+	}
+	if len(setA) == 0 || len(setB) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for token := range setA {
+		if setB[token] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(setA)+len(setB)-shared)
+}
+
+func mergeStrings(a, b []string) []string {
+	merged := slices.Clone(a)
+	for _, s := range b {
+		if !slices.Contains(merged, s) {
+			merged = append(merged, s)
+		}
+	}
+	return merged
+}
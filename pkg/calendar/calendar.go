@@ -0,0 +1,84 @@
+package calendar
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	timeLayout = "20060102T150405Z"
+	maxLine    = 75 // octets per content line, longer ones are folded
+)
+
+// Event is a single VEVENT of an iCalendar (RFC 5545) file.
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Location    string
+	Latitude    *float64
+	Longitude   *float64
+	Stamp       time.Time // when the event was last changed
+}
+
+// Encode renders the events as one iCalendar file. All times are written
+// in UTC.
+func Encode(events ...Event) []byte {
+	var b strings.Builder
+	line := func(name, value string) {
+		fold(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//GyanPass//Exchange Meetups//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	for _, event := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", escape(event.UID))
+		line("DTSTAMP", event.Stamp.UTC().Format(timeLayout))
+		line("DTSTART", event.Start.UTC().Format(timeLayout))
+		line("DTEND", event.End.UTC().Format(timeLayout))
+		line("SUMMARY", escape(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", escape(event.Description))
+		}
+		if event.Location != "" {
+			line("LOCATION", escape(event.Location))
+		}
+		if event.Latitude != nil && event.Longitude != nil {
+			line("GEO", strconv.FormatFloat(*event.Latitude, 'f', 6, 64)+";"+strconv.FormatFloat(*event.Longitude, 'f', 6, 64))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return []byte(b.String())
+}
+
+// escape makes text safe for a TEXT property value.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// fold writes a content line, breaking it into lines of at most maxLine
+// octets without splitting a UTF-8 sequence. Continuation lines start with
+// a space.
+func fold(b *strings.Builder, s string) {
+	limit := maxLine
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = maxLine - 1
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
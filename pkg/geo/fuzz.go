@@ -0,0 +1,56 @@
+package geo
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// Precision levels for coordinates shown to other users, finest first.
+const (
+	PrecisionNeighbourhood = "neighbourhood"
+	PrecisionDistrict      = "district"
+	PrecisionCity          = "city"
+)
+
+var precisionCellKm = map[string]float64{
+	PrecisionNeighbourhood: 1,
+	PrecisionDistrict:      5,
+	PrecisionCity:          20,
+}
+
+const kmPerDegree = EarthRadiusKm * math.Pi / 180
+
+func ValidPrecision(precision string) bool {
+	_, ok := precisionCellKm[precision]
+	return ok
+}
+
+// Fuzz snaps a coordinate to a grid cell of the given precision and returns
+// a point inside that cell picked by hashing the cell with seed. The result
+// only depends on the cell, so moving within it or asking again reveals
+// nothing more. Unknown precisions fall back to neighbourhood.
+func Fuzz(lat, lng float64, precision, seed string) (float64, float64) {
+	cellKm, ok := precisionCellKm[precision]
+	if !ok {
+		cellKm = precisionCellKm[PrecisionNeighbourhood]
+	}
+
+	latStep := cellKm / kmPerDegree
+	row := math.Floor(lat / latStep)
+	// Cells keep roughly the same width in km by widening towards the poles.
+	lngStep := cellKm / (kmPerDegree * math.Max(math.Cos(radians((row+0.5)*latStep)), 0.01))
+	col := math.Floor(lng / lngStep)
+
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s|%s|%d|%d", seed, precision, int64(row), int64(col))
+	sum := h.Sum64()
+	// Stay away from the cell's edges so the point cannot be mistaken for
+	// one in a neighbouring cell.
+	latOffset := 0.15 + 0.7*float64(sum&math.MaxUint32)/(1<<32)
+	lngOffset := 0.15 + 0.7*float64(sum>>32)/(1<<32)
+
+	fuzzedLat := math.Max(-90, math.Min(90, (row+latOffset)*latStep))
+	fuzzedLng := math.Mod((col+lngOffset)*lngStep+540, 360) - 180
+	return fuzzedLat, fuzzedLng
+}
@@ -0,0 +1,87 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFuzz(t *testing.T) {
+	tests := []struct {
+		name      string
+		lat, lng  float64
+		precision string
+	}{
+		{"kathmandu neighbourhood", 27.7172, 85.3240, PrecisionNeighbourhood},
+		{"kathmandu district", 27.7172, 85.3240, PrecisionDistrict},
+		{"kathmandu city", 27.7172, 85.3240, PrecisionCity},
+		{"southern hemisphere", -33.8688, 151.2093, PrecisionDistrict},
+		{"western hemisphere", 40.7128, -74.0060, PrecisionNeighbourhood},
+		{"equator and meridian", 0, 0, PrecisionCity},
+		{"antimeridian", 10, 179.999, PrecisionCity},
+		{"near the pole", 89.99, 45, PrecisionCity},
+		{"unknown precision", 27.7172, 85.3240, "street"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lat, lng := Fuzz(tt.lat, tt.lng, tt.precision, "seed")
+
+			if lat < -90 || lat > 90 || lng < -180 || lng >= 180 {
+				t.Fatalf("Fuzz = (%v, %v), out of range", lat, lng)
+			}
+			if againLat, againLng := Fuzz(tt.lat, tt.lng, tt.precision, "seed"); againLat != lat || againLng != lng {
+				t.Errorf("Fuzz not deterministic: (%v, %v) then (%v, %v)", lat, lng, againLat, againLng)
+			}
+
+			cellKm, ok := precisionCellKm[tt.precision]
+			if !ok {
+				cellKm = precisionCellKm[PrecisionNeighbourhood]
+			}
+			// Near the poles cells are clamped in width, so only check the
+			// distance where they keep their size.
+			if math.Abs(tt.lat) < 80 {
+				if d := Distance(tt.lat, tt.lng, lat, lng); d > cellKm*math.Sqrt2 {
+					t.Errorf("fuzzed point %.2f km away, want at most %.2f", d, cellKm*math.Sqrt2)
+				}
+			}
+		})
+	}
+}
+
+func TestFuzzSameCell(t *testing.T) {
+	// Two points a few metres apart inside the same 20 km cell.
+	lat1, lng1 := Fuzz(27.7172, 85.3240, PrecisionCity, "seed")
+	lat2, lng2 := Fuzz(27.7173, 85.3241, PrecisionCity, "seed")
+	if lat1 != lat2 || lng1 != lng2 {
+		t.Errorf("points in one cell fuzzed apart: (%v, %v) and (%v, %v)", lat1, lng1, lat2, lng2)
+	}
+}
+
+func TestFuzzSeed(t *testing.T) {
+	lat1, lng1 := Fuzz(27.7172, 85.3240, PrecisionNeighbourhood, "one")
+	lat2, lng2 := Fuzz(27.7172, 85.3240, PrecisionNeighbourhood, "two")
+	if lat1 == lat2 && lng1 == lng2 {
+		t.Errorf("different seeds gave the same point (%v, %v)", lat1, lng1)
+	}
+}
+
+func TestFuzzUnknownPrecision(t *testing.T) {
+	lat1, lng1 := Fuzz(27.7172, 85.3240, "street", "seed")
+	lat2, lng2 := Fuzz(27.7172, 85.3240, PrecisionNeighbourhood, "seed")
+	// The precision is hashed along with the cell, so only the cell size
+	// falls back; the point has to land in the same neighbourhood cell.
+	if d := Distance(lat1, lng1, lat2, lng2); d > precisionCellKm[PrecisionNeighbourhood]*math.Sqrt2 {
+		t.Errorf("unknown precision landed %.2f km from the neighbourhood cell", d)
+	}
+}
+
+func TestValidPrecision(t *testing.T) {
+	for _, precision := range []string{PrecisionNeighbourhood, PrecisionDistrict, PrecisionCity} {
+		if !ValidPrecision(precision) {
+			t.Errorf("ValidPrecision(%q) = false", precision)
+		}
+	}
+	if ValidPrecision("street") {
+		t.Error(`ValidPrecision("street") = true`)
+	}
+}
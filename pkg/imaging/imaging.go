@@ -0,0 +1,158 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const (
+	jpegQuality      = 90
+	thumbnailQuality = 80
+	maxPixels        = 40_000_000
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrInvalidImage      = errors.New("invalid image data")
+	ErrImageTooLarge     = errors.New("image dimensions too large")
+)
+
+type Result struct {
+	Data          []byte
+	ContentType   string
+	Width         int
+	Height        int
+	Thumbnail     []byte
+	ThumbnailType string
+}
+
+// Process sniffs the uploaded bytes, decodes them and re-encodes the picture
+// from raw pixels. Re-encoding drops every metadata block (EXIF, GPS, XMP,
+// text chunks), so the EXIF orientation is applied to the pixels beforehand.
+func Process(data []byte, thumbnailSize int) (*Result, error) {
+	contentType := http.DetectContentType(data)
+
+	var decode func([]byte) (image.Image, error)
+	switch contentType {
+	case "image/jpeg":
+		decode = func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) }
+	case "image/png":
+		decode = func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) }
+	case "image/gif":
+		decode = func(b []byte) (image.Image, error) { return gif.Decode(bytes.NewReader(b)) }
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	imgCfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if imgCfg.Width <= 0 || imgCfg.Height <= 0 || imgCfg.Width*imgCfg.Height > maxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	img, err := decode(data)
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	if contentType == "image/jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	var out bytes.Buffer
+	outType := contentType
+	switch contentType {
+	case "image/jpeg":
+		err = jpeg.Encode(&out, img, &jpeg.Options{Quality: jpegQuality})
+	default:
+		outType = "image/png"
+		err = png.Encode(&out, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var thumb bytes.Buffer
+	err = jpeg.Encode(&thumb, Thumbnail(img, thumbnailSize), &jpeg.Options{Quality: thumbnailQuality})
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	return &Result{
+		Data:          out.Bytes(),
+		ContentType:   outType,
+		Width:         bounds.Dx(),
+		Height:        bounds.Dy(),
+		Thumbnail:     thumb.Bytes(),
+		ThumbnailType: "image/jpeg",
+	}, nil
+}
+
+// Thumbnail scales img down to fit in a size x size box using an area
+// average and flattens any transparency onto white.
+func Thumbnail(img image.Image, size int) image.Image {
+	src := toRGBA(img)
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+
+	dw, dh := sw, sh
+	if sw > size || sh > size {
+		if sw >= sh {
+			dw = size
+			dh = max(1, sh*size/sw)
+		} else {
+			dh = size
+			dw = max(1, sw*size/sh)
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(src.Pix[i])
+					g += uint32(src.Pix[i+1])
+					b += uint32(src.Pix[i+2])
+					a += uint32(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+
+			// premultiplied alpha: blend onto white
+			r, g, b, a = r/n, g/n, b/n, a/n
+			o := dst.PixOffset(x, y)
+			dst.Pix[o] = uint8(r + 255 - a)
+			dst.Pix[o+1] = uint8(g + 255 - a)
+			dst.Pix[o+2] = uint8(b + 255 - a)
+			dst.Pix[o+3] = 255
+		}
+	}
+	return dst
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), &image.Uniform{color.Transparent}, image.Point{}, draw.Src)
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Over)
+	return rgba
+}
@@ -0,0 +1,88 @@
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalid = errors.New("invalid isbn")
+
+// Normalize strips separators, validates the ISBN-10 or ISBN-13 checksum and
+// returns the ISBN-13 form so that both editions of a number compare equal.
+func Normalize(s string) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9':
+			return r
+		case r == 'x' || r == 'X':
+			return 'X'
+		case r == '-' || r == ' ':
+			return -1
+		}
+		return '?'
+	}, strings.TrimSpace(s))
+
+	switch len(digits) {
+	case 10:
+		if !validISBN10(digits) {
+			return "", ErrInvalid
+		}
+		return toISBN13(digits), nil
+	case 13:
+		if !validISBN13(digits) {
+			return "", ErrInvalid
+		}
+		return digits, nil
+	}
+	return "", ErrInvalid
+}
+
+func IsValid(s string) bool {
+	_, err := Normalize(s)
+	return err == nil
+}
+
+func validISBN10(s string) bool {
+	sum := 0
+	for i := 0; i < 10; i++ {
+		var v int
+		switch {
+		case s[i] >= '0' && s[i] <= '9':
+			v = int(s[i] - '0')
+		case s[i] == 'X' && i == 9:
+			v = 10
+		default:
+			return false
+		}
+		sum += v * (10 - i)
+	}
+	return sum%11 == 0
+}
+
+func validISBN13(s string) bool {
+	sum := 0
+	for i := 0; i < 13; i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+		v := int(s[i] - '0')
+		if i%2 == 1 {
+			v *= 3
+		}
+		sum += v
+	}
+	return sum%10 == 0
+}
+
+func toISBN13(isbn10 string) string {
+	body := "978" + isbn10[:9]
+	sum := 0
+	for i := 0; i < 12; i++ {
+		v := int(body[i] - '0')
+		if i%2 == 1 {
+			v *= 3
+		}
+		sum += v
+	}
+	return body + string(rune('0'+(10-sum%10)%10))
+}
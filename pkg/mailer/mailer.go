@@ -0,0 +1,65 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/arjnep/gyanpass/config"
+)
+
+const defaultSMTPPort = "587"
+
+// Mailer delivers plain text email.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// NewMailer builds an SMTP mailer. It returns nil when no SMTP host is
+// configured, which disables email delivery.
+func NewMailer(cfg *config.Configuration) Mailer {
+	if cfg.Mail.SMTPHost == "" {
+		return nil
+	}
+	port := cfg.Mail.SMTPPort
+	if port == "" {
+		port = defaultSMTPPort
+	}
+	return &smtpMailer{
+		addr:     net.JoinHostPort(cfg.Mail.SMTPHost, port),
+		host:     cfg.Mail.SMTPHost,
+		username: cfg.Mail.Username,
+		password: cfg.Mail.Password,
+		from:     cfg.Mail.From,
+	}
+}
+
+type smtpMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+func (m *smtpMailer) Send(to, subject, body string) error {
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return fmt.Errorf("invalid mail header")
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	msg := "From: " + m.from + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" +
+		strings.ReplaceAll(body, "\n", "\r\n")
+
+	return smtp.SendMail(m.addr, auth, m.from, []string{to}, []byte(msg))
+}
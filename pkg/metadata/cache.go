@@ -0,0 +1,76 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const maxCacheEntries = 10000
+
+type cacheEntry struct {
+	meta      *Metadata
+	notFound  bool
+	expiresAt time.Time
+}
+
+type cachedProvider struct {
+	provider MetadataProvider
+	ttl      time.Duration
+	mu       sync.Mutex
+	entries  map[string]cacheEntry
+}
+
+// NewCachedProvider remembers successful lookups and misses for ttl. Provider
+// errors are not cached so a flaky upstream is retried on the next call.
+func NewCachedProvider(provider MetadataProvider, ttl time.Duration) MetadataProvider {
+	return &cachedProvider{
+		provider: provider,
+		ttl:      ttl,
+		entries:  make(map[string]cacheEntry),
+	}
+}
+
+func (p *cachedProvider) Lookup(ctx context.Context, isbn string) (*Metadata, error) {
+	now := time.Now()
+
+	p.mu.Lock()
+	entry, ok := p.entries[isbn]
+	p.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		if entry.notFound {
+			return nil, ErrNotFound
+		}
+		meta := *entry.meta
+		return &meta, nil
+	}
+
+	meta, err := p.provider.Lookup(ctx, isbn)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	if len(p.entries) >= maxCacheEntries {
+		p.evictExpired(now)
+	}
+	if len(p.entries) < maxCacheEntries {
+		p.entries[isbn] = cacheEntry{meta: meta, notFound: err != nil, expiresAt: now.Add(p.ttl)}
+	}
+	p.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	result := *meta
+	return &result, nil
+}
+
+func (p *cachedProvider) evictExpired(now time.Time) {
+	for key, entry := range p.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(p.entries, key)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/arjnep/gyanpass/pkg/isbn"
+)
+
+type catalogProvider struct {
+	books map[string]Metadata
+}
+
+// NewCatalogProvider loads a JSON array of Metadata records from path. Each
+// record's ISBN may be written as ISBN-10 or ISBN-13, with or without dashes.
+func NewCatalogProvider(path string) (MetadataProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read metadata catalog: %v", err)
+	}
+
+	var records []Metadata
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("unable to parse metadata catalog: %v", err)
+	}
+
+	books := make(map[string]Metadata, len(records))
+	for _, record := range records {
+		normalized, err := isbn.Normalize(record.ISBN)
+		if err != nil {
+			return nil, fmt.Errorf("metadata catalog: %q: %v", record.ISBN, err)
+		}
+		record.ISBN = normalized
+		books[normalized] = record
+	}
+	return &catalogProvider{books}, nil
+}
+
+func (p *catalogProvider) Lookup(ctx context.Context, isbn string) (*Metadata, error) {
+	record, ok := p.books[isbn]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &record, nil
+}
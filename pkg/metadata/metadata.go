@@ -0,0 +1,55 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arjnep/gyanpass/config"
+)
+
+const defaultCacheTTL = 24 * time.Hour
+
+var ErrNotFound = errors.New("no metadata found for isbn")
+
+type Metadata struct {
+	ISBN      string   `json:"isbn"`
+	Title     string   `json:"title"`
+	Authors   []string `json:"authors"`
+	Genres    []string `json:"genres"`
+	CoverURL  string   `json:"cover_url"`
+	Publisher string   `json:"publisher,omitempty"`
+	Published string   `json:"published,omitempty"`
+}
+
+// MetadataProvider looks up bibliographic data for a normalized ISBN-13.
+type MetadataProvider interface {
+	Lookup(ctx context.Context, isbn string) (*Metadata, error)
+}
+
+// NewProvider builds the configured provider wrapped in a lookup cache. It
+// returns nil when metadata enrichment is disabled.
+func NewProvider(cfg *config.Configuration) (MetadataProvider, error) {
+	var provider MetadataProvider
+	switch cfg.Metadata.Provider {
+	case "", "none":
+		return nil, nil
+	case "openlibrary":
+		provider = NewOpenLibraryProvider(cfg.Metadata.OpenLibraryURL)
+	case "catalog":
+		catalog, err := NewCatalogProvider(cfg.Metadata.CatalogPath)
+		if err != nil {
+			return nil, err
+		}
+		provider = catalog
+	default:
+		return nil, fmt.Errorf("unknown metadata provider: %s", cfg.Metadata.Provider)
+	}
+
+	ttl := time.Duration(cfg.Metadata.CacheTTL) * time.Second
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	return NewCachedProvider(provider, ttl), nil
+}
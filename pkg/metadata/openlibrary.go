@@ -0,0 +1,100 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultOpenLibraryURL = "https://openlibrary.org"
+
+type openLibraryProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewOpenLibraryProvider(baseURL string) MetadataProvider {
+	if baseURL == "" {
+		baseURL = defaultOpenLibraryURL
+	}
+	return &openLibraryProvider{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type openLibraryNamed struct {
+	Name string `json:"name"`
+}
+
+type openLibraryBook struct {
+	Title       string             `json:"title"`
+	Authors     []openLibraryNamed `json:"authors"`
+	Subjects    []openLibraryNamed `json:"subjects"`
+	Publishers  []openLibraryNamed `json:"publishers"`
+	PublishDate string             `json:"publish_date"`
+	Cover       struct {
+		Small  string `json:"small"`
+		Medium string `json:"medium"`
+		Large  string `json:"large"`
+	} `json:"cover"`
+}
+
+func (p *openLibraryProvider) Lookup(ctx context.Context, isbn string) (*Metadata, error) {
+	key := "ISBN:" + isbn
+	query := url.Values{
+		"bibkeys": {key},
+		"format":  {"json"},
+		"jscmd":   {"data"},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/api/books?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("open library lookup failed: %s", res.Status)
+	}
+
+	var body map[string]openLibraryBook
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	book, ok := body[key]
+	if !ok || book.Title == "" {
+		return nil, ErrNotFound
+	}
+
+	meta := &Metadata{
+		ISBN:      isbn,
+		Title:     book.Title,
+		Published: book.PublishDate,
+		CoverURL:  book.Cover.Large,
+	}
+	if meta.CoverURL == "" {
+		meta.CoverURL = book.Cover.Medium
+	}
+	for _, author := range book.Authors {
+		meta.Authors = append(meta.Authors, author.Name)
+	}
+	for _, subject := range book.Subjects {
+		meta.Genres = append(meta.Genres, subject.Name)
+	}
+	if len(book.Publishers) > 0 {
+		meta.Publisher = book.Publishers[0].Name
+	}
+	return meta, nil
+}
@@ -1,17 +1,22 @@
 package notification
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/arjnep/gyanpass/internal/entity"
 	"github.com/arjnep/gyanpass/internal/repository"
+	"github.com/arjnep/gyanpass/pkg/mailer"
 	"github.com/arjnep/gyanpass/pkg/response"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+var ErrEmailDisabled = errors.New("email delivery is not configured")
+
 type Service interface {
 	SendNotification(userID uuid.UUID, notificationType string, message string) error
+	SendEmail(userID uuid.UUID, subject string, body string) error
 	GetNotificationByID(id uuid.UUID) (*entity.Notification, error)
 	GetUserNotifications(userID uuid.UUID) ([]entity.Notification, error)
 	MarkNotificationAsRead(notification *entity.Notification, userID uuid.UUID) error
@@ -19,12 +24,15 @@ type Service interface {
 }
 
 type notificationService struct {
-	// Todo: Add Email
-	repo repository.NotificationRepository
+	repo     repository.NotificationRepository
+	userRepo repository.UserRepository
+	mailer   mailer.Mailer
 }
 
-func NewNotificationService(repo repository.NotificationRepository) Service {
-	return &notificationService{repo}
+// NewNotificationService sends in-app notifications and, when mailer is
+// not nil, email to the user's registered address.
+func NewNotificationService(repo repository.NotificationRepository, userRepo repository.UserRepository, mailer mailer.Mailer) Service {
+	return &notificationService{repo, userRepo, mailer}
 }
 
 func (s *notificationService) SendNotification(userID uuid.UUID, notificationType string, message string) error {
@@ -37,6 +45,18 @@ func (s *notificationService) SendNotification(userID uuid.UUID, notificationTyp
 	return s.repo.Create(notification)
 }
 
+func (s *notificationService) SendEmail(userID uuid.UUID, subject string, body string) error {
+	if s.mailer == nil {
+		return ErrEmailDisabled
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(user.Email, subject, body)
+}
+
 func (s *notificationService) GetNotificationByID(id uuid.UUID) (*entity.Notification, error) {
 	notificationFetched, err := s.repo.GetByID(id)
 	if err != nil && err == gorm.ErrRecordNotFound {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 type Type string
@@ -16,6 +17,7 @@ const (
 	NotFound             Type = "NOT_FOUND"
 	PayloadTooLarge      Type = "PAYLOAD_TOO_LARGE"
 	ServiceUnavailable   Type = "SERVICE_UNAVAILABLE"
+	TooManyRequests      Type = "TOO_MANY_REQUESTS"
 	UnsupportedMediaType Type = "UNSUPPORTED_MEDIA_TYPE"
 )
 
@@ -45,6 +47,8 @@ func (e *Error) Status() int {
 		return http.StatusRequestEntityTooLarge
 	case ServiceUnavailable:
 		return http.StatusServiceUnavailable
+	case TooManyRequests:
+		return http.StatusTooManyRequests
 	case UnsupportedMediaType:
 		return http.StatusUnsupportedMediaType
 	default:
@@ -111,6 +115,13 @@ func NewServiceUnavailableError() *Error {
 	}
 }
 
+func NewTooManyRequestsError(retryAfter time.Duration) *Error {
+	return &Error{
+		Type:    TooManyRequests,
+		Message: fmt.Sprintf("Too many attempts. Try again in %v", retryAfter.Round(time.Second)),
+	}
+}
+
 func NewUnsupportedMediaTypeError(reason string) *Error {
 	return &Error{
 		Type:    UnsupportedMediaType,
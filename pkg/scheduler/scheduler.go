@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Scheduler runs registered jobs on fixed intervals in the background.
+// A job never overlaps with itself: a tick that arrives while the previous
+// run is still busy is skipped.
+type Scheduler struct {
+	jobs   []job
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context)
+}
+
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every registers run to be called once per interval after Start.
+func (s *Scheduler) Every(name string, interval time.Duration, run func(ctx context.Context)) {
+	s.jobs = append(s.jobs, job{name, interval, run})
+}
+
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Stop cancels the jobs and waits for any run in progress to return.
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runJob(ctx, j)
+		}
+	}
+}
+
+func (s *Scheduler) runJob(ctx context.Context, j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Scheduled Job %s Panicked: %v\n", j.name, r)
+		}
+	}()
+
+	start := time.Now()
+	j.run(ctx)
+	log.Printf("Scheduled Job %s Finished In %v\n", j.name, time.Since(start))
+}
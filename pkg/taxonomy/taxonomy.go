@@ -0,0 +1,16 @@
+package taxonomy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalize folds a genre alias or tag to its lookup form: lower case,
+// with punctuation such as "-" and "_" treated as spaces and runs of
+// spaces collapsed, so "Sci-Fi" and "sci fi" compare equal.
+func Normalize(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '&' && r != '\''
+	})
+	return strings.Join(fields, " ")
+}